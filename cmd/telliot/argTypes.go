@@ -5,6 +5,8 @@ package main
 
 import (
 	"math/big"
+	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -79,3 +81,29 @@ func (b *EthereumInt) String() string {
 func (b *EthereumInt) IsDefault() bool {
 	return true
 }
+
+// Timestamp is a time as RFC3339 or unix seconds.
+type Timestamp struct {
+	time.Time
+}
+
+func (t *Timestamp) Set(v string) error {
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		t.Time = time.Unix(secs, 0)
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return errors.Errorf("invalid time, expected RFC3339 or unix seconds:%v", v)
+	}
+	t.Time = parsed
+	return nil
+}
+
+func (t *Timestamp) String() string {
+	return t.Time.Format(time.RFC3339)
+}
+
+func (t *Timestamp) IsDefault() bool {
+	return true
+}
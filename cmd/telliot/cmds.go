@@ -4,23 +4,33 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"math/big"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tellor-io/telliot/pkg/alerting"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/events"
 	"github.com/tellor-io/telliot/pkg/logging"
 	"github.com/tellor-io/telliot/pkg/ops"
 	"github.com/tellor-io/telliot/pkg/rest"
+	"github.com/tellor-io/telliot/pkg/tracker"
 )
 
 var GitTag string
@@ -288,8 +298,89 @@ func (v voteCmd) Run() error {
 	return ops.Vote(ctx, logger, client, contract, account, disputeID.Int, v.support)
 }
 
+type disputeIDCmd struct {
+	Config    configPath `type:"existingfile" help:"path to config file"`
+	DisputeID string     `arg:"" help:"the dispute id"`
+}
+
+type tallyCmd disputeIDCmd
+
+func (t tallyCmd) Run() error {
+	cfg, err := parseConfig(string(t.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	ctx := context.Background()
+	client, contract, account, err := createTellorVariables(ctx, logger, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "creating tellor variables")
+	}
+
+	disputeID := EthereumInt{}
+	if err := disputeID.Set(t.DisputeID); err != nil {
+		return errors.Wrapf(err, "parsing argument")
+	}
+	return ops.Tally(ctx, logger, client, contract, account, disputeID.Int)
+}
+
+type unlockFeeCmd disputeIDCmd
+
+func (u unlockFeeCmd) Run() error {
+	cfg, err := parseConfig(string(u.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	ctx := context.Background()
+	client, contract, account, err := createTellorVariables(ctx, logger, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "creating tellor variables")
+	}
+
+	disputeID := EthereumInt{}
+	if err := disputeID.Set(u.DisputeID); err != nil {
+		return errors.Wrapf(err, "parsing argument")
+	}
+	return ops.UnlockDisputeFee(ctx, logger, client, contract, account, disputeID.Int)
+}
+
+type disputeInfoCmd disputeIDCmd
+
+func (d disputeInfoCmd) Run() error {
+	cfg, err := parseConfig(string(d.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	ctx := context.Background()
+	client, contract, _, err := createTellorVariables(ctx, logger, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "creating tellor variables")
+	}
+
+	disputeID := EthereumInt{}
+	if err := disputeID.Set(d.DisputeID); err != nil {
+		return errors.Wrapf(err, "parsing argument")
+	}
+	source, closeSource, err := openEventSource(ctx, logger, cfg, client, contract)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+	return ops.ShowDispute(ctx, logger, contract, source, disputeID.Int)
+}
+
 type showCmd struct {
 	Config configPath `type:"existingfile" help:"path to config file"`
+	Vote   bool       `help:"vote on the open disputes according to the recommendation, each vote is confirmed unless --yes"`
+	Yes    bool       `help:"vote without confirmation"`
 }
 
 func (s showCmd) Run() error {
@@ -305,7 +396,80 @@ func (s showCmd) Run() error {
 	if err != nil {
 		return errors.Wrapf(err, "creating tellor variables")
 	}
-	return ops.List(ctx, logger, client, contract, account)
+	var policy *tracker.DisputePolicy
+	if s.Vote {
+		if err := apiOracle.EnsureValueOracle(logger, cfg); err != nil {
+			return errors.Wrapf(err, "opening the value history")
+		}
+		defer apiOracle.CloseValueOracle()
+		if err := tracker.LoadReplayIndexes(cfg); err != nil {
+			return errors.Wrapf(err, "loading the indexes")
+		}
+		confirm := confirmAction
+		if s.Yes {
+			confirm = nil
+		}
+		policy = tracker.NewDisputePolicy(logger, cfg, client, contract, account, confirm)
+	}
+	source, closeSource, err := openEventSource(ctx, logger, cfg, client, contract)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+	return ops.List(ctx, logger, client, contract, account, source, policy)
+}
+
+// confirmAction asks on the console whether to take an action.
+func confirmAction(action string) bool {
+	//lint:ignore faillint it should print to console
+	fmt.Printf("%s? [y/N] ", action)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+type candidatesCmd struct {
+	Config configPath `type:"existingfile" help:"path to config file"`
+	Format string     `enum:"json,markdown" default:"json" help:"the output format: json or markdown"`
+}
+
+func (c candidatesCmd) Run() error {
+	cfg, err := parseConfig(string(c.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	// The candidates are stored in the DB of the data server so are fetched from its REST API.
+	url := fmt.Sprintf("http://%s:%d/dispute/candidates", cfg.DataServer.ListenHost, cfg.DataServer.ListenPort)
+	resp, err := http.Get(url)
+	if err != nil {
+		return errors.Wrapf(err, "fetching the dispute candidates from the data server")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("fetching the dispute candidates from the data server status:%v", resp.Status)
+	}
+	var candidates []*tracker.DisputeCandidate
+	if err := json.NewDecoder(resp.Body).Decode(&candidates); err != nil {
+		return errors.Wrapf(err, "decoding the dispute candidates")
+	}
+
+	if c.Format == "markdown" {
+		for _, candidate := range candidates {
+			//lint:ignore faillint it should print to console
+			fmt.Println(candidate.Markdown())
+		}
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	if candidates == nil {
+		candidates = []*tracker.DisputeCandidate{}
+	}
+	return enc.Encode(candidates)
 }
 
 type dataserverCmd struct {
@@ -320,6 +484,10 @@ func (d dataserverCmd) Run() error {
 
 	logger := logging.NewLogger()
 
+	if err := alerting.Setup(logger, cfg); err != nil {
+		return errors.Wrapf(err, "creating alerting")
+	}
+
 	ctx := context.Background()
 	client, contract, account, err := createTellorVariables(ctx, logger, cfg)
 	if err != nil {
@@ -330,11 +498,12 @@ func (d dataserverCmd) Run() error {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 
-	DB, err := migrateAndOpenDB(logger, cfg)
+	DB, err := openDB(logger, cfg)
 	if err != nil {
 		return errors.Wrapf(err, "initializing database")
 	}
-	proxy, err := db.OpenLocal(logger, cfg, DB)
+	// The remote miners are notified of the written keys.
+	proxy, err := db.OpenLocal(logger, cfg, db.NewNotifier(DB))
 	if err != nil {
 		return errors.Wrapf(err, "open remote DB instance")
 	}
@@ -349,11 +518,24 @@ func (d dataserverCmd) Run() error {
 	}
 	<-ds.Ready()
 
-	http.Handle("/metrics", promhttp.Handler())
+	index, err := events.Open(logger, cfg, client, contract)
+	if err != nil {
+		return errors.Wrapf(err, "opening the event index")
+	}
+	defer index.Close()
+	index.Start(ctx, cfg.Events.Interval.Duration)
+
 	srv, err := rest.Create(logger, cfg, ctx, proxy, cfg.DataServer.ListenHost, cfg.DataServer.ListenPort)
 	if err != nil {
 		return errors.Wrapf(err, "creating http data server")
 	}
+	srv.Handle("/metrics", promhttp.Handler())
+	srv.Handle("/events", index)
+	api, err := rest.NewAPI(logger, cfg, proxy, client, contract, os.Getenv(config.APITokenEnvName))
+	if err != nil {
+		return errors.Wrapf(err, "creating the JSON API")
+	}
+	srv.Handle(rest.APIPrefix, api)
 	srv.Start()
 
 	// Wait for kill sig.
@@ -396,6 +578,10 @@ func (m mineCmd) Run() error {
 
 	logger := logging.NewLogger()
 
+	if err := alerting.Setup(logger, cfg); err != nil {
+		return errors.Wrapf(err, "creating alerting")
+	}
+
 	ctx := context.Background()
 	client, contract, account, err := createTellorVariables(ctx, logger, cfg)
 	if err != nil {
@@ -406,8 +592,9 @@ func (m mineCmd) Run() error {
 	signal.Notify(c, os.Interrupt)
 	exitChannels := make([]*chan os.Signal, 0)
 
-	http.Handle("/metrics", promhttp.Handler())
-	srv := &http.Server{Addr: fmt.Sprintf("%s:%d", cfg.Mine.ListenHost, cfg.Mine.ListenPort)}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: fmt.Sprintf("%s:%d", cfg.Mine.ListenHost, cfg.Mine.ListenPort), Handler: mux}
 	go func() {
 		level.Info(logger).Log("msg", "starting metrics server", "addr", cfg.Mine.ListenHost, "port", cfg.Mine.ListenPort)
 		// returns ErrServerClosed on graceful close
@@ -418,7 +605,7 @@ func (m mineCmd) Run() error {
 	defer srv.Close()
 
 	var ds *ops.DataServerOps
-	DB, err := migrateAndOpenDB(logger, cfg)
+	DB, err := openDB(logger, cfg)
 	if err != nil {
 		return errors.Wrapf(err, "initializing database")
 	}
@@ -427,7 +614,8 @@ func (m mineCmd) Run() error {
 	exitChannels = append(exitChannels, &ch1)
 
 	var proxy db.DataServerProxy
-	if cfg.Mine.RemoteDBHost != "" {
+	remote := len(cfg.Mine.RemoteDBs()) > 0
+	if remote {
 		proxy, err = db.OpenRemote(logger, cfg, DB)
 	} else {
 		proxy, err = db.OpenLocal(logger, cfg, DB)
@@ -438,7 +626,7 @@ func (m mineCmd) Run() error {
 	}
 
 	// Not using a remote DB so need to start the trackers.
-	if cfg.Mine.RemoteDBHost == "" {
+	if !remote {
 		ds, err = ops.CreateDataServerOps(ctx, logger, cfg, proxy, client, contract, account, ch1)
 		if err != nil {
 			return errors.Wrapf(err, "creating data server")
@@ -450,37 +638,15 @@ func (m mineCmd) Run() error {
 		<-ds.Ready()
 	}
 
-	var v []byte
-	for i := 0; i < 10; i++ {
-		// Start miner
-		v, err = proxy.Get(db.DisputeStatusKey)
-		if err != nil {
-			level.Warn(logger).Log("msg", "getting dispute status. Check if staked", "err", err)
-		}
-		if len(v) != 0 {
-			break
-		}
-		select {
-		case <-c: // Early exit from os.Interrupt
-			return nil
-		default:
-		}
-		time.Sleep(1 * time.Second)
-	}
-	if len(v) == 0 {
-		return errors.New("no status result after 10 attempts. this usually means no connection to the DB")
-	}
-
-	status, _ := hexutil.DecodeBig(string(v))
-	if status.Cmp(big.NewInt(1)) != 0 {
-		return errors.New("miner is not able to mine with current status")
-	}
 	ch2 := make(chan os.Signal)
 	exitChannels = append(exitChannels, &ch2)
 	miner, err := ops.CreateMiningManager(logger, ch2, cfg, proxy, contract, account)
 	if err != nil {
 		return errors.Wrapf(err, "creating miner")
 	}
+	// Mining is paused while the stake status doesn't allow submitting
+	// or isn't available yet because no data server is reachable.
+	mux.Handle("/stake", miner.Stake())
 	go func() {
 		miner.Start(ctx)
 	}()
@@ -520,3 +686,250 @@ func (m mineCmd) Run() error {
 	level.Info(logger).Log("msg", "main shutdown complete")
 	return nil
 }
+
+type historyExportCmd struct {
+	Config configPath `type:"existingfile" help:"path to config file"`
+	From   string     `help:"export values since this time as RFC3339 or unix seconds, defaults to the start of the retention"`
+	To     string     `help:"export values until this time as RFC3339 or unix seconds, defaults to now"`
+	Symbol string     `help:"only export the sources of this symbol from the indexes.json file"`
+	Format string     `enum:"csv,json" default:"csv" help:"the output format: csv or json"`
+	Output string     `help:"the output file, defaults to stdout"`
+}
+
+func (h historyExportCmd) Run() error {
+	cfg, err := parseConfig(string(h.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	to := Timestamp{time.Now()}
+	if h.To != "" {
+		if err := to.Set(h.To); err != nil {
+			return errors.Wrapf(err, "parsing argument")
+		}
+	}
+	from := Timestamp{to.Add(-cfg.History.Retention.Duration)}
+	if h.From != "" {
+		if err := from.Set(h.From); err != nil {
+			return errors.Wrapf(err, "parsing argument")
+		}
+	}
+	if from.After(to.Time) {
+		return errors.Errorf("from:%v is after to:%v", from.String(), to.String())
+	}
+
+	if err := apiOracle.EnsureValueOracle(logger, cfg); err != nil {
+		return errors.Wrapf(err, "opening the value history, stop the data server that uses it")
+	}
+	defer apiOracle.CloseValueOracle()
+
+	var sources []string
+	if h.Symbol == "" {
+		sources, err = apiOracle.HistorySources()
+		if err != nil {
+			return errors.Wrapf(err, "reading the history sources")
+		}
+	} else {
+		symbolSources, err := tracker.IndexSources(cfg)
+		if err != nil {
+			return errors.Wrapf(err, "reading the index sources")
+		}
+		var ok bool
+		if sources, ok = symbolSources[h.Symbol]; !ok {
+			return errors.Errorf("symbol isn't in the index file:%v", h.Symbol)
+		}
+	}
+
+	records, err := apiOracle.ExportHistory(sources, from.Time, to.Time)
+	if err != nil {
+		return errors.Wrapf(err, "exporting the history")
+	}
+
+	out := os.Stdout
+	if h.Output != "" {
+		out, err = os.Create(h.Output)
+		if err != nil {
+			return errors.Wrapf(err, "creating the output file")
+		}
+		defer out.Close()
+	}
+	if err := apiOracle.WriteHistory(out, apiOracle.HistoryFormat(h.Format), records); err != nil {
+		return errors.Wrapf(err, "writing the history")
+	}
+	level.Info(logger).Log("msg", "exported history", "sources", len(sources), "values", len(records))
+	return nil
+}
+
+type historyImportCmd struct {
+	Config   configPath `type:"existingfile" help:"path to config file"`
+	Format   string     `enum:",csv,json" default:"" help:"the input format: csv or json, defaults to the file extension"`
+	Backfill bool       `help:"also import the values older than the newest value of their source, these intervals are downsampled again"`
+	Files    []string   `arg:"" type:"existingfile" help:"the exported history files"`
+}
+
+func (h historyImportCmd) Run() error {
+	cfg, err := parseConfig(string(h.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	if err := apiOracle.EnsureValueOracle(logger, cfg); err != nil {
+		return errors.Wrapf(err, "opening the value history, stop the data server that uses it")
+	}
+	defer apiOracle.CloseValueOracle()
+
+	for _, file := range h.Files {
+		format := apiOracle.HistoryFormat(h.Format)
+		if format == "" {
+			format = apiOracle.HistoryFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), "."))
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrapf(err, "opening the history file")
+		}
+		records, err := apiOracle.ReadHistory(f, format)
+		f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "reading the history file:%v", file)
+		}
+		imported, skipped, err := apiOracle.ImportHistory(records, h.Backfill)
+		if err != nil {
+			return errors.Wrapf(err, "importing the history file:%v", file)
+		}
+		level.Info(logger).Log("msg", "imported history", "file", file, "imported", imported, "skipped", skipped)
+	}
+	return nil
+}
+
+type psrValueCmd struct {
+	Config    configPath `type:"existingfile" help:"path to config file"`
+	RequestID int        `arg:"" help:"the request id of the PSR"`
+	At        string     `help:"compute the value at this time as RFC3339 or unix seconds, defaults to now"`
+}
+
+func (p psrValueCmd) Run() error {
+	cfg, err := parseConfig(string(p.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	at := Timestamp{time.Now()}
+	if p.At != "" {
+		if err := at.Set(p.At); err != nil {
+			return errors.Wrapf(err, "parsing argument")
+		}
+	}
+
+	if err := apiOracle.EnsureValueOracle(logger, cfg); err != nil {
+		return errors.Wrapf(err, "opening the value history")
+	}
+	defer apiOracle.CloseValueOracle()
+	if err := tracker.LoadReplayIndexes(cfg); err != nil {
+		return errors.Wrapf(err, "loading the indexes")
+	}
+
+	value, confidence, err := tracker.PSRValueForTime(p.RequestID, at.Time)
+	if err != nil {
+		return errors.Wrapf(err, "computing the PSR value")
+	}
+	level.Info(logger).Log("msg", "PSR value", "requestID", p.RequestID, "at", at.String(), "value", value, "confidence", confidence)
+	return nil
+}
+
+type psrReplayCmd struct {
+	Config     configPath `type:"existingfile" help:"path to config file"`
+	RequestIDs []int      `name:"id" help:"the request ids of the PSRs to replay, defaults to all"`
+	From       string     `help:"replay since this time as RFC3339 or unix seconds, defaults to a day ago"`
+	To         string     `help:"replay until this time as RFC3339 or unix seconds, defaults to now"`
+	Step       string     `default:"10m" help:"the time between the recomputed values"`
+	Format     string     `enum:"csv,json" default:"csv" help:"the output format: csv or json"`
+	Output     string     `help:"the output file, defaults to stdout"`
+}
+
+func (p psrReplayCmd) Run() error {
+	cfg, err := parseConfig(string(p.Config))
+	if err != nil {
+		return errors.Wrapf(err, "creating config")
+	}
+
+	logger := logging.NewLogger()
+
+	to := Timestamp{time.Now()}
+	if p.To != "" {
+		if err := to.Set(p.To); err != nil {
+			return errors.Wrapf(err, "parsing argument")
+		}
+	}
+	from := Timestamp{to.Add(-24 * time.Hour)}
+	if p.From != "" {
+		if err := from.Set(p.From); err != nil {
+			return errors.Wrapf(err, "parsing argument")
+		}
+	}
+	step, err := time.ParseDuration(p.Step)
+	if err != nil {
+		return errors.Wrapf(err, "parsing argument")
+	}
+
+	if err := apiOracle.EnsureValueOracle(logger, cfg); err != nil {
+		return errors.Wrapf(err, "opening the value history")
+	}
+	defer apiOracle.CloseValueOracle()
+	if err := tracker.LoadReplayIndexes(cfg); err != nil {
+		return errors.Wrapf(err, "loading the indexes")
+	}
+
+	values, err := tracker.ReplayPSRs(p.RequestIDs, from.Time, to.Time, step)
+	if err != nil {
+		return errors.Wrapf(err, "replaying the PSRs")
+	}
+
+	out := os.Stdout
+	if p.Output != "" {
+		out, err = os.Create(p.Output)
+		if err != nil {
+			return errors.Wrapf(err, "creating the output file")
+		}
+		defer out.Close()
+	}
+	if err := writePSRValues(out, p.Format, values); err != nil {
+		return errors.Wrapf(err, "writing the PSR values")
+	}
+	level.Info(logger).Log("msg", "replayed PSRs", "values", len(values))
+	return nil
+}
+
+func writePSRValues(w io.Writer, format string, values []tracker.PSRValue) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		if values == nil {
+			values = []tracker.PSRValue{}
+		}
+		return enc.Encode(values)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"requestId", "time", "value", "confidence", "error"}); err != nil {
+		return err
+	}
+	for _, v := range values {
+		err := cw.Write([]string{
+			strconv.Itoa(v.RequestID),
+			v.Time.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(v.Value, 'f', -1, 64),
+			strconv.FormatFloat(v.Confidence, 'f', -1, 64),
+			v.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -7,15 +7,15 @@ import (
 	"context"
 	"os"
 	"path/filepath"
-	"strconv"
 
 	"github.com/alecthomas/kong"
 	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
-	tellorCommon "github.com/tellor-io/telliot/pkg/common"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/events"
 	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
@@ -60,40 +60,43 @@ func createTellorVariables(ctx context.Context, logger log.Logger, cfg *config.C
 	return client, &contract, &account, nil
 }
 
-// migrateAndOpenDB migrates the tx costs and deletes the db.
-// The DB is always deleted because the price avarages calculations
-// is not calculated properly between restarts.
-// TODO don't do this and just improve the price calculations.
-func migrateAndOpenDB(logger log.Logger, cfg *config.Config) (db.DB, error) {
-	// Create a db instance
-	DB, err := db.Open(logger, cfg, cfg.DBFile)
+// openEventSource opens the event index and brings it up to date.
+// When the index is in use by a running data server its events are fetched from the data server.
+func openEventSource(ctx context.Context, logger log.Logger, cfg *config.Config, client contracts.ETHClient, contract *contracts.Tellor) (events.Source, func(), error) {
+	index, err := events.Open(logger, cfg, client, contract)
 	if err != nil {
-		return nil, errors.Wrapf(err, "opening DB instance")
+		level.Info(logger).Log("msg", "opening the event index failed, fetching the events from the data server", "err", err)
+		url, httpClient, err := db.LocalDataServer(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return events.NewRemote(url, httpClient), func() {}, nil
 	}
-
-	var txsGas [][]byte
-	for i := 0; i <= 5; i++ {
-		txID := tellorCommon.PriceTXs + strconv.Itoa(i)
-		txGas, err := DB.Get(txID)
-		if err == nil && len(txGas) > 0 {
-			txsGas = append(txsGas, txGas)
+	closeIndex := func() {
+		if err := index.Close(); err != nil {
+			level.Error(logger).Log("msg", "closing the event index", "err", err)
 		}
 	}
-	if err := DB.Close(); err != nil {
-		return nil, errors.Wrapf(err, "closing DB instance for migration")
+	if err := index.Sync(ctx); err != nil {
+		closeIndex()
+		return nil, nil, errors.Wrap(err, "syncing the event index")
 	}
-	os.RemoveAll(cfg.DBFile)
+	return index, closeIndex, nil
+}
 
-	DB, err = db.Open(logger, cfg, cfg.DBFile)
+// openDB opens the DB and migrates it to the current version.
+func openDB(logger log.Logger, cfg *config.Config) (db.DB, error) {
+	DB, err := db.Open(logger, cfg, cfg.DBFile)
 	if err != nil {
 		return nil, errors.Wrapf(err, "opening DB instance")
 	}
-
-	for i, txGas := range txsGas {
-		txID := tellorCommon.PriceTXs + strconv.Itoa(i)
-		_ = DB.Put(txID, txGas)
+	if err := db.Migrate(DB, cfg.DBFile); err != nil {
+		DB.Close()
+		return nil, errors.Wrapf(err, "migrating DB instance")
+	}
+	if cfg.DBBackend == db.BackendRedis {
+		level.Info(logger).Log("msg", "the value history and the event index aren't shared, every replica keeps its own", "history", cfg.History.File, "events", cfg.Events.File)
 	}
-
 	return DB, nil
 }
 
@@ -108,10 +111,22 @@ var cli struct {
 		Status   statusCmd   `cmd:"" help:"show stake status"`
 	} `cmd:"" help:"Perform one of the stake operations"`
 	Dispute struct {
-		New  newDisputeCmd `cmd:"" help:"start a new dispute"`
-		Vote voteCmd       `cmd:"" help:"vote on a open dispute"`
-		Show showCmd       `cmd:"" help:"show open disputes"`
+		New        newDisputeCmd  `cmd:"" help:"start a new dispute"`
+		Vote       voteCmd        `cmd:"" help:"vote on a open dispute"`
+		Show       showCmd        `cmd:"" help:"show open disputes"`
+		Info       disputeInfoCmd `cmd:"" help:"show all details of a dispute"`
+		Tally      tallyCmd       `cmd:"" help:"tally the votes of a dispute after the voting has ended"`
+		UnlockFee  unlockFeeCmd   `cmd:"" name:"unlock-fee" help:"pay out the fee of a dispute a day after it was tallied"`
+		Candidates candidatesCmd  `cmd:"" help:"show the submitted values detected as dispute candidates"`
 	} `cmd:"" help:"Perform commands related to disputes"`
+	History struct {
+		Export historyExportCmd `cmd:"" help:"export the value history of the sources, stop the data server first as the history database can only be opened by one process"`
+		Import historyImportCmd `cmd:"" help:"import exported value history, stop the data server first as the history database can only be opened by one process"`
+	} `cmd:"" help:"Export and import the value history"`
+	Psr struct {
+		Value  psrValueCmd  `cmd:"" help:"compute the value of a PSR from the value history"`
+		Replay psrReplayCmd `cmd:"" help:"recompute the values of the PSRs over a time range from the value history"`
+	} `cmd:"" help:"Compute PSR values from the recorded value history"`
 	Dataserver dataserverCmd `cmd:"" help:"launch only a dataserver instance"`
 	Mine       mineCmd       `cmd:"" help:"mine TRB and submit values"`
 	Version    VersionCmd    `cmd:"" help:"Show the Docker version information"`
@@ -0,0 +1,206 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package alerting
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"golang.org/x/time/rate"
+)
+
+const ComponentName = "alerting"
+
+// The rules of the alerts.
+const (
+	RuleLowETHBalance    = "low_eth_balance"
+	RuleLowTRBBalance    = "low_trb_balance"
+	RuleStakeStatus      = "stake_status"
+	RuleSubmitFails      = "submit_fails"
+	RuleStaleChallenge   = "stale_challenge"
+	RuleDisputeCandidate = "dispute_candidate"
+)
+
+// The severities of the alerts.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// sendTimeout limits the time to send an alert to all notifiers.
+const sendTimeout = 30 * time.Second
+
+// Alert is a notification about a critical condition.
+type Alert struct {
+	Rule string `json:"rule"`
+	// Key identifies the condition within the rule, alerts with the same rule and key are deduplicated.
+	Key      string            `json:"key,omitempty"`
+	Severity string            `json:"severity"`
+	Summary  string            `json:"summary"`
+	Details  map[string]string `json:"details,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Text renders the alert as plain text.
+func (a *Alert) Text() string {
+	var b strings.Builder
+	b.WriteString("[" + strings.ToUpper(a.Severity) + "] " + a.Summary)
+	keys := make([]string, 0, len(a.Details))
+	for k := range a.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString("\n" + k + ": " + a.Details[k])
+	}
+	return b.String()
+}
+
+// Notifier sends alerts to a destination.
+type Notifier interface {
+	Notify(ctx context.Context, alert *Alert) error
+	String() string
+}
+
+// Alerter sends the alerts to all notifiers.
+// The same alert isn't sent again within the repeat interval and all alerts are rate limited.
+type Alerter struct {
+	logger         log.Logger
+	notifiers      []Notifier
+	repeatInterval time.Duration
+	limiter        *rate.Limiter
+
+	mtx  sync.Mutex
+	sent map[string]time.Time
+	// pruned is when the sent alerts past the repeat interval were last removed.
+	pruned time.Time
+}
+
+// New creates an alerter with the notifiers configured in cfg.
+func New(logger log.Logger, cfg *config.Config) (*Alerter, error) {
+	logger, err := logging.ApplyFilter(*cfg, ComponentName, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	notifiers, err := notifiersFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewAlerter(log.With(logger, "component", ComponentName), notifiers, cfg.Alerting.RepeatInterval.Duration, cfg.Alerting.RateLimit), nil
+}
+
+// NewAlerter creates an alerter with the given notifiers.
+// A zero rateLimit, in alerts per hour, disables the rate limit.
+func NewAlerter(logger log.Logger, notifiers []Notifier, repeatInterval time.Duration, rateLimit float64) *Alerter {
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if rateLimit > 0 {
+		burst := int(rateLimit)
+		if burst < 1 {
+			burst = 1
+		}
+		limiter = rate.NewLimiter(rate.Limit(rateLimit/time.Hour.Seconds()), burst)
+	}
+	return &Alerter{
+		logger:         logger,
+		notifiers:      notifiers,
+		repeatInterval: repeatInterval,
+		limiter:        limiter,
+		sent:           make(map[string]time.Time),
+	}
+}
+
+// Send sends the alert to all notifiers unless it is a duplicate or over the rate limit.
+// It returns whether the alert was sent.
+func (a *Alerter) Send(ctx context.Context, alert *Alert) (bool, error) {
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	key := alert.Rule + "/" + alert.Key
+
+	a.mtx.Lock()
+	if last, ok := a.sent[key]; ok && alert.Time.Sub(last) < a.repeatInterval {
+		a.mtx.Unlock()
+		level.Debug(a.logger).Log("msg", "duplicate alert", "rule", alert.Rule, "key", alert.Key)
+		return false, nil
+	}
+	if !a.limiter.AllowN(alert.Time, 1) {
+		a.mtx.Unlock()
+		level.Warn(a.logger).Log("msg", "alert rate limit reached, alert dropped", "rule", alert.Rule, "summary", alert.Summary)
+		return false, nil
+	}
+	a.sent[key] = alert.Time
+	a.prune(alert.Time)
+	a.mtx.Unlock()
+
+	level.Warn(a.logger).Log("msg", "alert", "rule", alert.Rule, "severity", alert.Severity, "summary", alert.Summary)
+	var final error
+	for _, n := range a.notifiers {
+		if err := n.Notify(ctx, alert); err != nil {
+			final = multierror.Append(final, errors.Wrapf(err, "notify %v", n))
+		}
+	}
+	return true, final
+}
+
+// prune removes the sent alerts that can be repeated at now so that the keys
+// of one off alerts, like the hash of a dispute candidate, don't accumulate.
+// It runs once per repeat interval so at most the alerts of the last two intervals are kept.
+func (a *Alerter) prune(now time.Time) {
+	if now.Sub(a.pruned) < a.repeatInterval {
+		return
+	}
+	for key, last := range a.sent {
+		if now.Sub(last) >= a.repeatInterval {
+			delete(a.sent, key)
+		}
+	}
+	a.pruned = now
+}
+
+var (
+	defaultAlerter    *Alerter
+	defaultAlerterMtx sync.Mutex
+)
+
+// Setup creates the alerter used by Notify.
+func Setup(logger log.Logger, cfg *config.Config) error {
+	alerter, err := New(logger, cfg)
+	if err != nil {
+		return err
+	}
+	defaultAlerterMtx.Lock()
+	defer defaultAlerterMtx.Unlock()
+	defaultAlerter = alerter
+	return nil
+}
+
+// Notify sends the alert in the background with the alerter created by Setup.
+// It does nothing when Setup wasn't called.
+func Notify(alert *Alert) {
+	defaultAlerterMtx.Lock()
+	alerter := defaultAlerter
+	defaultAlerterMtx.Unlock()
+	if alerter == nil {
+		return
+	}
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+		if _, err := alerter.Send(ctx, alert); err != nil {
+			level.Error(alerter.logger).Log("msg", "sending alert", "rule", alert.Rule, "err", err)
+		}
+	}()
+}
@@ -0,0 +1,136 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type countingNotifier struct {
+	alerts []*Alert
+}
+
+func (n *countingNotifier) Notify(ctx context.Context, alert *Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *countingNotifier) String() string {
+	return "counting"
+}
+
+func TestAlerterDedupAndRateLimit(t *testing.T) {
+	n := &countingNotifier{}
+	a := NewAlerter(logging.NewLogger(), []Notifier{n}, time.Hour, 2)
+	ctx := context.Background()
+	now := time.Now()
+
+	sent, err := a.Send(ctx, &Alert{Rule: RuleLowETHBalance, Key: "a", Time: now})
+	testutil.Ok(t, err)
+	testutil.Assert(t, sent, "expected the first alert to be sent")
+
+	// The same alert within the repeat interval is a duplicate.
+	sent, err = a.Send(ctx, &Alert{Rule: RuleLowETHBalance, Key: "a", Time: now.Add(time.Minute)})
+	testutil.Ok(t, err)
+	testutil.Assert(t, !sent, "expected a duplicate alert")
+
+	sent, err = a.Send(ctx, &Alert{Rule: RuleLowETHBalance, Key: "b", Time: now.Add(time.Minute)})
+	testutil.Ok(t, err)
+	testutil.Assert(t, sent, "expected an alert with another key to be sent")
+
+	// The burst of 2 alerts per hour is used up.
+	sent, err = a.Send(ctx, &Alert{Rule: RuleSubmitFails, Time: now.Add(2 * time.Minute)})
+	testutil.Ok(t, err)
+	testutil.Assert(t, !sent, "expected the alert to be rate limited")
+
+	// After the repeat interval the alert is sent again.
+	sent, err = a.Send(ctx, &Alert{Rule: RuleLowETHBalance, Key: "a", Time: now.Add(2 * time.Hour)})
+	testutil.Ok(t, err)
+	testutil.Assert(t, sent, "expected the alert to be repeated")
+	testutil.Equals(t, 3, len(n.alerts))
+	// The alerts past the repeat interval are forgotten.
+	testutil.Equals(t, map[string]time.Time{RuleLowETHBalance + "/a": now.Add(2 * time.Hour)}, a.sent)
+}
+
+func TestNotifiers(t *testing.T) {
+	bodies := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		bodies[r.URL.Path] = string(b)
+	}))
+	defer srv.Close()
+
+	cfg := config.OpenTestConfig(t)
+	cfg.Alerting.Webhooks = []config.Webhook{
+		{URL: srv.URL + "/json"},
+		{URL: srv.URL + "/template", Template: `{"rule":{{json .Rule}},"msg":{{json .Summary}},"details":{{json .Details}}}`},
+	}
+	cfg.Alerting.Slack = []string{srv.URL + "/slack"}
+	notifiers, err := notifiersFromConfig(cfg)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(notifiers))
+
+	alert := &Alert{
+		Rule:     RuleStakeStatus,
+		Severity: SeverityCritical,
+		Summary:  "stake status changed to 3",
+		Details:  map[string]string{"status": "3", "previous": "1", "reason": "\"slashed\"\nby dispute"},
+	}
+	a := NewAlerter(logging.NewLogger(), notifiers, time.Hour, 0)
+	sent, err := a.Send(context.Background(), alert)
+	testutil.Ok(t, err)
+	testutil.Assert(t, sent, "expected the alert to be sent")
+
+	var got Alert
+	testutil.Ok(t, json.Unmarshal([]byte(bodies["/json"]), &got))
+	testutil.Equals(t, alert.Summary, got.Summary)
+	testutil.Equals(t, alert.Details, got.Details)
+
+	// Values with quotes and newlines are escaped by the json function.
+	var templated struct {
+		Rule    string
+		Msg     string
+		Details map[string]string
+	}
+	testutil.Ok(t, json.Unmarshal([]byte(bodies["/template"]), &templated))
+	testutil.Equals(t, RuleStakeStatus, templated.Rule)
+	testutil.Equals(t, alert.Summary, templated.Msg)
+	testutil.Equals(t, alert.Details, templated.Details)
+
+	var slack map[string]string
+	testutil.Ok(t, json.Unmarshal([]byte(bodies["/slack"]), &slack))
+	testutil.Equals(t, "[CRITICAL] stake status changed to 3\nprevious: 1\nreason: \"slashed\"\nby dispute\nstatus: 3", slack["text"])
+}
+
+func TestSMTP(t *testing.T) {
+	n, err := NewSMTP(config.SMTP{Host: "mail.example.com", From: "telliot@example.com", To: []string{"ops@example.com"}}, "secret")
+	testutil.Ok(t, err)
+	var addr string
+	var msg []byte
+	n.sendMail = func(a string, auth smtp.Auth, from string, to []string, m []byte) error {
+		addr = a
+		msg = m
+		testutil.Assert(t, auth != nil, "expected authentication")
+		return nil
+	}
+	testutil.Ok(t, n.Notify(context.Background(), &Alert{Rule: RuleSubmitFails, Severity: SeverityCritical, Summary: "5 consecutive solution submissions failed"}))
+	testutil.Equals(t, "mail.example.com:587", addr)
+	testutil.Assert(t, strings.Contains(string(msg), "Subject: telliot critical: 5 consecutive solution submissions failed\r\n"), "unexpected message:%s", msg)
+
+	_, err = NewSMTP(config.SMTP{Host: "mail.example.com"}, "")
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,195 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+func notifiersFromConfig(cfg *config.Config) ([]Notifier, error) {
+	var notifiers []Notifier
+	for _, w := range cfg.Alerting.Webhooks {
+		n, err := NewWebhook(w.URL, w.Template)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	for _, url := range cfg.Alerting.Slack {
+		notifiers = append(notifiers, NewSlack(url))
+	}
+	if cfg.Alerting.SMTP.Host != "" {
+		n, err := NewSMTP(cfg.Alerting.SMTP, os.Getenv(config.SMTPPasswordEnvName))
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// postJSON posts the body to the url and expects a successful status.
+func postJSON(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("post request status:%v", resp.Status)
+	}
+	return nil
+}
+
+// Webhook posts the alerts as JSON to a URL.
+type Webhook struct {
+	url  string
+	tmpl *template.Template
+}
+
+// templateFuncs are the functions of the webhook templates.
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON so that strings are quoted and escaped.
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewWebhook creates a webhook notifier.
+// The body is rendered with the Go template tmpl from the alert, or is the alert as JSON when tmpl is empty.
+// The template isn't escaped so values are inserted with the json function, e.g. {"msg": {{json .Summary}}}.
+func NewWebhook(url, tmpl string) (*Webhook, error) {
+	if url == "" {
+		return nil, errors.New("missing the webhook url")
+	}
+	w := &Webhook{url: url}
+	if tmpl != "" {
+		t, err := template.New("webhook").Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse the template of webhook:%v", url)
+		}
+		w.tmpl = t
+	}
+	return w, nil
+}
+
+// Notify implements Notifier.
+func (w *Webhook) Notify(ctx context.Context, alert *Alert) error {
+	var body []byte
+	if w.tmpl != nil {
+		var b bytes.Buffer
+		if err := w.tmpl.Execute(&b, alert); err != nil {
+			return errors.Wrap(err, "execute template")
+		}
+		body = b.Bytes()
+	} else {
+		var err error
+		body, err = json.Marshal(alert)
+		if err != nil {
+			return errors.Wrap(err, "marshal alert")
+		}
+	}
+	return postJSON(ctx, w.url, body)
+}
+
+func (w *Webhook) String() string {
+	return "webhook"
+}
+
+// Slack posts the alerts to a Slack compatible incoming webhook.
+type Slack struct {
+	url string
+}
+
+// NewSlack creates a Slack notifier.
+func NewSlack(url string) *Slack {
+	return &Slack{url: url}
+}
+
+// Notify implements Notifier.
+func (s *Slack) Notify(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(map[string]string{"text": alert.Text()})
+	if err != nil {
+		return errors.Wrap(err, "marshal message")
+	}
+	return postJSON(ctx, s.url, body)
+}
+
+func (s *Slack) String() string {
+	return "slack"
+}
+
+// SMTP emails the alerts.
+type SMTP struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	to       []string
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTP creates an email notifier.
+// The password is optional and without it the server is used without authentication.
+func NewSMTP(cfg config.SMTP, password string) (*SMTP, error) {
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("smtp notifier needs the from and to addresses")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	s := &SMTP{
+		addr:     fmt.Sprintf("%s:%d", cfg.Host, port),
+		from:     cfg.From,
+		to:       cfg.To,
+		sendMail: smtp.SendMail,
+	}
+	if password != "" {
+		username := cfg.Username
+		if username == "" {
+			username = cfg.From
+		}
+		s.auth = smtp.PlainAuth("", username, password, cfg.Host)
+	}
+	return s, nil
+}
+
+// Notify implements Notifier.
+// smtp.SendMail doesn't support a context so the context is only checked before sending.
+func (s *SMTP) Notify(ctx context.Context, alert *Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var msg strings.Builder
+	msg.WriteString("From: " + s.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(s.to, ", ") + "\r\n")
+	msg.WriteString("Subject: telliot " + alert.Severity + ": " + alert.Summary + "\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(alert.Text(), "\n", "\r\n") + "\r\n")
+	if err := s.sendMail(s.addr, s.auth, s.from, s.to, []byte(msg.String())); err != nil {
+		return errors.Wrap(err, "send mail")
+	}
+	return nil
+}
+
+func (s *SMTP) String() string {
+	return "smtp"
+}
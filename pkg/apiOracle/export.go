@@ -0,0 +1,170 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package apiOracle
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HistoryFormat is the file format of exported value history.
+type HistoryFormat string
+
+const (
+	HistoryCSV  HistoryFormat = "csv"
+	HistoryJSON HistoryFormat = "json"
+)
+
+var historyCSVHeader = []string{"source", "created", "price", "volume"}
+
+// HistoryRecord is a recorded value of a source.
+type HistoryRecord struct {
+	Source string `json:"source"`
+	PriceStamp
+}
+
+// HistorySources returns the IDs of all sources with a recorded history.
+func HistorySources() ([]string, error) {
+	h := history()
+	if h == nil {
+		return nil, errors.New("value oracle isn't opened")
+	}
+	return h.Sources()
+}
+
+// ExportHistory returns the recorded values of the sources between from and to.
+func ExportHistory(sources []string, from, to time.Time) ([]*HistoryRecord, error) {
+	h := history()
+	if h == nil {
+		return nil, errors.New("value oracle isn't opened")
+	}
+	var records []*HistoryRecord
+	for _, id := range sources {
+		values, err := h.WithinRange(id, to, to.Sub(from))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read history of:%v", id)
+		}
+		for _, v := range values {
+			records = append(records, &HistoryRecord{Source: id, PriceStamp: *v})
+		}
+	}
+	return records, nil
+}
+
+// ImportHistory merges the records into the recorded history.
+// Like the recorded values the records of a source are only appended so
+// records older than the retention or not newer than the newest value of their source are skipped.
+// With backfill the records older than the newest value are added too, unless there is a value
+// at their time, and the already downsampled intervals are downsampled again with the next compaction.
+func ImportHistory(records []*HistoryRecord, backfill bool) (imported, skipped int, err error) {
+	h := history()
+	if h == nil {
+		return 0, 0, errors.New("value oracle isn't opened")
+	}
+	sorted := make([]*HistoryRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created.Before(sorted[j].Created)
+	})
+	insert := h.Insert
+	if backfill {
+		insert = h.Backfill
+	}
+	for _, r := range sorted {
+		stamp := r.PriceStamp
+		added, err := insert(r.Source, &stamp)
+		if err != nil {
+			return imported, skipped, errors.Wrapf(err, "import value of:%v", r.Source)
+		}
+		if added {
+			imported++
+		} else {
+			skipped++
+		}
+	}
+	return imported, skipped, nil
+}
+
+// WriteHistory writes the records in the given format.
+func WriteHistory(w io.Writer, format HistoryFormat, records []*HistoryRecord) error {
+	switch format {
+	case HistoryJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		if records == nil {
+			records = []*HistoryRecord{}
+		}
+		return enc.Encode(records)
+	case HistoryCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(historyCSVHeader); err != nil {
+			return err
+		}
+		for _, r := range records {
+			err := cw.Write([]string{
+				r.Source,
+				r.Created.UTC().Format(time.RFC3339Nano),
+				strconv.FormatFloat(r.Price, 'g', -1, 64),
+				strconv.FormatFloat(r.Volume, 'g', -1, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return errors.Errorf("unknown history format:%v", format)
+	}
+}
+
+// ReadHistory reads records in the given format.
+func ReadHistory(r io.Reader, format HistoryFormat) ([]*HistoryRecord, error) {
+	switch format {
+	case HistoryJSON:
+		var records []*HistoryRecord
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, errors.Wrap(err, "decode history json")
+		}
+		return records, nil
+	case HistoryCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = len(historyCSVHeader)
+		rows, err := cr.ReadAll()
+		if err != nil {
+			return nil, errors.Wrap(err, "read history csv")
+		}
+		if len(rows) == 0 || rows[0][0] != historyCSVHeader[0] {
+			return nil, errors.New("history csv requires a header")
+		}
+		records := make([]*HistoryRecord, 0, len(rows)-1)
+		for i, row := range rows[1:] {
+			created, err := time.Parse(time.RFC3339Nano, row[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse created time on line:%d", i+2)
+			}
+			price, err := strconv.ParseFloat(row[2], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse price on line:%d", i+2)
+			}
+			volume, err := strconv.ParseFloat(row[3], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse volume on line:%d", i+2)
+			}
+			records = append(records, &HistoryRecord{
+				Source:     row[0],
+				PriceStamp: PriceStamp{Created: created, PriceInfo: PriceInfo{Price: price, Volume: volume}},
+			})
+		}
+		return records, nil
+	default:
+		return nil, errors.Errorf("unknown history format:%v", format)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package apiOracle
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestHistoryExportImport(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	cfg.ConfigFolder = t.TempDir()
+	testutil.Ok(t, EnsureValueOracle(logging.NewLogger(), &cfg))
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		SetRequestValue("a", start.Add(time.Duration(i)*time.Minute), PriceInfo{Price: float64(i), Volume: 1.5})
+		SetRequestValue("b", start.Add(time.Duration(i)*time.Minute), PriceInfo{Price: float64(10 + i)})
+	}
+	records, err := ExportHistory([]string{"a", "b"}, start.Add(time.Minute), start.Add(3*time.Minute))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 6, len(records))
+	testutil.Equals(t, "a", records[0].Source)
+	testutil.Equals(t, 1.0, records[0].Price)
+	testutil.Equals(t, "b", records[5].Source)
+	testutil.Equals(t, 13.0, records[5].Price)
+
+	for _, format := range []HistoryFormat{HistoryCSV, HistoryJSON} {
+		var buf bytes.Buffer
+		testutil.Ok(t, WriteHistory(&buf, format, records))
+		read, err := ReadHistory(&buf, format)
+		testutil.Ok(t, err)
+		testutil.Equals(t, len(records), len(read))
+		for i := range records {
+			testutil.Equals(t, records[i].Source, read[i].Source)
+			testutil.Assert(t, records[i].Created.Equal(read[i].Created), "created time should survive the round trip")
+			testutil.Equals(t, records[i].PriceInfo, read[i].PriceInfo)
+		}
+	}
+
+	// Values not newer than the newest value of a source are skipped.
+	testutil.Ok(t, CloseValueOracle())
+	testutil.Ok(t, EnsureValueOracle(logging.NewLogger(), &cfg))
+	defer func() { testutil.Ok(t, CloseValueOracle()) }()
+	SetRequestValue("b", start.Add(2*time.Minute), PriceInfo{Price: 100})
+	imported, skipped, err := ImportHistory(records, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, imported)
+	testutil.Equals(t, 2, skipped)
+
+	// With backfill the older values are added too
+	// and values at the time of an existing value are skipped.
+	imported, skipped, err = ImportHistory(records, true)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, imported)
+	testutil.Equals(t, 5, skipped)
+
+	sources, err := HistorySources()
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"a", "b"}, sources)
+	values := GetRequestValuesForTime("b", start.Add(time.Hour), time.Hour)
+	testutil.Equals(t, 3, len(values))
+	testutil.Equals(t, 11.0, values[0].Price)
+	testutil.Equals(t, 100.0, values[1].Price)
+	testutil.Equals(t, 13.0, values[2].Price)
+}
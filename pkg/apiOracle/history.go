@@ -0,0 +1,339 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package apiOracle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// historyPrefix prefixes the value keys of a source which are followed
+	// by the big endian unix nano timestamp so that keys are sorted by time.
+	historyPrefix = "h:"
+	// downsampledPrefix prefixes the keys that hold until when a source is downsampled.
+	downsampledPrefix = "d:"
+	// historySeparator ends the source ID in a key so that
+	// the range of a source never includes a source with a longer ID.
+	historySeparator = 0
+)
+
+// historyStore keeps the value history of every source in a LevelDB.
+type historyStore struct {
+	db                 *leveldb.DB
+	retention          time.Duration
+	downsampleAfter    time.Duration
+	downsampleInterval time.Duration
+
+	// mtx guards inserts so that values are always appended in order.
+	mtx sync.Mutex
+	// latest caches the timestamp of the newest value for each source.
+	latest map[string]time.Time
+}
+
+// openHistoryStore opens the store at the given path.
+// An empty path keeps the history in memory only.
+func openHistoryStore(path string, retention, downsampleAfter, downsampleInterval time.Duration) (*historyStore, error) {
+	var db *leveldb.DB
+	var err error
+	if path == "" {
+		db, err = leveldb.Open(storage.NewMemStorage(), nil)
+	} else {
+		db, err = leveldb.OpenFile(path, nil)
+		if _, corrupted := err.(*leveldbErrors.ErrCorrupted); corrupted {
+			db, err = leveldb.RecoverFile(path, nil)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "open history db:%v", path)
+	}
+	return &historyStore{
+		db:                 db,
+		retention:          retention,
+		downsampleAfter:    downsampleAfter,
+		downsampleInterval: downsampleInterval,
+		latest:             make(map[string]time.Time),
+	}, nil
+}
+
+func (h *historyStore) Close() error {
+	return h.db.Close()
+}
+
+func sourcePrefix(id string) []byte {
+	return append([]byte(historyPrefix+id), historySeparator)
+}
+
+func historyKey(id string, t time.Time) []byte {
+	key := sourcePrefix(id)
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(t.UnixNano()))
+	return append(key, ts...)
+}
+
+func encodePriceInfo(info PriceInfo) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, math.Float64bits(info.Price))
+	binary.BigEndian.PutUint64(b[8:], math.Float64bits(info.Volume))
+	return b
+}
+
+// decodeStamp decodes the value at the current position of the iterator.
+func decodeStamp(it iterator.Iterator) (*PriceStamp, error) {
+	key, value := it.Key(), it.Value()
+	if len(key) < 8 || len(value) != 16 {
+		return nil, errors.Errorf("invalid history entry key:%x", key)
+	}
+	return &PriceStamp{
+		Created: time.Unix(0, int64(binary.BigEndian.Uint64(key[len(key)-8:]))),
+		PriceInfo: PriceInfo{
+			Price:  math.Float64frombits(binary.BigEndian.Uint64(value)),
+			Volume: math.Float64frombits(binary.BigEndian.Uint64(value[8:])),
+		},
+	}, nil
+}
+
+// Insert adds a value to the history of a source and reports whether it was added.
+// Like Window.Insert it ignores values older than the retention or the newest value of the source
+// and also values at the time of the newest value so that importing the same values twice adds nothing.
+func (h *historyStore) Insert(id string, x *PriceStamp) (bool, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if time.Since(x.Created) > h.retention {
+		return false, nil
+	}
+	latest, ok := h.latest[id]
+	if !ok {
+		newest, err := h.newest(id)
+		if err != nil {
+			return false, err
+		}
+		if newest != nil {
+			latest = newest.Created
+		}
+	}
+	// A value at the same time as the newest one would overwrite it.
+	if !latest.IsZero() && !x.Created.After(latest) {
+		return false, nil
+	}
+	if err := h.db.Put(historyKey(id, x.Created), encodePriceInfo(x.PriceInfo), nil); err != nil {
+		return false, errors.Wrap(err, "put history value")
+	}
+	h.latest[id] = x.Created
+	return true, nil
+}
+
+// Backfill adds a value to the history of a source and reports whether it was added.
+// Unlike Insert it also adds values older than the newest value of the source, for importing history.
+// Values older than the retention or at the time of an existing value are ignored.
+// When the value is in an interval that was already downsampled the interval is downsampled again
+// with the next compaction.
+func (h *historyStore) Backfill(id string, x *PriceStamp) (bool, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if time.Since(x.Created) > h.retention {
+		return false, nil
+	}
+	key := historyKey(id, x.Created)
+	exists, err := h.db.Has(key, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "check history value")
+	}
+	if exists {
+		return false, nil
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put(key, encodePriceInfo(x.PriceInfo))
+	if h.downsampleInterval > 0 {
+		marker, err := h.db.Get([]byte(downsampledPrefix+id), nil)
+		if err != nil && err != leveldb.ErrNotFound {
+			return false, err
+		}
+		if len(marker) == 8 && x.Created.Before(time.Unix(0, int64(binary.BigEndian.Uint64(marker)))) {
+			ts := make([]byte, 8)
+			binary.BigEndian.PutUint64(ts, uint64(x.Created.Truncate(h.downsampleInterval).UnixNano()))
+			batch.Put([]byte(downsampledPrefix+id), ts)
+		}
+	}
+	if err := h.db.Write(batch, nil); err != nil {
+		return false, errors.Wrap(err, "put history value")
+	}
+	if latest, ok := h.latest[id]; ok && x.Created.After(latest) {
+		h.latest[id] = x.Created
+	}
+	return true, nil
+}
+
+// newest returns the newest value of a source.
+func (h *historyStore) newest(id string) (*PriceStamp, error) {
+	it := h.db.NewIterator(util.BytesPrefix(sourcePrefix(id)), nil)
+	defer it.Release()
+	if !it.Last() {
+		return nil, it.Error()
+	}
+	return decodeStamp(it)
+}
+
+// WithinRange returns the values of a source from at-delta until at.
+func (h *historyStore) WithinRange(id string, at time.Time, delta time.Duration) ([]*PriceStamp, error) {
+	it := h.db.NewIterator(&util.Range{
+		Start: historyKey(id, at.Add(-delta)),
+		Limit: historyKey(id, at.Add(1)),
+	}, nil)
+	defer it.Release()
+	var items []*PriceStamp
+	for it.Next() {
+		stamp, err := decodeStamp(it)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, stamp)
+	}
+	return items, it.Error()
+}
+
+// ClosestTwo returns the last value of a source at or before at and the first one after it.
+func (h *historyStore) ClosestTwo(id string, at time.Time) (before, after *PriceStamp, err error) {
+	it := h.db.NewIterator(util.BytesPrefix(sourcePrefix(id)), nil)
+	defer it.Release()
+	next := historyKey(id, at.Add(1))
+	if it.Seek(next) {
+		if after, err = decodeStamp(it); err != nil {
+			return nil, nil, err
+		}
+		if it.Prev() {
+			before, err = decodeStamp(it)
+		}
+	} else if it.Last() {
+		before, err = decodeStamp(it)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, it.Error()
+}
+
+// Sources returns the IDs of all sources with a history.
+func (h *historyStore) Sources() ([]string, error) {
+	var ids []string
+	it := h.db.NewIterator(util.BytesPrefix([]byte(historyPrefix)), nil)
+	defer it.Release()
+	for ok := it.First(); ok; {
+		key := it.Key()[len(historyPrefix):]
+		end := bytes.IndexByte(key, historySeparator)
+		if end < 0 {
+			return nil, errors.Errorf("invalid history entry key:%x", it.Key())
+		}
+		id := string(key[:end])
+		ids = append(ids, id)
+		// Skip all other values of this source.
+		ok = it.Seek(util.BytesPrefix(sourcePrefix(id)).Limit)
+	}
+	return ids, it.Error()
+}
+
+// Compact deletes the values older than the retention and
+// downsamples the values older than downsampleAfter to one value per downsampleInterval.
+func (h *historyStore) Compact(now time.Time) error {
+	ids, err := h.Sources()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := h.compactSource(id, now); err != nil {
+			return errors.Wrapf(err, "compact history of:%v", id)
+		}
+	}
+	return nil
+}
+
+func (h *historyStore) compactSource(id string, now time.Time) error {
+	batch := new(leveldb.Batch)
+
+	// Delete expired values.
+	expired := now.Add(-h.retention)
+	it := h.db.NewIterator(&util.Range{Start: sourcePrefix(id), Limit: historyKey(id, expired)}, nil)
+	for it.Next() {
+		batch.Delete(append([]byte{}, it.Key()...))
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	if h.downsampleInterval > 0 && h.downsampleAfter > 0 {
+		// Only complete intervals that weren't downsampled yet.
+		from := expired
+		marker, err := h.db.Get([]byte(downsampledPrefix+id), nil)
+		if err != nil && err != leveldb.ErrNotFound {
+			return err
+		}
+		if len(marker) == 8 {
+			if done := time.Unix(0, int64(binary.BigEndian.Uint64(marker))); done.After(from) {
+				from = done
+			}
+		}
+		until := now.Add(-h.downsampleAfter).Truncate(h.downsampleInterval)
+		if until.After(from) {
+			if err := h.downsample(batch, id, from, until); err != nil {
+				return err
+			}
+			ts := make([]byte, 8)
+			binary.BigEndian.PutUint64(ts, uint64(until.UnixNano()))
+			batch.Put([]byte(downsampledPrefix+id), ts)
+		}
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+	return h.db.Write(batch, nil)
+}
+
+// downsample replaces the values of every interval between from and until
+// with a single value at the start of the interval that has the mean price and volume.
+func (h *historyStore) downsample(batch *leveldb.Batch, id string, from, until time.Time) error {
+	it := h.db.NewIterator(&util.Range{Start: historyKey(id, from), Limit: historyKey(id, until)}, nil)
+	defer it.Release()
+
+	var bucket time.Time
+	var keys [][]byte
+	var sum PriceInfo
+	flush := func() {
+		if len(keys) <= 1 {
+			return
+		}
+		for _, key := range keys {
+			batch.Delete(key)
+		}
+		n := float64(len(keys))
+		batch.Put(historyKey(id, bucket), encodePriceInfo(PriceInfo{Price: sum.Price / n, Volume: sum.Volume / n}))
+	}
+	for it.Next() {
+		stamp, err := decodeStamp(it)
+		if err != nil {
+			return err
+		}
+		if b := stamp.Created.Truncate(h.downsampleInterval); !b.Equal(bucket) {
+			flush()
+			bucket, keys, sum = b, nil, PriceInfo{}
+		}
+		keys = append(keys, append([]byte{}, it.Key()...))
+		sum.Price += stamp.Price
+		sum.Volume += stamp.Volume
+	}
+	flush()
+	return it.Error()
+}
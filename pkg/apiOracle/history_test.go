@@ -0,0 +1,140 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package apiOracle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestHistoryStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := openHistoryStore(dir, 7*24*time.Hour, 48*time.Hour, 10*time.Minute)
+	testutil.Ok(t, err)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 10; i++ {
+		testutil.Ok(t, insert(store, "a", &PriceStamp{Created: start.Add(time.Duration(i) * time.Minute), PriceInfo: PriceInfo{Price: float64(i)}}))
+	}
+	// A source with an ID that has the other one as a prefix.
+	testutil.Ok(t, insert(store, "ab", &PriceStamp{Created: start, PriceInfo: PriceInfo{Price: 100}}))
+	// Older than the newest value or the retention are ignored.
+	testutil.Ok(t, insert(store, "a", &PriceStamp{Created: start, PriceInfo: PriceInfo{Price: 100}}))
+	testutil.Ok(t, insert(store, "c", &PriceStamp{Created: start.Add(-8 * 24 * time.Hour), PriceInfo: PriceInfo{Price: 100}}))
+
+	values, err := store.WithinRange("a", start.Add(5*time.Minute), 2*time.Minute)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(values))
+	testutil.Equals(t, 3.0, values[0].Price)
+	testutil.Equals(t, 5.0, values[2].Price)
+
+	before, after, err := store.ClosestTwo("a", start.Add(90*time.Second))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1.0, before.Price)
+	testutil.Equals(t, 2.0, after.Price)
+	before, after, err = store.ClosestTwo("a", start.Add(time.Hour))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 9.0, before.Price)
+	testutil.Assert(t, after == nil, "no value after the newest")
+	before, after, err = store.ClosestTwo("ab", start.Add(-time.Second))
+	testutil.Ok(t, err)
+	testutil.Assert(t, before == nil, "no value before the oldest")
+	testutil.Equals(t, 100.0, after.Price)
+
+	sources, err := store.Sources()
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"a", "ab"}, sources)
+
+	// The history survives a restart.
+	testutil.Ok(t, store.Close())
+	store, err = openHistoryStore(dir, 7*24*time.Hour, 48*time.Hour, 10*time.Minute)
+	testutil.Ok(t, err)
+	defer store.Close()
+	values, err = store.WithinRange("a", start.Add(time.Hour), time.Hour)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 10, len(values))
+	// The newest value is still respected after a restart.
+	testutil.Ok(t, insert(store, "a", &PriceStamp{Created: start, PriceInfo: PriceInfo{Price: 100}}))
+	values, err = store.WithinRange("a", start, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0.0, values[0].Price)
+}
+
+func TestHistoryCompact(t *testing.T) {
+	store, err := openHistoryStore("", 7*24*time.Hour, 48*time.Hour, 10*time.Minute)
+	testutil.Ok(t, err)
+	defer store.Close()
+
+	now := time.Now()
+	// Two days and an hour of values every minute.
+	start := now.Add(-49 * time.Hour).Truncate(10 * time.Minute)
+	for i := 0; i < 49*60; i++ {
+		testutil.Ok(t, insert(store, "a", &PriceStamp{Created: start.Add(time.Duration(i) * time.Minute), PriceInfo: PriceInfo{Price: float64(i % 10), Volume: 1}}))
+	}
+	testutil.Ok(t, store.Compact(now))
+
+	// Old values are downsampled to the mean of every 10 minutes.
+	values, err := store.WithinRange("a", start.Add(59*time.Minute), time.Hour)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 6, len(values))
+	for i, v := range values {
+		testutil.Equals(t, start.Add(time.Duration(i)*10*time.Minute).UnixNano(), v.Created.UnixNano())
+		testutil.Equals(t, 4.5, v.Price)
+		testutil.Equals(t, 1.0, v.Volume)
+	}
+	// Recent values are kept.
+	values, err = store.WithinRange("a", now, time.Hour)
+	testutil.Ok(t, err)
+	testutil.Assert(t, len(values) >= 50, "recent values shouldn't be downsampled, got:%v", len(values))
+
+	// Expired values are deleted.
+	testutil.Ok(t, store.Compact(now.Add(6*24*time.Hour+30*time.Minute)))
+	values, err = store.WithinRange("a", start.Add(time.Hour), time.Hour)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(values))
+}
+
+func TestHistoryBackfill(t *testing.T) {
+	store, err := openHistoryStore("", 7*24*time.Hour, 48*time.Hour, 10*time.Minute)
+	testutil.Ok(t, err)
+	defer store.Close()
+
+	now := time.Now()
+	start := now.Add(-49 * time.Hour).Truncate(10 * time.Minute)
+	for i := 0; i < 10; i++ {
+		testutil.Ok(t, insert(store, "a", &PriceStamp{Created: start.Add(time.Duration(i) * time.Minute), PriceInfo: PriceInfo{Price: 1}}))
+	}
+	testutil.Ok(t, insert(store, "a", &PriceStamp{Created: now, PriceInfo: PriceInfo{Price: 1}}))
+	testutil.Ok(t, store.Compact(now))
+
+	// Older values are added and the values at the time of an existing value are skipped.
+	added, err := store.Backfill("a", &PriceStamp{Created: start.Add(15 * time.Minute), PriceInfo: PriceInfo{Price: 2}})
+	testutil.Ok(t, err)
+	testutil.Assert(t, added, "an older value should be backfilled")
+	added, err = store.Backfill("a", &PriceStamp{Created: start.Add(16 * time.Minute), PriceInfo: PriceInfo{Price: 4}})
+	testutil.Ok(t, err)
+	testutil.Assert(t, added, "an older value should be backfilled")
+	added, err = store.Backfill("a", &PriceStamp{Created: start, PriceInfo: PriceInfo{Price: 3}})
+	testutil.Ok(t, err)
+	testutil.Assert(t, !added, "a value at the time of an existing value should be skipped")
+	added, err = store.Backfill("a", &PriceStamp{Created: now.Add(-8 * 24 * time.Hour), PriceInfo: PriceInfo{Price: 3}})
+	testutil.Ok(t, err)
+	testutil.Assert(t, !added, "an expired value should be skipped")
+
+	// The backfilled values in downsampled intervals are downsampled with the next compaction.
+	testutil.Ok(t, store.Compact(now))
+	values, err := store.WithinRange("a", start.Add(30*time.Minute), 30*time.Minute)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(values))
+	testutil.Equals(t, 1.0, values[0].Price)
+	testutil.Equals(t, start.Add(10*time.Minute).UnixNano(), values[1].Created.UnixNano())
+	testutil.Equals(t, 3.0, values[1].Price)
+}
+
+func insert(store *historyStore, id string, x *PriceStamp) error {
+	_, err := store.Insert(id, x)
+	return err
+}
@@ -20,81 +20,95 @@ import (
 
 const ComponentName = "apiOracle"
 
-// maps symbol to a time window of values.
-var valueHistory map[string]*Window
+// compactInterval is how often the expired values are deleted and the old values downsampled.
+const compactInterval = 10 * time.Minute
+
+// keeps the value history of every source.
+var valueHistory *historyStore
 var valueHistoryMutex sync.RWMutex
+var valueHistoryLogger log.Logger
+var valueHistoryDone chan struct{}
+var valueHistoryStopped chan struct{}
 
-func GetNearestTwoRequestValue(id string, at time.Time) (before, after *PriceStamp) {
+// history returns the value history store or nil when the value oracle isn't opened.
+func history() *historyStore {
 	valueHistoryMutex.RLock()
 	defer valueHistoryMutex.RUnlock()
-	w, ok := valueHistory[id]
-	if !ok {
+	return valueHistory
+}
+
+func GetNearestTwoRequestValue(id string, at time.Time) (before, after *PriceStamp) {
+	h := history()
+	if h == nil {
+		return nil, nil
+	}
+	before, after, err := h.ClosestTwo(id, at)
+	if err != nil {
+		level.Error(valueHistoryLogger).Log("msg", "read value history", "id", id, "err", err)
 		return nil, nil
 	}
-	return w.ClosestTwo(at)
+	return before, after
 }
 
 func GetRequestValuesForTime(id string, at time.Time, delta time.Duration) []*PriceStamp {
-	valueHistoryMutex.RLock()
-	defer valueHistoryMutex.RUnlock()
-	w, ok := valueHistory[id]
-	if !ok {
+	h := history()
+	if h == nil {
+		return nil
+	}
+	values, err := h.WithinRange(id, at, delta)
+	if err != nil {
+		level.Error(valueHistoryLogger).Log("msg", "read value history", "id", id, "err", err)
 		return nil
 	}
-	return w.WithinRange(at, delta)
+	return values
 }
 
 func SetRequestValue(id string, at time.Time, info PriceInfo) {
-
-	valueHistoryMutex.Lock()
-	_, ok := valueHistory[id]
-	if !ok {
-		valueHistory[id] = NewWindow(7 * 24 * time.Hour)
+	h := history()
+	if h == nil {
+		return
 	}
-	valueHistory[id].Insert(&PriceStamp{
+	_, err := h.Insert(id, &PriceStamp{
 		Created:   at,
 		PriceInfo: info,
 	})
-	valueHistoryMutex.Unlock()
-}
-
-func writeOutHistory(logger log.Logger) {
-	valueHistoryMutex.Lock()
-	for _, v := range valueHistory {
-		v.Trim()
-	}
-	data, err := json.MarshalIndent(valueHistory, "", "\t")
-
-	// In order to not hold up the rest of the program, we release the mutex while we write out the file
-	// this function is single threaded, but we need mutex to access multithreaded history.
-	valueHistoryMutex.Unlock()
 	if err != nil {
-		level.Error(logger).Log("msg", "marshal PSR values", "err", err)
-		return
+		level.Error(valueHistoryLogger).Log("msg", "write value history", "id", id, "err", err)
 	}
+}
 
-	cfg := config.GetConfig()
-	psrSavedData := filepath.Join(cfg.ConfigFolder, "saved.json")
-	psrSavedDataTmp := psrSavedData + ".tmp"
-	err = ioutil.WriteFile(psrSavedDataTmp, data, 0644)
+// migrateSavedHistory moves the values of the saved.json file,
+// used by older versions to keep the history, into the history store.
+func migrateSavedHistory(logger log.Logger, cfg *config.Config, store *historyStore) error {
+	historyPath := filepath.Join(cfg.ConfigFolder, "saved.json")
+	byteValue, err := ioutil.ReadFile(historyPath)
 	if err != nil {
-		level.Error(logger).Log(
-			"msg", "write out PSR values",
-			"psrSavedDataTmp", psrSavedDataTmp,
-			"err", err,
-		)
-		return
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "read psr file:%v", historyPath)
 	}
-	// Rename tmp file to old file (should be atomic on most modern OS)
-	err = os.Rename(psrSavedDataTmp, psrSavedData)
-	if err != nil {
-		level.Error(logger).Log("msg", "move new PSR save onto old", "err", err)
-		return
+	var saved map[string]*Window
+	if err := json.Unmarshal(byteValue, &saved); err != nil {
+		return errors.Wrap(err, "unmarshal saved values")
+	}
+	for id, w := range saved {
+		n := len(w.buffer)
+		for i := 0; i < w.num; i++ {
+			if _, err := store.Insert(id, w.buffer[(w.start+i)%n]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(historyPath, historyPath+".migrated"); err != nil {
+		return errors.Wrap(err, "rename migrated psr file")
 	}
+	level.Info(logger).Log("msg", "migrated the saved values to the history db", "sources", len(saved))
+	return nil
 }
 
 func EnsureValueOracle(logger log.Logger, cfg *config.Config) error {
-	if valueHistory != nil {
+	if history() != nil {
 		return nil
 	}
 
@@ -112,36 +126,55 @@ func EnsureValueOracle(logger log.Logger, cfg *config.Config) error {
 	}
 	logger = log.With(logger, "component", ComponentName)
 
-	historyPath := filepath.Join(cfg.ConfigFolder, "saved.json")
-
-	_, err = os.Stat(historyPath)
-	exists := true
+	store, err := openHistoryStore(
+		cfg.History.File,
+		cfg.History.Retention.Duration,
+		cfg.History.DownsampleAfter.Duration,
+		cfg.History.DownsampleInterval.Duration,
+	)
 	if err != nil {
-		if os.IsNotExist(err) {
-			exists = false
-		} else {
-			return errors.Wrapf(err, "stat error file: %v", historyPath)
-		}
+		return err
 	}
-
-	if exists {
-		byteValue, err := ioutil.ReadFile(historyPath)
-		if err != nil {
-			return errors.Wrapf(err, "read psr file:%v", historyPath)
-		}
-		err = json.Unmarshal(byteValue, &valueHistory)
-		if err != nil {
-			return errors.Errorf("unmarshal saved values")
-		}
-	} else {
-		valueHistory = make(map[string]*Window)
+	if err := migrateSavedHistory(logger, cfg, store); err != nil {
+		store.Close()
+		return err
 	}
-	// Periodically flush the value history to disk to create a record for disputes
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	valueHistory = store
+	valueHistoryLogger = logger
+	valueHistoryDone = done
+	valueHistoryStopped = stopped
+
+	// Periodically delete expired values and downsample old ones.
 	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(compactInterval)
+		defer ticker.Stop()
 		for {
-			time.Sleep(2 * time.Minute)
-			writeOutHistory(logger)
+			if err := store.Compact(time.Now()); err != nil {
+				level.Error(logger).Log("msg", "compact value history", "err", err)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
 		}
 	}()
 	return nil
 }
+
+// CloseValueOracle stops the compaction and closes the value history.
+func CloseValueOracle() error {
+	valueHistoryMutex.Lock()
+	defer valueHistoryMutex.Unlock()
+	if valueHistory == nil {
+		return nil
+	}
+	close(valueHistoryDone)
+	<-valueHistoryStopped
+	err := valueHistory.Close()
+	valueHistory = nil
+	return errors.Wrap(err, "close history db")
+}
@@ -1,10 +0,0 @@
-// Copyright (c) The Tellor Authors.
-// Licensed under the MIT License.
-
-package common
-
-var (
-	// PriceTXs is the key used to save transactions cost
-	// These are used to calculate the profitability when submitting a solution.
-	PriceTXs = "PriceTXSlot"
-)
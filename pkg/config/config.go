@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -47,42 +48,213 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 type DataServer struct {
 	ListenHost string
 	ListenPort uint
+	// ClockSkew is how far the time of a signed RPC request can be off the server time.
+	ClockSkew Duration
+	// LegacyProtocol enables the binary protocol of the older miners.
+	LegacyProtocol bool
+	// TLS of the data server, the CA enables the client certificate verification.
+	TLS TLS
+	// HealthMaxAge is how long the data server is healthy after the last write to its DB.
+	HealthMaxAge Duration
+	// Miners are the scopes of the whitelisted miners by their address,
+	// the miners without a scope can read all the shared keys and write their own keys.
+	Miners map[string]MinerScope
+	// AuditLog is the file where the rejected miner requests are recorded.
+	AuditLog string
+}
+
+// MinerScope limits the keys a miner can access and the rate of its requests.
+// A key pattern ending with * matches all the keys with this prefix.
+type MinerScope struct {
+	// Read are the patterns of the keys the miner can read, all the shared keys when empty.
+	// The keys prefixed with the address of the miner can always be read.
+	Read []string
+	// Write are the patterns of the keys the miner can write, without its address prefix.
+	// All the keys prefixed with the address of the miner when empty.
+	Write []string
+	// RateLimit is the maximum number of requests per second, 0 disables the limit.
+	RateLimit float64
+	// RateBurst is the number of requests allowed at once above the rate limit.
+	RateBurst int
+}
+
+// TLS configures the encryption of the link between the miners and the data server.
+type TLS struct {
+	Enabled bool
+	// CertFile and KeyFile are the certificate of the data server or the client certificate of a miner.
+	CertFile string
+	KeyFile  string
+	// CAFile is used to verify the certificate of the other side instead of the system CAs.
+	CAFile string
 }
 
 type Mine struct {
 	// Connect to this remote DB.
 	RemoteDBHost string
 	RemoteDBPort uint
+	// RemoteDBEndpoints are the host:port of several remote DBs to fail over between.
+	RemoteDBEndpoints []string
+	// RemoteDBTLS enables https to the remote DB.
+	RemoteDBTLS TLS
+	// RemoteDBSigner is the address of the remote DB that signs the streamed updates.
+	RemoteDBSigner string
 	// Exposes metrics on this host and port.
 	ListenHost string
 	ListenPort uint
 }
 
+// RemoteDBs returns the host:port of the remote DBs, empty when mining uses the local DB.
+func (m Mine) RemoteDBs() []string {
+	if len(m.RemoteDBEndpoints) > 0 {
+		return m.RemoteDBEndpoints
+	}
+	if m.RemoteDBHost != "" {
+		return []string{m.RemoteDBHost + ":" + strconv.Itoa(int(m.RemoteDBPort))}
+	}
+	return nil
+}
+
+// History configures the value history of the index trackers.
+type History struct {
+	// File of the history DB, when empty or with the memory DB backend the history is kept in memory only.
+	File string
+	// Values older than this are deleted.
+	Retention Duration
+	// Values older than this are downsampled to one value per DownsampleInterval.
+	DownsampleAfter    Duration
+	DownsampleInterval Duration
+}
+
+// Events configures the index of the Tellor contract events.
+type Events struct {
+	// File of the events DB, when empty or with the memory DB backend the events are kept in memory only.
+	File string
+	// StartBlocks is how many blocks before the head the indexing starts when the index is empty.
+	StartBlocks uint64
+	// Confirmations is how many blocks the index stays behind the head to avoid chain reorganizations.
+	Confirmations uint64
+	// BatchBlocks is how many blocks are requested from the node at once.
+	BatchBlocks uint64
+	// Interval between the index updates of the data server.
+	Interval Duration
+}
+
+// Alerting configures the notifications about critical conditions.
+type Alerting struct {
+	Webhooks []Webhook
+	// Slack are the URLs of Slack incoming webhooks.
+	Slack []string
+	SMTP  SMTP
+	// RepeatInterval is how long the same alert isn't sent again.
+	RepeatInterval Duration
+	// RateLimit is the maximum number of alerts sent per hour, 0 disables the limit.
+	RateLimit float64
+	Rules     AlertRules
+}
+
+// Webhook is a generic webhook notifier.
+type Webhook struct {
+	URL string
+	// Template is a Go template of the JSON body, the alert as JSON when empty.
+	// The json function encodes a value as JSON, e.g. {"msg": {{json .Summary}}}.
+	Template string
+}
+
+// SMTP is an email notifier, the password is read from the SMTPPasswordEnvName env variable.
+type SMTP struct {
+	Host     string
+	Port     uint
+	Username string
+	From     string
+	To       []string
+}
+
+// AlertRules selects the conditions to alert about.
+type AlertRules struct {
+	// MinETHBalance and MinTRBBalance are the balances below which to alert, 0 disables the rule.
+	MinETHBalance float64
+	MinTRBBalance float64
+	// StakeStatus alerts when the stake status changes.
+	StakeStatus bool
+	// SubmitFails is the number of consecutive failed submissions to alert about, 0 disables the rule.
+	SubmitFails int
+	// StaleChallenge is how long the challenge can stay the same before alerting, 0 disables the rule.
+	StaleChallenge Duration
+	// DisputeCandidates alerts about the values found by the dispute checker.
+	DisputeCandidates bool
+}
+
+// Disputes configures the automated dispute actions of the dispute checker.
+type Disputes struct {
+	// AutoVote votes on open disputes according to the value history.
+	AutoVote bool
+	// AutoDispute begins a dispute for a submitted value that is off
+	// by more than DisputeMultiple times the disputeThreshold.
+	AutoDispute     bool
+	DisputeMultiple float64
+	// DryRun only logs and audits the actions without sending any transactions.
+	DryRun bool
+	// MinConfidence is the minimum confidence of a datapoint used to check a value.
+	MinConfidence float64
+	// MinDatapoints is the minimum number of datapoints needed to vote or dispute.
+	MinDatapoints int
+	// AuditLog is the file where every action is recorded.
+	AuditLog string
+	// CheckerStartBlock is the block the dispute checker starts from without a checkpoint,
+	// 0 starts from the head of the chain.
+	CheckerStartBlock uint64
+	// OutlierThreshold is the maximum relative difference between a submitted value
+	// and the median of the other miners or the final value of the same value.
+	OutlierThreshold float64
+}
+
+// Redis is the server of the redis DB backend, shared by several data servers.
+// The password is read from the RedisPasswordEnvName env variable.
+type Redis struct {
+	// Address is the host:port of the server.
+	Address string
+	// Database is the number of the redis database.
+	Database int
+	// Prefix is prepended to all keys so that several deployments can use the same server.
+	Prefix string
+}
+
 // Config holds global config info derived from config.json.
 type Config struct {
 	Mine                         Mine
 	DataServer                   DataServer
-	PublicAddress                string            `json:"publicAddress"`
-	EthClientTimeout             uint              `json:"ethClientTimeout"`
-	MinSubmitPeriod              Duration          `json:"minSubmitPeriod"`
-	TrackerSleepCycle            Duration          `json:"trackerCycle"`
-	Trackers                     map[string]bool   `json:"trackers"`
-	DBFile                       string            `json:"dbFile"`
-	FetchTimeout                 Duration          `json:"fetchTimeout"`
-	MinConfidence                float64           `json:"minConfidence"`
-	MiningInterruptCheckInterval Duration          `json:"miningInterruptCheckInterval"`
-	GasMultiplier                float32           `json:"gasMultiplier"`
-	GasMax                       uint              `json:"gasMax"`
-	NumProcessors                int               `json:"numProcessors"`
-	Heartbeat                    Duration          `json:"heartbeat"`
-	ServerWhitelist              []string          `json:"serverWhitelist"`
-	Worker                       string            `json:"worker"`
-	Password                     string            `json:"password"`
-	PoolURL                      string            `json:"poolURL"`
-	ConfigFolder                 string            `json:"configFolder"`
-	Logger                       map[string]string `json:"logger"`
-	DisputeTimeDelta             Duration          `json:"disputeTimeDelta"` // Ignore data further than this away from the value we are checking.
-	DisputeThreshold             float64           `json:"disputeThreshold"` // Maximum allowed relative difference between observed and submitted value.
+	History                      History
+	Events                       Events
+	Alerting                     Alerting
+	Disputes                     Disputes
+	PublicAddress                string             `json:"publicAddress"`
+	EthClientTimeout             uint               `json:"ethClientTimeout"`
+	MinSubmitPeriod              Duration           `json:"minSubmitPeriod"`
+	TrackerSleepCycle            Duration           `json:"trackerCycle"`
+	Trackers                     map[string]bool    `json:"trackers"`
+	DBFile                       string             `json:"dbFile"`
+	DBBackend                    string             `json:"dbBackend"` // One of leveldb, memory, bolt or redis.
+	DBRedis                      Redis              `json:"dbRedis"`
+	FetchTimeout                 Duration           `json:"fetchTimeout"`
+	FetchRateLimit               float64            `json:"fetchRateLimit"`      // Maximum requests per second to a single API host, 0 disables the limit.
+	FetchRateBurst               int                `json:"fetchRateBurst"`      // Requests to a single API host allowed at once above the rate limit.
+	FetchHostRateLimits          map[string]float64 `json:"fetchHostRateLimits"` // Per host overrides of the fetchRateLimit.
+	FetchCacheTTL                Duration           `json:"fetchCacheTTL"`       // How long an API response is reused for identical requests.
+	FetchMaxBackoff              Duration           `json:"fetchMaxBackoff"`     // Maximum delay between retries of a failed API request.
+	MinConfidence                float64            `json:"minConfidence"`
+	MiningInterruptCheckInterval Duration           `json:"miningInterruptCheckInterval"`
+	GasMultiplier                float32            `json:"gasMultiplier"`
+	GasMax                       uint               `json:"gasMax"`
+	NumProcessors                int                `json:"numProcessors"`
+	Heartbeat                    Duration           `json:"heartbeat"`
+	ServerWhitelist              []string           `json:"serverWhitelist"`
+	Worker                       string             `json:"worker"`
+	Password                     string             `json:"password"`
+	PoolURL                      string             `json:"poolURL"`
+	ConfigFolder                 string             `json:"configFolder"`
+	Logger                       map[string]string  `json:"logger"`
+	DisputeTimeDelta             Duration           `json:"disputeTimeDelta"` // Ignore data further than this away from the value we are checking.
+	DisputeThreshold             float64            `json:"disputeThreshold"` // Maximum allowed relative difference between observed and submitted value.
 	// Minimum percent of profit when submitting a solution.
 	// For example if the tx cost is 0.01 ETH and current reward is 0.02 ETH
 	// a ProfitThreshold of 200% or more will wait until the reward is increased or
@@ -107,13 +279,58 @@ var defaultConfig = Config{
 		ListenPort: 9090,
 	},
 	DataServer: DataServer{
-		ListenHost: "localhost",
-		ListenPort: 5000,
+		ListenHost:     "localhost",
+		ListenPort:     5000,
+		ClockSkew:      Duration{30 * time.Second},
+		LegacyProtocol: true,
+		HealthMaxAge:   Duration{5 * time.Minute},
+		AuditLog:       "dataserver-audit.log",
+	},
+	History: History{
+		File:               "history",
+		Retention:          Duration{7 * 24 * time.Hour},
+		DownsampleAfter:    Duration{48 * time.Hour},
+		DownsampleInterval: Duration{10 * time.Minute},
+	},
+	Events: Events{
+		File:          "events",
+		StartBlocks:   10e3 * 14,
+		Confirmations: 6,
+		BatchBlocks:   5000,
+		Interval:      Duration{time.Minute},
+	},
+	Disputes: Disputes{
+		DisputeMultiple:  5,
+		DryRun:           true,
+		MinConfidence:    0.8,
+		MinDatapoints:    3,
+		AuditLog:         "dispute-audit.log",
+		OutlierThreshold: 0.05,
+	},
+	Alerting: Alerting{
+		RepeatInterval: Duration{time.Hour},
+		RateLimit:      30,
+		Rules: AlertRules{
+			MinETHBalance:     0.1,
+			StakeStatus:       true,
+			SubmitFails:       5,
+			StaleChallenge:    Duration{30 * time.Minute},
+			DisputeCandidates: true,
+		},
+	},
+	DBRedis: Redis{
+		Address: "localhost:6379",
+		Prefix:  "telliot:",
 	},
 	Heartbeat:                    Duration{15 * time.Second},
 	DBFile:                       "db",
+	DBBackend:                    "leveldb",
 	MiningInterruptCheckInterval: Duration{15 * time.Second},
 	FetchTimeout:                 Duration{30 * time.Second},
+	FetchRateLimit:               2,
+	FetchRateBurst:               5,
+	FetchCacheTTL:                Duration{10 * time.Second},
+	FetchMaxBackoff:              Duration{10 * time.Second},
 	TrackerSleepCycle:            Duration{30 * time.Second},
 	DisputeTimeDelta:             Duration{5 * time.Minute},
 	NumProcessors:                2,
@@ -138,12 +355,17 @@ var defaultConfig = Config{
 		"ops":        "info",
 		"rest":       "info",
 		"apiOracle":  "info",
+		"events":     "info",
+		"alerting":   "info",
 	},
 	EnvFile: path.Join(ConfigFolder, ".env"),
 }
 
 const PrivateKeyEnvName = "ETH_PRIVATE_KEY"
 const NodeURLEnvName = "NODE_URL"
+const SMTPPasswordEnvName = "SMTP_PASSWORD"
+const APITokenEnvName = "DATASERVER_API_TOKEN"
+const RedisPasswordEnvName = "DB_REDIS_PASSWORD"
 
 // ParseConfig and set a shared config entry.
 func ParseConfig(path string) error {
@@ -174,6 +396,13 @@ func ParseConfigBytes(data []byte) error {
 		}
 	}
 
+	// The value history and the event index are LevelDBs of their own for any DB backend.
+	// With the memory backend they are kept in memory too.
+	if config.DBBackend == "memory" {
+		config.History.File = ""
+		config.Events.File = ""
+	}
+
 	os.Setenv(PrivateKeyEnvName, strings.ToLower(strings.ReplaceAll(os.Getenv(PrivateKeyEnvName), "0x", "")))
 	config.PublicAddress = strings.ToLower(strings.ReplaceAll(config.PublicAddress, "0x", ""))
 
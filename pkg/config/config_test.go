@@ -5,6 +5,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/tellor-io/telliot/pkg/testutil"
@@ -40,3 +41,12 @@ func TestConfig(t *testing.T) {
 	testutil.Assert(t, cfg.DisputeThreshold > 0, "DisputeThreshold should have value")
 
 }
+
+func TestMemoryBackendLocalStores(t *testing.T) {
+	cfg := OpenTestConfig(t)
+	t.Cleanup(func() { cfg.DBBackend = "leveldb" })
+	memoryConfig := strings.Replace(mainConfig, `"History": {"File": ""},`, `"History": {"File": "history"}, "Events": {"File": "events"}, "dbBackend": "memory",`, 1)
+	testutil.Ok(t, ParseConfigBytes([]byte(memoryConfig)))
+	testutil.Equals(t, "", cfg.History.File)
+	testutil.Equals(t, "", cfg.Events.File)
+}
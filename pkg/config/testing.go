@@ -18,6 +18,8 @@ var mainConfig = `
     "trackerCycle": 1,
     "trackers": {},
     "dbFile": "/tellorDB",
+    "History": {"File": ""},
+    "DataServer": {"AuditLog": ""},
     "requestTips": 1,
     "configFolder": "` + filepath.Join("..", "..", "configs") + `",
     "envFile": "` + filepath.Join("..", "..", "configs", ".env.example") + `"
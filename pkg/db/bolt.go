@@ -0,0 +1,128 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket holds all the keys of the BoltDB backend.
+var boltBucket = []byte("telliot")
+
+// boltDB is a DB in a single BoltDB file.
+// Every write is a transaction synced to the disk so a crash can't corrupt it.
+type boltDB struct {
+	db     *bolt.DB
+	logger log.Logger
+}
+
+func openBolt(logger log.Logger, file string) (*boltDB, error) {
+	db, err := bolt.Open(file, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	b := &boltDB{db: db, logger: logger}
+	level.Info(b.logger).Log("msg", "created BoltDB", "at", file)
+	return b, nil
+}
+
+func (b *boltDB) Close() error {
+	level.Info(b.logger).Log("msg", "closing db")
+	return b.db.Close()
+}
+
+func (b *boltDB) Has(key string) (bool, error) {
+	var has bool
+	err := b.db.View(func(tx *bolt.Tx) error {
+		has = tx.Bucket(boltBucket).Get([]byte(key)) != nil
+		return nil
+	})
+	return has, err
+}
+
+func (b *boltDB) Put(key string, value []byte) error {
+	level.Debug(b.logger).Log(
+		"msg", "adding DB entry",
+		"key", key,
+		"bytes", len(value),
+	)
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(key), value)
+	})
+}
+
+func (b *boltDB) Get(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// The value is only valid during the transaction.
+		if v := tx.Bucket(boltBucket).Get([]byte(key)); v != nil {
+			value = append([]byte{}, v...)
+		}
+		return nil
+	})
+	if err == nil && value == nil {
+		level.Debug(b.logger).Log(
+			"msg", "did not find value",
+			"key", key,
+		)
+	}
+	return value, err
+}
+
+func (b *boltDB) Delete(key string) error {
+	level.Debug(b.logger).Log("msg", "deleting key", "key", key)
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(key))
+	})
+}
+
+func (b *boltDB) Iterate(prefix string, fn func(key string, value []byte) error) error {
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		p := []byte(prefix)
+		for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+			if err := fn(string(k), append([]byte{}, v...)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == ErrStopIteration {
+		return nil
+	}
+	return err
+}
+
+func (b *boltDB) log() log.Logger {
+	return b.logger
+}
+
+func (b *boltDB) Batch(batch *Batch) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for k, v := range batch.puts {
+			if err := bucket.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		for k := range batch.deletes {
+			if err := bucket.Delete([]byte(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -4,12 +4,17 @@
 package db
 
 import (
+	"os"
+
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/errors"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/logging"
 )
@@ -27,22 +32,92 @@ const (
 	minHandles = 8
 )
 
+// The backends selected by the dbBackend config option.
+const (
+	BackendLevelDB = "leveldb"
+	BackendMemory  = "memory"
+	BackendBolt    = "bolt"
+	BackendRedis   = "redis"
+)
+
 // DB is the primary interface to an underlying datastore.
 type DB interface {
 	Has(key string) (bool, error)
 	Put(key string, value []byte) error
 	Get(key string) ([]byte, error)
 	Delete(key string) error
+	// Batch applies all the writes of the batch atomically.
+	Batch(b *Batch) error
+	// Iterate calls fn for every key with the prefix in key order until fn returns an error.
+	// Returning ErrStopIteration ends the iteration without an error.
+	Iterate(prefix string, fn func(key string, value []byte) error) error
 	Close() error
 }
 
-type impl struct {
+// backend is implemented by all the DBs returned by Open.
+type backend interface {
+	DB
+	log() log.Logger
+}
+
+// Batch collects writes that are applied at once.
+// A later write of the same key replaces the earlier one.
+type Batch struct {
+	puts    map[string][]byte
+	deletes map[string]bool
+}
+
+func NewBatch() *Batch {
+	return &Batch{puts: make(map[string][]byte), deletes: make(map[string]bool)}
+}
+
+func (b *Batch) Put(key string, value []byte) {
+	delete(b.deletes, key)
+	b.puts[key] = value
+}
+
+func (b *Batch) Delete(key string) {
+	delete(b.puts, key)
+	b.deletes[key] = true
+}
+
+// Len returns the number of the written keys.
+func (b *Batch) Len() int {
+	return len(b.puts) + len(b.deletes)
+}
+
+// ErrStopIteration ends an iteration early without an error.
+var ErrStopIteration = errors.New("stop iteration")
+
+type levelDB struct {
 	db     *leveldb.DB
 	logger log.Logger
 }
 
-// Open the database using the given DB file as its data store.
+// Open the database of the backend selected by the dbBackend config option.
+// The file is the data store of the LevelDB and BoltDB backends.
 func Open(logger log.Logger, cfg *config.Config, file string) (DB, error) {
+	logger, err := logging.ApplyFilter(*cfg, ComponentName, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger = log.With(logger, "component", ComponentName)
+
+	switch cfg.DBBackend {
+	case BackendLevelDB, "":
+		return openLevelDB(logger, file)
+	case BackendMemory:
+		return openMemory(logger)
+	case BackendBolt:
+		return openBolt(logger, file)
+	case BackendRedis:
+		return openRedis(logger, cfg.DBRedis, os.Getenv(config.RedisPasswordEnvName))
+	default:
+		return nil, errors.Errorf("unknown DB backend:%v", cfg.DBBackend)
+	}
+}
+
+func openLevelDB(logger log.Logger, file string) (*levelDB, error) {
 	// Open the db and recover any potential corruptions.
 	db, err := leveldb.OpenFile(file, &opt.Options{
 		OpenFilesCacheCapacity: minHandles,
@@ -50,33 +125,39 @@ func Open(logger log.Logger, cfg *config.Config, file string) (DB, error) {
 		WriteBuffer:            minCache / 4 * opt.MiB, // Two of these are used internally.
 		Filter:                 filter.NewBloomFilter(10),
 	})
-	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
+	if _, corrupted := err.(*leveldbErrors.ErrCorrupted); corrupted {
 		db, err = leveldb.RecoverFile(file, nil)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	logger, err = logging.ApplyFilter(*cfg, ComponentName, logger)
+	i := &levelDB{db: db, logger: logger}
+	level.Info(i.logger).Log("msg", "created DB", "at", file)
+	return i, nil
+}
+
+// openMemory opens a LevelDB that keeps its data in memory only.
+func openMemory(logger log.Logger) (*levelDB, error) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
 	if err != nil {
 		return nil, err
 	}
-
-	i := &impl{db: db, logger: log.With(logger, "component", ComponentName)}
-	level.Info(i.logger).Log("msg", "created DB", "at", file)
+	i := &levelDB{db: db, logger: logger}
+	level.Info(i.logger).Log("msg", "created in memory DB")
 	return i, nil
 }
 
-func (i *impl) Close() error {
+func (i *levelDB) Close() error {
 	level.Info(i.logger).Log("msg", "closing db")
 	return i.db.Close()
 }
 
-func (i *impl) Has(key string) (bool, error) {
+func (i *levelDB) Has(key string) (bool, error) {
 	return i.db.Has([]byte(key), nil)
 }
 
-func (i *impl) Put(key string, value []byte) error {
+func (i *levelDB) Put(key string, value []byte) error {
 	level.Debug(i.logger).Log(
 		"msg", "adding DB entry",
 		"key", key,
@@ -85,9 +166,9 @@ func (i *impl) Put(key string, value []byte) error {
 	return i.db.Put([]byte(key), value, nil)
 }
 
-func (i *impl) Get(key string) ([]byte, error) {
+func (i *levelDB) Get(key string) ([]byte, error) {
 	b, e := i.db.Get([]byte(key), nil)
-	if e == errors.ErrNotFound {
+	if e == leveldbErrors.ErrNotFound {
 		level.Debug(i.logger).Log(
 			"msg", "did not find value",
 			"key", key,
@@ -97,7 +178,41 @@ func (i *impl) Get(key string) ([]byte, error) {
 	return b, e
 }
 
-func (i *impl) Delete(key string) error {
+func (i *levelDB) Delete(key string) error {
 	level.Debug(i.logger).Log("msg", "deleting key", "key", key)
 	return i.db.Delete([]byte(key), nil)
 }
+
+func (i *levelDB) Iterate(prefix string, fn func(key string, value []byte) error) error {
+	snap, err := i.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+	iter := snap.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		if err := fn(string(iter.Key()), append([]byte{}, iter.Value()...)); err != nil {
+			if err == ErrStopIteration {
+				return nil
+			}
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+func (i *levelDB) log() log.Logger {
+	return i.logger
+}
+
+func (i *levelDB) Batch(b *Batch) error {
+	lb := new(leveldb.Batch)
+	for k, v := range b.puts {
+		lb.Put([]byte(k), v)
+	}
+	for k := range b.deletes {
+		lb.Delete([]byte(k))
+	}
+	return i.db.Write(lb, nil)
+}
@@ -4,9 +4,18 @@
 package db
 
 import (
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/phayes/freeport"
+	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
 )
 
 func TestDB(t *testing.T) {
@@ -35,3 +44,133 @@ func TestDB(t *testing.T) {
 	}
 	t.Log("Retrieved " + s)
 }
+
+// TestBackends runs the same checks against every backend.
+// The redis backend is tested when redis-server is installed.
+func TestBackends(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	backends := map[string]func(t *testing.T) *config.Config{
+		BackendLevelDB: func(t *testing.T) *config.Config { return withBackend(cfg, BackendLevelDB) },
+		BackendMemory:  func(t *testing.T) *config.Config { return withBackend(cfg, BackendMemory) },
+		BackendBolt:    func(t *testing.T) *config.Config { return withBackend(cfg, BackendBolt) },
+		BackendRedis: func(t *testing.T) *config.Config {
+			c := withBackend(cfg, BackendRedis)
+			c.DBRedis.Address = startRedis(t)
+			c.DBRedis.Prefix = "test:"
+			return c
+		},
+	}
+	for name, backendCfg := range backends {
+		backendCfg := backendCfg
+		t.Run(name, func(t *testing.T) {
+			c := backendCfg(t)
+			file := filepath.Join(t.TempDir(), "db")
+			DB, err := Open(logging.NewLogger(), c, file)
+			testutil.Ok(t, err)
+			defer DB.Close()
+			testBackend(t, DB)
+
+			// The backends keep their values after they are reopened.
+			if name == BackendMemory {
+				return
+			}
+			testutil.Ok(t, DB.Put("reopened", []byte("value")))
+			testutil.Ok(t, DB.Close())
+			DB, err = Open(logging.NewLogger(), c, file)
+			testutil.Ok(t, err)
+			v, err := DB.Get("reopened")
+			testutil.Ok(t, err)
+			testutil.Equals(t, []byte("value"), v)
+		})
+	}
+}
+
+func withBackend(cfg *config.Config, backend string) *config.Config {
+	c := *cfg
+	c.DBBackend = backend
+	return &c
+}
+
+func testBackend(t *testing.T, DB DB) {
+	v, err := DB.Get("missing")
+	testutil.Ok(t, err)
+	testutil.Assert(t, v == nil, "expected no value for a missing key")
+	has, err := DB.Has("missing")
+	testutil.Ok(t, err)
+	testutil.Assert(t, !has, "expected a missing key")
+	testutil.Ok(t, DB.Delete("missing"))
+
+	testutil.Ok(t, DB.Put("key", []byte("value")))
+	testutil.Ok(t, DB.Put("key", []byte{0x00, 0xff}))
+	v, err = DB.Get("key")
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte{0x00, 0xff}, v)
+	has, err = DB.Has("key")
+	testutil.Ok(t, err)
+	testutil.Assert(t, has, "expected the key to be present")
+	testutil.Ok(t, DB.Delete("key"))
+	has, err = DB.Has("key")
+	testutil.Ok(t, err)
+	testutil.Assert(t, !has, "expected the key to be deleted")
+
+	// Batches apply the puts and the deletes at once.
+	testutil.Ok(t, DB.Put("p_deleted", []byte("0")))
+	b := NewBatch()
+	b.Put("p_2", []byte("2"))
+	b.Put("p_1", []byte("1"))
+	b.Put("p*", []byte("*"))
+	b.Put("q_1", []byte("q"))
+	b.Delete("p_deleted")
+	testutil.Ok(t, DB.Batch(b))
+
+	// The iteration is limited to the prefix, in key order and can be stopped.
+	var keys []string
+	testutil.Ok(t, DB.Iterate("p_", func(key string, value []byte) error {
+		keys = append(keys, key+"="+string(value))
+		return nil
+	}))
+	testutil.Equals(t, []string{"p_1=1", "p_2=2"}, keys)
+	keys = nil
+	testutil.Ok(t, DB.Iterate("", func(key string, value []byte) error {
+		keys = append(keys, key)
+		return ErrStopIteration
+	}))
+	testutil.Equals(t, []string{"p*"}, keys)
+	testutil.NotOk(t, DB.Iterate("", func(key string, value []byte) error {
+		return errors.New("failed")
+	}))
+
+	// A fresh DB is migrated to the latest version.
+	testutil.Ok(t, Migrate(DB, filepath.Join(t.TempDir(), "db")))
+	version, err := dbVersion(DB)
+	testutil.Ok(t, err)
+	testutil.Equals(t, len(migrations), version)
+}
+
+// startRedis starts a redis-server without persistence for the test.
+func startRedis(t *testing.T) string {
+	bin, err := exec.LookPath("redis-server")
+	if err != nil {
+		t.Skip("redis-server isn't installed")
+	}
+	port, err := freeport.GetFreePort()
+	testutil.Ok(t, err)
+	cmd := exec.Command(bin, "--port", strconv.Itoa(port), "--save", "", "--appendonly", "no")
+	testutil.Ok(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr := net.JoinHostPort("localhost", strconv.Itoa(port))
+	for i := 0; ; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return addr
+		}
+		if i == 50 {
+			t.Fatal("redis-server didn't start", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// HealthPath is the path of the health of the data server.
+const HealthPath = "/health"
+
+const (
+	// requestTimeout is how long a data server has to respond before failing over to the next one.
+	requestTimeout = 5 * time.Second
+	// endpointDownTime is how long a data server that failed is tried only after the others.
+	endpointDownTime = 30 * time.Second
+	// healthProbeInterval is how often the health of the data servers is checked.
+	healthProbeInterval = 30 * time.Second
+	// legacyRetryInterval is how long a data server without the RPC is sent
+	// legacy requests before trying the RPC again.
+	legacyRetryInterval = 5 * time.Minute
+)
+
+// Health is the health of a data server.
+type Health struct {
+	Healthy bool `json:"healthy"`
+	// Updated is the unix time of the last write to the data server DB.
+	Updated int64 `json:"updated"`
+}
+
+// endpoint is a remote data server.
+type endpoint struct {
+	url string
+	// updated is the last write to the data server DB.
+	updated int64
+	// downUntil is set after a failure to try the other data servers first.
+	downUntil time.Time
+	// legacyUntil is set when the data server doesn't support the RPC.
+	legacyUntil time.Time
+}
+
+// endpoints orders the remote data servers to prefer the freshest one that is up.
+type endpoints struct {
+	logger log.Logger
+
+	mtx     sync.Mutex
+	list    []*endpoint
+	current *endpoint
+}
+
+func newEndpoints(logger log.Logger, urls []string) *endpoints {
+	e := &endpoints{logger: logger}
+	for _, u := range urls {
+		e.list = append(e.list, &endpoint{url: u})
+	}
+	return e
+}
+
+// ordered returns the data servers that are up, the freshest first, followed by the ones that are down.
+func (e *endpoints) ordered(now time.Time) []*endpoint {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	list := append([]*endpoint{}, e.list...)
+	sort.SliceStable(list, func(a, b int) bool {
+		upA, upB := !now.Before(list[a].downUntil), !now.Before(list[b].downUntil)
+		if upA != upB {
+			return upA
+		}
+		return list[a].updated > list[b].updated
+	})
+	return list
+}
+
+func (e *endpoints) failed(ep *endpoint, now time.Time) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	ep.downUntil = now.Add(endpointDownTime)
+}
+
+// isLegacy returns whether the data server is sent legacy requests because it doesn't support the RPC.
+func (e *endpoints) isLegacy(ep *endpoint, now time.Time) bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return now.Before(ep.legacyUntil)
+}
+
+// setLegacy sends legacy requests to the data server until the RPC is tried again.
+func (e *endpoints) setLegacy(ep *endpoint, now time.Time) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	ep.legacyUntil = now.Add(legacyRetryInterval)
+}
+
+// succeeded records a response and the time of the last write of the data server when known.
+func (e *endpoints) succeeded(ep *endpoint, updated int64) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	ep.downUntil = time.Time{}
+	if updated > 0 {
+		ep.updated = updated
+	}
+	if e.current != ep {
+		if e.current != nil {
+			level.Warn(e.logger).Log("msg", "failed over to another data server", "from", e.current.url, "to", ep.url)
+		}
+		e.current = ep
+	}
+}
+
+// errNoEndpoints is returned when no remote data server is configured.
+var errNoEndpoints = errors.New("no remote DB configured")
+
+// errLegacyUnsupported is returned when the request isn't supported by the data servers using the legacy protocol.
+var errLegacyUnsupported = errors.New("the request isn't supported by the data server, upgrade the data server")
+
+// probed records a healthy data server.
+func (e *endpoints) probed(ep *endpoint, updated int64) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	ep.downUntil = time.Time{}
+	ep.updated = updated
+}
+
+// send posts the RPC request to the freshest data server that is up
+// and fails over to the other ones until the timeout.
+// The data servers that don't support the RPC are sent the legacy request instead,
+// it is nil when the request isn't supported by the legacy protocol.
+// It returns the response and whether it is a legacy response.
+func (i *remoteImpl) send(body []byte, legacyRequest func() ([]byte, error)) ([]byte, *endpoint, bool, error) {
+	if len(i.endpoints.list) == 0 {
+		return nil, nil, false, errNoEndpoints
+	}
+	var legacyBody []byte
+	deadline := time.Now().Add(remoteTimeout)
+	for {
+		var err error
+		// unsupported stays true when no data server can handle the request.
+		unsupported := true
+		for _, ep := range i.endpoints.ordered(time.Now()) {
+			var data []byte
+			if !i.endpoints.isLegacy(ep, time.Now()) {
+				data, err = i.post(ep.url+RPCPath, body)
+				if err == nil {
+					return data, ep, false, nil
+				}
+				if err == errRPCNotFound {
+					level.Warn(i.logger).Log("msg", "the data server doesn't support the RPC, falling back to the legacy protocol, upgrade the data server", "url", ep.url)
+					i.endpoints.setLegacy(ep, time.Now())
+				}
+			}
+			if i.endpoints.isLegacy(ep, time.Now()) {
+				if legacyRequest == nil {
+					err = errLegacyUnsupported
+					continue
+				}
+				if legacyBody == nil {
+					if legacyBody, err = legacyRequest(); err != nil {
+						return nil, nil, false, err
+					}
+				}
+				data, err = i.post(ep.url+"/", legacyBody)
+				if err == nil {
+					return data, ep, true, nil
+				}
+			}
+			unsupported = false
+			level.Warn(i.logger).Log("msg", "sending request to the data server", "url", ep.url, "err", err)
+			i.endpoints.failed(ep, time.Now())
+		}
+		if unsupported {
+			return nil, nil, false, err
+		}
+		if time.Now().After(deadline) {
+			return nil, nil, false, errors.Wrap(err, "timeout expired")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (i *remoteImpl) post(url string, body []byte) ([]byte, error) {
+	resp, err := i.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errRPCNotFound
+	case err != nil:
+		return nil, errors.Wrap(err, "reading the response")
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("response status:%v", resp.Status)
+	}
+	return data, nil
+}
+
+// probe checks the health of all data servers until done is closed
+// so that the freshest one is used.
+func (i *remoteImpl) probe(done chan struct{}) {
+	ticker := time.NewTicker(healthProbeInterval)
+	defer ticker.Stop()
+	for {
+		for _, ep := range i.endpoints.ordered(time.Now()) {
+			health, err := i.health(ep.url)
+			switch {
+			case err == errRPCNotFound:
+				// Data servers without the health endpoint are only checked by the requests.
+			case err != nil:
+				level.Warn(i.logger).Log("msg", "checking the data server health", "url", ep.url, "err", err)
+				i.endpoints.failed(ep, time.Now())
+			case !health.Healthy:
+				level.Warn(i.logger).Log("msg", "unhealthy data server", "url", ep.url, "updated", time.Unix(health.Updated, 0))
+				i.endpoints.failed(ep, time.Now())
+			default:
+				i.endpoints.probed(ep, health.Updated)
+			}
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *remoteImpl) health(url string) (*Health, error) {
+	resp, err := i.httpClient.Get(url + HealthPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, errRPCNotFound
+	case http.StatusOK, http.StatusServiceUnavailable:
+	default:
+		return nil, errors.Errorf("response status:%v", resp.Status)
+	}
+	health := &Health{}
+	if err := json.NewDecoder(resp.Body).Decode(health); err != nil {
+		return nil, errors.Wrap(err, "decoding the health")
+	}
+	return health, nil
+}
+
+// Health returns the health of the local data server from the last write to its DB.
+func (i *remoteImpl) Health() *Health {
+	notifier, ok := i.localDB.(*Notifier)
+	if !ok {
+		return &Health{}
+	}
+	updated := notifier.LastUpdate()
+	if updated.IsZero() {
+		return &Health{}
+	}
+	return &Health{
+		Healthy: time.Since(updated) <= i.healthMaxAge,
+		Updated: updated.Unix(),
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestEndpointsOrder(t *testing.T) {
+	e := newEndpoints(logging.NewLogger(), []string{"a", "b", "c"})
+	now := time.Now()
+	urls := func() string {
+		var urls []string
+		for _, ep := range e.ordered(now) {
+			urls = append(urls, ep.url)
+		}
+		return strings.Join(urls, ",")
+	}
+	testutil.Equals(t, "a,b,c", urls())
+
+	// The freshest data server first.
+	e.succeeded(e.list[2], 200)
+	e.succeeded(e.list[1], 100)
+	testutil.Equals(t, "c,b,a", urls())
+
+	// Then the ones that are down.
+	e.failed(e.list[2], now)
+	testutil.Equals(t, "b,a,c", urls())
+	now = now.Add(endpointDownTime)
+	testutil.Equals(t, "c,b,a", urls())
+}
+
+// testDataServer serves the RPC and the health of a data server.
+func testDataServer(t *testing.T, failing *bool) (*Notifier, *httptest.Server) {
+	cfg, _, DB := openTestRPC(t)
+	notifier := NewNotifier(DB)
+	proxy, err := OpenLocal(logging.NewLogger(), cfg, notifier)
+	testutil.Ok(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing != nil && *failing {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path == HealthPath {
+			testutil.Ok(t, json.NewEncoder(w).Encode(proxy.Health()))
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		out, err := proxy.IncomingRPC("", data)
+		testutil.Ok(t, err)
+		_, err = w.Write(out)
+		testutil.Ok(t, err)
+	}))
+	t.Cleanup(srv.Close)
+	return notifier, srv
+}
+
+func TestFailover(t *testing.T) {
+	var failing bool
+	db1, srv1 := testDataServer(t, &failing)
+	db2, srv2 := testDataServer(t, nil)
+	testutil.Ok(t, db1.Put(DifficultyKey, []byte("1")))
+	// The second data server is fresher.
+	time.Sleep(time.Second)
+	testutil.Ok(t, db2.Put(DifficultyKey, []byte("2")))
+
+	cfg, _, _ := openTestRPC(t)
+	cfg.Mine.RemoteDBEndpoints = []string{strings.TrimPrefix(srv1.URL, "http://"), strings.TrimPrefix(srv2.URL, "http://")}
+	// The config is shared by the tests.
+	t.Cleanup(func() { cfg.Mine.RemoteDBEndpoints = nil })
+	c, err := OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+	client := c.(*remoteImpl)
+	defer close(client.done)
+
+	for i := 0; client.endpoints.ordered(time.Now())[0].url != srv2.URL; i++ {
+		testutil.Assert(t, i < 50, "expected the fresher data server to be probed")
+		time.Sleep(100 * time.Millisecond)
+	}
+	v, err := client.Get(DifficultyKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "2", string(v))
+	testutil.Equals(t, srv2.URL, client.endpoints.current.url)
+
+	// Fail over when it goes down.
+	srv2.Close()
+	v, err = client.Get(DifficultyKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "1", string(v))
+	testutil.Equals(t, srv1.URL, client.endpoints.current.url)
+
+	// Unhealthy data servers are tried last.
+	failing = true
+	client.probe(closed())
+	testutil.Assert(t, time.Now().Before(client.endpoints.list[0].downUntil), "expected the failing data server to be down")
+}
+
+func TestFailoverLegacy(t *testing.T) {
+	db1, srv1 := testDataServer(t, nil)
+	testutil.Ok(t, db1.Put(DifficultyKey, []byte("1")))
+
+	// A fresher data server that only supports the legacy protocol.
+	cfg, server, DB := openTestRPC(t)
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
+	var rpcRequests int
+	srv2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case HealthPath:
+			testutil.Ok(t, json.NewEncoder(w).Encode(&Health{Healthy: true, Updated: time.Now().Unix() + 100}))
+		case "/":
+			data, err := ioutil.ReadAll(r.Body)
+			testutil.Ok(t, err)
+			out, err := server.IncomingRequest("", data)
+			testutil.Ok(t, err)
+			_, err = w.Write(out)
+			testutil.Ok(t, err)
+		default:
+			rpcRequests++
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv2.Close()
+
+	cfg.Mine.RemoteDBEndpoints = []string{strings.TrimPrefix(srv1.URL, "http://"), strings.TrimPrefix(srv2.URL, "http://")}
+	t.Cleanup(func() { cfg.Mine.RemoteDBEndpoints = nil })
+	c, err := OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+	client := c.(*remoteImpl)
+	defer close(client.done)
+	for i := 0; client.endpoints.ordered(time.Now())[0].url != srv2.URL; i++ {
+		testutil.Assert(t, i < 50, "expected the fresher data server to be probed")
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Only the data server without the RPC is sent legacy requests.
+	v, err := client.Get(DifficultyKey)
+	testutil.Ok(t, err)
+	r, err := DecodeRecord(v)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "2", string(r.Value))
+	testutil.Assert(t, client.endpoints.isLegacy(client.endpoints.list[1], time.Now()), "expected the legacy protocol for the data server without the RPC")
+	testutil.Assert(t, !client.endpoints.isLegacy(client.endpoints.list[0], time.Now()), "expected the RPC for the other data server")
+
+	// Requests the legacy protocol doesn't support go to the other data server.
+	vals, err := client.Scan(DifficultyKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "1", string(vals[DifficultyKey]))
+
+	// The RPC is tried again later.
+	testutil.Equals(t, 1, rpcRequests)
+	client.endpoints.list[1].legacyUntil = time.Now()
+	_, err = client.Get(DifficultyKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, rpcRequests)
+}
+
+func closed() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}
+
+func TestHealth(t *testing.T) {
+	cfg, _, DB := openTestRPC(t)
+	notifier := NewNotifier(DB)
+	proxy, err := OpenLocal(logging.NewLogger(), cfg, notifier)
+	testutil.Ok(t, err)
+
+	testutil.Assert(t, !proxy.Health().Healthy, "expected a data server without writes to be unhealthy")
+	testutil.Ok(t, notifier.Put(DifficultyKey, []byte("1")))
+	health := proxy.Health()
+	testutil.Assert(t, health.Healthy, "expected a healthy data server")
+	testutil.Assert(t, health.Updated > 0, "expected the time of the last write")
+
+	proxy.(*remoteImpl).healthMaxAge = 0
+	time.Sleep(10 * time.Millisecond)
+	testutil.Assert(t, !proxy.Health().Healthy, "expected a data server without recent writes to be unhealthy")
+}
@@ -3,7 +3,11 @@
 
 package db
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
 
 const (
 	// BalanceKey is the key to store/lookup account balance.
@@ -28,6 +32,12 @@ const (
 
 	TributeBalanceKey = "trib_balance"
 	DisputeStatusKey  = "dispute_status"
+	// DisputeCandidatesKey holds the submitted values found outside of the expected range.
+	DisputeCandidatesKey = "dispute_candidates"
+	// DisputeCheckerBlockKey holds the last block checked by the dispute checker.
+	DisputeCheckerBlockKey = "dispute_checker_block"
+	// DisputesFiledKey holds the disputes begun by the dispute checker.
+	DisputesFiledKey = "disputes_filed"
 
 	// QueryMetadataPrefix is for RequestID's that are stored with this prefix and the id itself
 	// e.g. "qm_2" represents request ID 2.
@@ -38,43 +48,127 @@ const (
 	LastNewValueKey    = "lastnewvalue"
 	LastSubmissionKey  = "last_submission"
 	TimeOutKey         = "time_out"
+
+	// GasUsedPrefix is for the gas used by the transactions of the miner with this prefix plus the slot number.
+	GasUsedPrefix = "PriceTXSlot"
+)
+
+// valueType is the type of the values stored under a key.
+type valueType int
+
+const (
+	// typeBig is a hex encoded integer.
+	typeBig valueType = iota + 1
+	// typeBigBytes is a big endian encoded integer.
+	typeBigBytes
+	// typeUint64 is a hex encoded uint64.
+	typeUint64
+	typeBytes
+	typeBool
+	// typePSR is a PSR value with its confidence.
+	typePSR
+	typeJSON
 )
 
-var knownKeys map[string]bool
-
-func initKeyLook() {
-	knownKeys = map[string]bool{
-		BalanceKey:          true,
-		CurrentChallengeKey: true,
-		RequestIdKey:        true,
-		RequestIdKey0:       true,
-		RequestIdKey1:       true,
-		RequestIdKey2:       true,
-		RequestIdKey3:       true,
-		RequestIdKey4:       true,
-		DifficultyKey:       true,
-		QueryStringKey:      true,
-		GranularityKey:      true,
-		TotalTipKey:         true,
-		MiningStatusKey:     true,
-		GasKey:              true,
-		Top50Key:            true,
-		TributeBalanceKey:   true,
-		DisputeStatusKey:    true,
-		LastNewValueKey:     true,
-		LastSubmissionKey:   true,
-		TimeOutKey:          true,
+// schema is the type of the values of the keys.
+var schema = map[string]valueType{
+	BalanceKey:             typeBig,
+	CurrentChallengeKey:    typeBytes,
+	RequestIdKey:           typeBig,
+	RequestIdKey0:          typeBig,
+	RequestIdKey1:          typeBig,
+	RequestIdKey2:          typeBig,
+	RequestIdKey3:          typeBig,
+	RequestIdKey4:          typeBig,
+	DifficultyKey:          typeBig,
+	QueryStringKey:         typeBytes,
+	GranularityKey:         typeBig,
+	TotalTipKey:            typeBig,
+	MiningStatusKey:        typeBool,
+	GasKey:                 typeBig,
+	Top50Key:               typeBytes,
+	TributeBalanceKey:      typeBig,
+	DisputeStatusKey:       typeBig,
+	DisputeCandidatesKey:   typeJSON,
+	DisputeCheckerBlockKey: typeUint64,
+	DisputesFiledKey:       typeJSON,
+	LastNewValueKey:        typeBig,
+	LastSubmissionKey:      typeBig,
+	TimeOutKey:             typeBig,
+}
+
+// prefixSchema is the type of the values of the keys with a prefix.
+var prefixSchema = map[string]valueType{
+	QueryMetadataPrefix: typeBytes,
+	QueriedValuePrefix:  typePSR,
+	GasUsedPrefix:       typeBigBytes,
+}
+
+// typeOf returns the type of the values of the key,
+// the keys of the miners are prefixed with their address.
+func typeOf(key string) (valueType, bool) {
+	if strings.HasPrefix(key, "0x") {
+		if idx := strings.Index(key, "-"); idx > 0 {
+			key = key[idx+1:]
+		}
 	}
+	if t, ok := schema[key]; ok {
+		return t, true
+	}
+	for prefix, t := range prefixSchema {
+		if strings.HasPrefix(key, prefix) {
+			return t, true
+		}
+	}
+	return 0, false
 }
-func isKnownKey(key string) bool {
-	if knownKeys == nil {
-		initKeyLook()
+
+// remoteKeys are the keys of the data server that can be read by the remote miners
+// besides the ones with the remotePrefixes.
+var remoteKeys = map[string]bool{
+	BalanceKey:          true,
+	CurrentChallengeKey: true,
+	RequestIdKey:        true,
+	RequestIdKey0:       true,
+	RequestIdKey1:       true,
+	RequestIdKey2:       true,
+	RequestIdKey3:       true,
+	RequestIdKey4:       true,
+	DifficultyKey:       true,
+	QueryStringKey:      true,
+	GranularityKey:      true,
+	TotalTipKey:         true,
+	MiningStatusKey:     true,
+	GasKey:              true,
+	Top50Key:            true,
+	TributeBalanceKey:   true,
+	DisputeStatusKey:    true,
+	LastNewValueKey:     true,
+	LastSubmissionKey:   true,
+	TimeOutKey:          true,
+}
+
+var remotePrefixes = []string{QueryMetadataPrefix, QueriedValuePrefix}
+
+// isRemoteKey returns whether the key of the data server can be read by the remote miners.
+func isRemoteKey(key string) bool {
+	if remoteKeys[key] {
+		return true
 	}
-	if !knownKeys[key] {
-		if !strings.HasPrefix(key, QueryMetadataPrefix) &&
-			!strings.HasPrefix(key, QueriedValuePrefix) {
-			return false
+	for _, prefix := range remotePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
 		}
 	}
-	return true
+	return false
+}
+
+func isPSRKey(key string) bool {
+	t, _ := typeOf(key)
+	return t == typePSR
+}
+
+// MinerKey returns the key of a value of the miner address.
+func MinerKey(address common.Address, key string) string {
+	return strings.ToLower(address.Hex()) + "-" + key
 }
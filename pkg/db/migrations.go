@@ -0,0 +1,144 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// DBVersionKey holds the version of the DB layout, the number of migrations applied to it.
+const DBVersionKey = "db_version"
+
+// migration upgrades the DB from the previous version.
+type migration struct {
+	description string
+	migrate     func(logger log.Logger, db DB) error
+}
+
+// migrations are applied in order, the DB version is the number of the applied ones.
+// New migrations are only appended.
+var migrations = []migration{
+	{"keep the values that aren't refreshed at start as records and drop the others", migrateToRecords},
+}
+
+// Migrate upgrades the DB to the latest version after backing it up to a LevelDB next to the file.
+// The DBs of earlier versions, without a version, were deleted on every start.
+func Migrate(d DB, file string) error {
+	b, ok := d.(backend)
+	if !ok {
+		return errors.New("only the DBs returned by Open can be migrated")
+	}
+	logger := b.log()
+	version, err := dbVersion(b)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return errors.Errorf("the DB version:%v is newer than the supported version:%v, upgrade or restore a backup", version, len(migrations))
+	}
+	if version == len(migrations) {
+		return nil
+	}
+
+	empty, err := isEmpty(b)
+	if err != nil {
+		return err
+	}
+	if !empty {
+		path := fmt.Sprintf("%s.v%d-%s.backup", file, version, time.Now().Format("20060102150405"))
+		if err := backup(b, path); err != nil {
+			return errors.Wrap(err, "backing up the DB before the migration")
+		}
+		level.Info(logger).Log("msg", "backed up the DB before the migration", "backup", path)
+	}
+	for ; version < len(migrations); version++ {
+		m := migrations[version]
+		level.Info(logger).Log("msg", "migrating the DB", "version", version+1, "migration", m.description)
+		if err := m.migrate(logger, b); err != nil {
+			return errors.Wrapf(err, "migrating the DB to version:%v", version+1)
+		}
+		if err := b.Put(DBVersionKey, []byte(hexutil.EncodeUint64(uint64(version+1)))); err != nil {
+			return errors.Wrap(err, "storing the DB version")
+		}
+	}
+	return nil
+}
+
+func dbVersion(d DB) (int, error) {
+	data, err := d.Get(DBVersionKey)
+	if err != nil {
+		return 0, errors.Wrap(err, "getting the DB version")
+	}
+	if data == nil {
+		return 0, nil
+	}
+	version, err := hexutil.DecodeUint64(string(data))
+	if err != nil {
+		return 0, errors.Wrap(err, "decoding the DB version")
+	}
+	return int(version), nil
+}
+
+func isEmpty(b DB) (bool, error) {
+	empty := true
+	err := b.Iterate("", func(string, []byte) error {
+		empty = false
+		return ErrStopIteration
+	})
+	return empty, err
+}
+
+// backup copies all the values of the DB to a new LevelDB at the path,
+// whatever the backend of the DB.
+func backup(b DB, path string) error {
+	backup, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return err
+	}
+	lb := new(leveldb.Batch)
+	if err := b.Iterate("", func(key string, value []byte) error {
+		lb.Put([]byte(key), value)
+		return nil
+	}); err != nil {
+		backup.Close()
+		return err
+	}
+	if err := backup.Write(lb, nil); err != nil {
+		backup.Close()
+		return err
+	}
+	return backup.Close()
+}
+
+// migrateToRecords keeps the gas used by the transactions and the dispute checker state,
+// like the deletion of the DB on every start did, and stores them as records.
+// The other values are refreshed by the trackers and the values written before
+// were encoded without their write time.
+func migrateToRecords(logger log.Logger, db DB) error {
+	kept := map[string]bool{DisputeCandidatesKey: true, DisputeCheckerBlockKey: true}
+	b := NewBatch()
+	if err := db.Iterate("", func(key string, value []byte) error {
+		if !kept[key] && !strings.HasPrefix(key, GasUsedPrefix) {
+			b.Delete(key)
+			return nil
+		}
+		if r, err := DecodeRecord(value); err == nil && r.Version > 0 {
+			return nil
+		}
+		b.Put(key, newRecord(value).Encode())
+		return nil
+	}); err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "converted the kept values to records", "changes", b.Len())
+	return db.Batch(b)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestMigrate(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	file := filepath.Join(t.TempDir(), "db")
+	DB, err := Open(logging.NewLogger(), cfg, file)
+	testutil.Ok(t, err)
+	defer DB.Close()
+
+	// The values of a DB without a version.
+	old := map[string][]byte{
+		GasUsedPrefix + "1":    big.NewInt(21000).Bytes(),
+		DisputeCheckerBlockKey: []byte(hexutil.EncodeUint64(100)),
+		DisputeCandidatesKey:   []byte("[]"),
+		CurrentChallengeKey:    {0xab},
+		PSRValueKey(1):         []byte("0x1"),
+	}
+	for k, v := range old {
+		testutil.Ok(t, DB.Put(k, v))
+	}
+	testutil.Ok(t, Migrate(DB, file))
+
+	version, err := dbVersion(DB)
+	testutil.Ok(t, err)
+	testutil.Equals(t, len(migrations), version)
+	for _, k := range []string{CurrentChallengeKey, PSRValueKey(1)} {
+		has, err := DB.Has(k)
+		testutil.Ok(t, err)
+		testutil.Assert(t, !has, "expected the refreshed value %v to be dropped", k)
+	}
+	// The kept values are records.
+	proxy, err := OpenLocal(logging.NewLogger(), cfg, DB)
+	testutil.Ok(t, err)
+	store := NewStore(proxy)
+	block, updated, err := store.GetDisputeCheckerBlock()
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(100), block)
+	testutil.Assert(t, !updated.IsZero(), "expected a record")
+	gas, _, err := store.GetGasUsed(big.NewInt(1))
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(21000), gas)
+
+	// The backup has the values before the migration.
+	backups, err := filepath.Glob(file + ".v0-*.backup")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(backups))
+	backup, err := Open(logging.NewLogger(), cfg, backups[0])
+	testutil.Ok(t, err)
+	for k, v := range old {
+		data, err := backup.Get(k)
+		testutil.Ok(t, err)
+		testutil.Equals(t, v, data)
+	}
+	testutil.Ok(t, backup.Close())
+
+	// Migrating again changes nothing.
+	testutil.Ok(t, Migrate(DB, file))
+	backups, err = filepath.Glob(file + ".v*.backup")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(backups))
+
+	// The DB of a newer version isn't opened.
+	testutil.Ok(t, DB.Put(DBVersionKey, []byte(hexutil.EncodeUint64(uint64(len(migrations)+1)))))
+	testutil.NotOk(t, Migrate(DB, file))
+}
@@ -4,10 +4,11 @@
 package db
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"io"
+	"net/http"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,7 +21,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/logging"
-	"github.com/tellor-io/telliot/pkg/util"
 )
 
 // DataServerProxy interface for local interaction/abstraction/testing.
@@ -37,13 +37,33 @@ type DataServerProxy interface {
 	Put(key string, value []byte) error
 
 	// put multiple keys and values on remote data server.
+	// The values are written atomically.
 	BatchPut(keys []string, values [][]byte) error
 
 	// local call to get several data server values by their keys.
 	BatchGet(keys []string) (map[string][]byte, error)
 
-	// notification that a remote miner has requested data.
-	IncomingRequest(data []byte) ([]byte, error)
+	// get the data server values of all the keys with the prefix.
+	Scan(prefix string) (map[string][]byte, error)
+
+	// notification that a remote miner has requested data using the legacy binary protocol.
+	// The peer is the miner address of the client certificate, empty without one.
+	IncomingRequest(peer string, data []byte) ([]byte, error)
+
+	// notification that a remote miner has sent a JSON-RPC request.
+	// The peer is the miner address of the client certificate, empty without one.
+	IncomingRPC(peer string, data []byte) ([]byte, error)
+
+	// health of the local data server.
+	Health() *Health
+
+	// notification that a remote miner subscribes to the updates of keys.
+	// The peer is the miner address of the client certificate, empty without one.
+	Subscribe(peer string, data []byte) (<-chan *StreamEvent, func(), error)
+
+	// stream the updates of keys from the remote data server.
+	// It returns ErrStreamUnsupported when the keys need to be polled instead.
+	Stream(ctx context.Context, keys []string) (<-chan map[string][]byte, error)
 
 	io.Closer
 }
@@ -51,6 +71,9 @@ type DataServerProxy interface {
 // how long a signed request is good for before reject it. Semi-protection against replays.
 const _validityThreshold = 2 //seconds
 
+// how long to retry a request to the remote data server.
+const remoteTimeout = 10 * time.Second
+
 /***************************************************************************************
 ** NOTE: This component is used to proxy data requests from approved miner processes. Miner
 ** public addresses are whitelisted and a small history of requests is retained to mitigate
@@ -68,11 +91,31 @@ type remoteImpl struct {
 	publicAddress string
 	localDB       DB
 	whitelist     map[string]bool
-	postURL       string
+	scopes        map[string]*minerScope
+	audit         *auditLog
+	endpoints     *endpoints
+	httpClient    *http.Client
 	logger        log.Logger
 	wlHistory     map[string]*lru.ARCCache
 	isRemote      bool
 	rwLock        sync.RWMutex
+
+	// Replay protection of the RPC.
+	clockSkew     time.Duration
+	nonces        *nonceCache
+	legacyEnabled bool
+
+	rpcMtx sync.Mutex
+	rpcID  uint64
+
+	// streamSigner is the data server address expected to sign the stream events,
+	// the updates aren't streamed when empty.
+	streamSigner string
+	// streamIdle is how long the stream can be idle before it is closed.
+	streamIdle time.Duration
+
+	healthMaxAge time.Duration
+	done         chan struct{}
 }
 
 func OpenRemote(logger log.Logger, cfg *config.Config, localDB DB) (DataServerProxy, error) {
@@ -114,38 +157,60 @@ func open(logger log.Logger, cfg *config.Config, localDB DB, isRemote bool) (Dat
 		wlMap[asStr] = true
 	}
 
-	url := "http://" + cfg.Mine.RemoteDBHost + ":" + strconv.Itoa(int(cfg.Mine.RemoteDBPort))
+	scheme := "http://"
+	httpClient := &http.Client{Timeout: requestTimeout}
+	if isRemote && cfg.Mine.RemoteDBTLS.Enabled {
+		tlsConfig, err := clientTLSConfig(cfg.Mine.RemoteDBTLS)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating the remote DB TLS config")
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		scheme = "https://"
+	}
+	var urls []string
+	for _, addr := range cfg.Mine.RemoteDBs() {
+		urls = append(urls, scheme+addr)
+	}
+	logger = log.With(logger, "component", ComponentName)
 	i := &remoteImpl{
 		privateKey:    privateKey,
 		publicAddress: strings.ToLower(fromAddress.Hex()),
 		localDB:       localDB,
-		postURL:       url,
+		endpoints:     newEndpoints(logger, urls),
+		httpClient:    httpClient,
 		whitelist:     wlMap,
+		scopes:        newMinerScopes(wlMap, cfg.DataServer.Miners),
+		audit:         &auditLog{logger: logger, file: cfg.DataServer.AuditLog},
 		wlHistory:     wlLRU,
-		logger:        log.With(logger, "component", ComponentName),
+		logger:        logger,
 		isRemote:      isRemote,
+		clockSkew:     cfg.DataServer.ClockSkew.Duration,
+		nonces:        &nonceCache{seen: make(map[string]time.Time)},
+		legacyEnabled: cfg.DataServer.LegacyProtocol,
+		healthMaxAge:  cfg.DataServer.HealthMaxAge.Duration,
+		streamIdle:    streamIdleTimeout,
+		done:          make(chan struct{}),
+	}
+	if cfg.Mine.RemoteDBSigner != "" {
+		i.streamSigner = strings.ToLower(common.HexToAddress(cfg.Mine.RemoteDBSigner).Hex())
+	} else if isRemote {
+		level.Warn(logger).Log("msg", "the data server updates aren't streamed without its address in RemoteDBSigner, polling the data server instead")
+	}
+	if isRemote && len(urls) > 1 {
+		go i.probe(i.done)
 	}
 	level.Info(i.logger).Log(
 		"msg", "created remote data proxy connector",
-		"host", cfg.Mine.RemoteDBHost,
-		"port", cfg.Mine.RemoteDBPort,
+		"endpoints", strings.Join(urls, ","),
 	)
 	return i, nil
 }
 
-// Check whether an incoming storage request key is prefixed by one of our known miner
-// keys. Otherwise, we have to reject the request as invalid or not coming from this
-// codebase.
-func (i *remoteImpl) hasAddressPrefix(key string) bool {
-	for k := range i.whitelist {
-		if strings.HasPrefix(key, k) {
-			return true
-		}
+// IncomingRequest handles a request of a remote miner using the legacy binary protocol.
+func (i *remoteImpl) IncomingRequest(peer string, data []byte) ([]byte, error) {
+	if !i.legacyEnabled {
+		return errorResponse("the legacy protocol is disabled, upgrade the miner to use " + RPCPath)
 	}
-	return false
-}
-
-func (i *remoteImpl) IncomingRequest(data []byte) ([]byte, error) {
 	req, err := decodeRequest(i.logger, data, i)
 	if err != nil {
 		return errorResponse("decoding incoming request")
@@ -159,60 +224,26 @@ func (i *remoteImpl) IncomingRequest(data []byte) ([]byte, error) {
 		return errorResponse("No keys found in request!")
 	}
 
-	if i.localDB == nil {
-		return errorResponse("Missing localDB instance!")
+	method := MethodGet
+	if len(req.dbValues) > 0 {
+		method = MethodPut
 	}
-
-	if req.dbValues != nil && len(req.dbValues) > 0 {
-
-		//lock out other threads from reading/writing until the write is done
-		i.rwLock.Lock()
-
-		//make sure we unlock when we leave so next thread can get in
-		defer i.rwLock.Unlock()
-
-		//if we're writing values to the DB
-		if len(req.dbKeys) != len(req.dbValues) {
-			return errorResponse("Keys and values must have the same array dimensions")
-		}
-
-		//request to write data locally
-		for idx, k := range req.dbKeys {
-			//make sure key is prefixed with address
-			if !i.hasAddressPrefix(k) {
-				return errorResponse("All remote data storage request keys must be prefixed with miner public Ethereum address")
-			}
-			v := req.dbValues[idx]
-			if err := i.localDB.Put(k, v); err != nil {
-				return errorResponse(err.Error())
-			}
-		}
-
-	} else {
-		//we're not writing, so we just need a read lock
-		i.rwLock.RLock()
-		defer i.rwLock.RUnlock()
+	// With client certificates the signer must be the miner of the certificate.
+	if peer != "" && !strings.EqualFold(peer, req.signer) {
+		rpcErr := i.audit.reject(req.signer, "legacy", req.dbKeys, rpcErrorf(CodeUnauthorized, "the signer:%v doesn't match the client certificate:%v", req.signer, peer))
+		return errorResponse(rpcErr.Message)
 	}
-
-	level.Info(i.logger).Log("msg", "getting remote request for keys", req.dbKeys)
-
-	outMap := map[string][]byte{}
-	for _, k := range req.dbKeys {
-		if req.dbValues == nil && !isKnownKey(k) {
-			return errorResponse("Invalid lookup key: " + k)
-		}
-		level.Debug(i.logger).Log("looking up for local DB key", "key", k)
-		bts, err := i.localDB.Get(k)
-
-		if err != nil {
-			return errorResponse(err.Error())
-		}
-		if bts != nil {
-			level.Debug(i.logger).Log("msg", "get bytes of result", "bytes", len(bts))
-			outMap[k] = bts
-		}
+	if rpcErr := i.allow(req.signer, method, req.dbKeys); rpcErr != nil {
+		return errorResponse(rpcErr.Message)
+	}
+	outMap, rpcErr := i.serve(req.signer, req.dbKeys, req.dbValues)
+	if rpcErr != nil {
+		return errorResponse(rpcErr.Message)
+	}
+	// The miners using the legacy protocol don't decode the records.
+	for k, v := range outMap {
+		outMap[k] = legacyValue(k, v)
 	}
-
 	resp := &responsePayload{dbVals: outMap, errorMsg: ""}
 	return encodeResponse(resp)
 }
@@ -255,43 +286,19 @@ func (i *remoteImpl) BatchGet(keys []string) (map[string][]byte, error) {
 		)
 		return outMap, nil
 	}
-	req, err := createRequest(i.logger, keys, nil, i)
-	if err != nil {
-		return nil, err
-	}
-	data, err := encodeRequest(i.logger, req)
-	if err != nil {
-		return nil, err
-	}
-	httpReq := &util.HTTPFetchRequest{Method: util.POST, QueryURL: i.postURL, Payload: data, Timeout: time.Duration(10 * time.Second)}
-
-	respData, err := util.HTTPWithRetries(i.logger, httpReq)
-	if err != nil {
-		return nil, errors.Wrapf(err, "retrieving data after retries")
-	}
-	remResp, err := decodeResponse(respData)
-	if err != nil {
-		return nil, err
-	}
-	if len(remResp.errorMsg) > 0 {
-		return nil, errors.New(remResp.errorMsg)
-	}
-	return remResp.dbVals, nil
+	return i.call(MethodGet, keys, nil)
 }
 
 func (i *remoteImpl) BatchPut(keys []string, values [][]byte) error {
 	if !i.isRemote {
-		if len(values) > 0 && len(keys) != len(values) {
+		if len(keys) != len(values) {
 			return errors.Errorf("keys and values must have same array dimensions")
 		}
+		b := NewBatch()
 		for idx, k := range keys {
-			err := i.localDB.Put(k, values[idx])
-			if err != nil {
-				return err
-			}
+			b.Put(k, values[idx])
 		}
-
-		return nil
+		return i.localDB.Batch(b)
 	}
 
 	//must prefix all keys with public address
@@ -303,43 +310,68 @@ func (i *remoteImpl) BatchPut(keys []string, values [][]byte) error {
 			dbKeys[idx] = k
 		}
 	}
-	req, err := createRequest(i.logger, dbKeys, values, i)
-	if err != nil {
-		return err
+	_, err := i.call(MethodPut, dbKeys, values)
+	return err
+}
+
+// Scan returns the values of all the keys with the prefix.
+// Remote miners only receive the keys they can read.
+func (i *remoteImpl) Scan(prefix string) (map[string][]byte, error) {
+	if !i.isRemote {
+		outMap := map[string][]byte{}
+		if err := i.localDB.Iterate(prefix, func(key string, value []byte) error {
+			outMap[key] = value
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return outMap, nil
 	}
-	data, err := encodeRequest(i.logger, req)
-	if err != nil {
-		return err
+	return i.call(MethodScan, []string{prefix}, nil)
+}
+
+// legacyRequest creates a request of the legacy binary protocol.
+// The values are sent without the records like the values received with legacyResponse.
+func (i *remoteImpl) legacyRequest(keys []string, values [][]byte) ([]byte, error) {
+	if len(values) > 0 && len(values) != len(keys) {
+		return nil, errors.Errorf("keys and values must have same array dimensions")
 	}
-	httpReq := &util.HTTPFetchRequest{
-		Method:   util.POST,
-		QueryURL: i.postURL,
-		Payload:  data,
-		Timeout:  time.Duration(10 * time.Second),
+	var raw [][]byte
+	for idx, v := range values {
+		raw = append(raw, legacyValue(keys[idx], v))
 	}
-	respData, err := util.HTTPWithRetries(i.logger, httpReq)
+	req, err := createRequest(i.logger, keys, raw, i)
 	if err != nil {
-		//return nil, err
-		return errors.Wrap(err, "put data after retries")
+		return nil, err
 	}
+	return encodeRequest(i.logger, req)
+}
+
+// legacyResponse decodes a response of the legacy binary protocol.
+func (i *remoteImpl) legacyResponse(ep *endpoint, respData []byte) (map[string][]byte, error) {
 	remResp, err := decodeResponse(respData)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	i.endpoints.succeeded(ep, 0)
 	if len(remResp.errorMsg) > 0 {
-		return errors.New(remResp.errorMsg)
+		return nil, errors.New(remResp.errorMsg)
+	}
+	out := make(map[string][]byte, len(remResp.dbVals))
+	for k, v := range remResp.dbVals {
+		out[k] = legacyRecord(v)
 	}
-	return nil
+	return out, nil
 }
 
 func (i *remoteImpl) Sign(hash []byte) ([]byte, error) {
 	return crypto.Sign(hash, i.privateKey)
 }
 
-func (i *remoteImpl) Verify(hash []byte, timestamp int64, sig []byte) error {
+func (i *remoteImpl) Verify(hash []byte, timestamp int64, sig []byte) (string, error) {
 	pubKey, err := crypto.SigToPub(hash, sig)
 	if err != nil {
-		return err
+		return "", err
 	}
 	addr := crypto.PubkeyToAddress(*pubKey)
 	ashex := strings.ToLower(addr.Hex())
@@ -349,13 +381,13 @@ func (i *remoteImpl) Verify(hash []byte, timestamp int64, sig []byte) error {
 		"whitlisted", i.whitelist[ashex],
 	)
 	if !i.whitelist[ashex] {
-		level.Warn(i.logger).Log("msg", "unauthorized miner detected", "address", ashex)
-		return errors.Errorf("Unauthorized")
+		i.audit.reject(ashex, "legacy", nil, rpcErrorf(CodeUnauthorized, "unauthorized address:%v", ashex))
+		return "", errors.Errorf("Unauthorized")
 	}
 
 	cache := i.wlHistory[ashex]
 	if cache == nil {
-		return errors.Errorf("No history found for address")
+		return "", errors.Errorf("No history found for address")
 	}
 	if cache.Contains(timestamp) {
 		level.Debug(i.logger).Log(
@@ -371,7 +403,8 @@ func (i *remoteImpl) Verify(hash []byte, timestamp int64, sig []byte) error {
 				"timestamp", time.Unix(timestamp, 0),
 				"now", now,
 			)
-			return errors.Errorf("Request expired")
+			i.audit.reject(ashex, "legacy", nil, rpcErrorf(CodeExpired, "request expired"))
+			return "", errors.Errorf("Request expired")
 		}
 		level.Debug(i.logger).Log(
 			"msg", "time of last request",
@@ -387,9 +420,10 @@ func (i *remoteImpl) Verify(hash []byte, timestamp int64, sig []byte) error {
 		)
 	}
 	cache.Add(timestamp, true)
-	return nil
+	return ashex, nil
 }
 
 func (l *remoteImpl) Close() error {
+	close(l.done)
 	return l.localDB.Close()
 }
@@ -0,0 +1,95 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaVersion is the version of the encoding of the values in the records.
+const SchemaVersion = 1
+
+// recordMarker starts every record to tell it apart from the raw values written before the records.
+const recordMarker = 0xfe
+
+// recordHeader is the size of the marker, the version and the write time.
+const recordHeader = 10
+
+// Record is a value stored with the time it was written and the version of its encoding.
+// The records are stored and sent to the remote miners as they are
+// so that the miners can judge the freshness of the values.
+type Record struct {
+	Version uint8
+	// Updated is the write time, zero for the values written before the records.
+	Updated time.Time
+	Value   []byte
+}
+
+func newRecord(value []byte) *Record {
+	return &Record{Version: SchemaVersion, Updated: time.Now(), Value: value}
+}
+
+// Encode returns the record as it is stored.
+func (r *Record) Encode() []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(recordMarker)
+	buf.WriteByte(r.Version)
+	// Writing to a buffer doesn't fail.
+	_ = encode(buf, r.Updated.Unix())
+	buf.Write(r.Value)
+	return buf.Bytes()
+}
+
+// DecodeRecord decodes a stored record.
+// Values written before the records, by older data servers or miners,
+// are returned as records of version 0 without a write time.
+func DecodeRecord(data []byte) (*Record, error) {
+	if len(data) < recordHeader || data[0] != recordMarker {
+		return &Record{Value: data}, nil
+	}
+	r := &Record{Version: data[1], Value: data[recordHeader:]}
+	if r.Version > SchemaVersion {
+		return nil, errors.Errorf("unsupported schema version:%v, upgrade to read it", r.Version)
+	}
+	if r.Version == 0 {
+		return r, nil
+	}
+	var updated int64
+	if err := decode(bytes.NewReader(data[2:recordHeader]), &updated); err != nil {
+		return nil, errors.Wrap(err, "decoding the record time")
+	}
+	r.Updated = time.Unix(updated, 0)
+	return r, nil
+}
+
+// legacyRecord returns a value received from a data server using the legacy protocol,
+// which sends the values without the records, as a record of version 0.
+// This way the raw values can't be mistaken for records when they start like one.
+func legacyRecord(value []byte) []byte {
+	if len(value) == 0 {
+		return value
+	}
+	return (&Record{Value: value}).Encode()
+}
+
+// legacyValue returns a stored value in the encoding used before the records
+// for the miners that don't decode the records yet.
+func legacyValue(key string, data []byte) []byte {
+	r, err := DecodeRecord(data)
+	if err != nil || r.Version == 0 {
+		return data
+	}
+	if isPSRKey(key) {
+		var v psrValue
+		if err := json.Unmarshal(r.Value, &v); err != nil {
+			return data
+		}
+		return []byte(v.Value)
+	}
+	return r.Value
+}
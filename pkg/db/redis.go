@@ -0,0 +1,201 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// redisScanCount is the number of keys requested per SCAN.
+const redisScanCount = 1000
+
+// redisDB is a DB on a redis server so that several data servers can share it.
+// All keys are prefixed with the configured prefix.
+type redisDB struct {
+	pool   *redis.Pool
+	prefix string
+	logger log.Logger
+}
+
+func openRedis(logger log.Logger, cfg config.Redis, password string) (*redisDB, error) {
+	pool := &redis.Pool{
+		MaxIdle:     8,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", cfg.Address,
+				redis.DialDatabase(cfg.Database),
+				redis.DialPassword(password),
+				redis.DialConnectTimeout(10*time.Second),
+				redis.DialReadTimeout(10*time.Second),
+				redis.DialWriteTimeout(10*time.Second),
+			)
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < time.Minute {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+	r := &redisDB{pool: pool, prefix: cfg.Prefix, logger: logger}
+	if err := r.do(func(c redis.Conn) error {
+		_, err := c.Do("PING")
+		return err
+	}); err != nil {
+		pool.Close()
+		return nil, errors.Wrapf(err, "connecting to redis:%v", cfg.Address)
+	}
+	level.Info(r.logger).Log("msg", "connected to redis DB", "at", cfg.Address, "database", cfg.Database, "prefix", cfg.Prefix)
+	return r, nil
+}
+
+// do runs fn with a connection of the pool.
+func (r *redisDB) do(fn func(c redis.Conn) error) error {
+	c := r.pool.Get()
+	defer c.Close()
+	return fn(c)
+}
+
+func (r *redisDB) Close() error {
+	level.Info(r.logger).Log("msg", "closing db")
+	return r.pool.Close()
+}
+
+func (r *redisDB) Has(key string) (bool, error) {
+	var has bool
+	err := r.do(func(c redis.Conn) error {
+		var err error
+		has, err = redis.Bool(c.Do("EXISTS", r.prefix+key))
+		return err
+	})
+	return has, err
+}
+
+func (r *redisDB) Put(key string, value []byte) error {
+	level.Debug(r.logger).Log(
+		"msg", "adding DB entry",
+		"key", key,
+		"bytes", len(value),
+	)
+	return r.do(func(c redis.Conn) error {
+		_, err := c.Do("SET", r.prefix+key, value)
+		return err
+	})
+}
+
+func (r *redisDB) Get(key string) ([]byte, error) {
+	var value []byte
+	err := r.do(func(c redis.Conn) error {
+		var err error
+		value, err = redis.Bytes(c.Do("GET", r.prefix+key))
+		return err
+	})
+	if err == redis.ErrNil {
+		level.Debug(r.logger).Log(
+			"msg", "did not find value",
+			"key", key,
+		)
+		return nil, nil
+	}
+	return value, err
+}
+
+func (r *redisDB) Delete(key string) error {
+	level.Debug(r.logger).Log("msg", "deleting key", "key", key)
+	return r.do(func(c redis.Conn) error {
+		_, err := c.Do("DEL", r.prefix+key)
+		return err
+	})
+}
+
+// Iterate scans the keys with the prefix, sorts them and then reads their values.
+// Keys written during the iteration might be missed and deleted keys are skipped.
+func (r *redisDB) Iterate(prefix string, fn func(key string, value []byte) error) error {
+	return r.do(func(c redis.Conn) error {
+		var keys []string
+		cursor := 0
+		for {
+			reply, err := redis.Values(c.Do("SCAN", cursor, "MATCH", escapeRedisPattern(r.prefix+prefix)+"*", "COUNT", redisScanCount))
+			if err != nil {
+				return err
+			}
+			var page []string
+			if _, err := redis.Scan(reply, &cursor, &page); err != nil {
+				return err
+			}
+			keys = append(keys, page...)
+			if cursor == 0 {
+				break
+			}
+		}
+		sort.Strings(keys)
+		// SCAN can return a key more than once.
+		for i, k := range keys {
+			if i > 0 && keys[i-1] == k {
+				continue
+			}
+			value, err := redis.Bytes(c.Do("GET", k))
+			if err == redis.ErrNil {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := fn(strings.TrimPrefix(k, r.prefix), value); err != nil {
+				if err == ErrStopIteration {
+					return nil
+				}
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *redisDB) log() log.Logger {
+	return r.logger
+}
+
+// Batch applies the batch in a MULTI/EXEC transaction.
+func (r *redisDB) Batch(b *Batch) error {
+	return r.do(func(c redis.Conn) error {
+		if err := c.Send("MULTI"); err != nil {
+			return err
+		}
+		for k, v := range b.puts {
+			if err := c.Send("SET", r.prefix+k, v); err != nil {
+				return err
+			}
+		}
+		for k := range b.deletes {
+			if err := c.Send("DEL", r.prefix+k); err != nil {
+				return err
+			}
+		}
+		_, err := c.Do("EXEC")
+		return err
+	})
+}
+
+// escapeRedisPattern escapes the glob characters of a SCAN MATCH pattern.
+func escapeRedisPattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -24,8 +24,9 @@ type RequestSigner interface {
 // RequestValidator validates that a miner's signature is valid, that its address
 // is whitelisted, and minizes chances that the requested hash isn't being replayed.
 type RequestValidator interface {
-	// Verify the given signature was signed by a valid/whitelisted miner address.
-	Verify(hash []byte, timestamp int64, sig []byte) error
+	// Verify the given signature was signed by a valid/whitelisted miner address
+	// and return the address.
+	Verify(hash []byte, timestamp int64, sig []byte) (string, error)
 }
 
 // Request payload is encoded and comes from a remote client (miner) that is
@@ -45,6 +46,9 @@ type requestPayload struct {
 
 	// signature of op, dbKey, dbVal, and timestamp.
 	sig []byte
+
+	// signer is the address recovered from the signature of a decoded request.
+	signer string
 }
 
 // Create an outgoing request for the given keys.
@@ -193,8 +197,9 @@ func decodeRequest(logger log.Logger, data []byte, validator RequestValidator) (
 		return nil, err
 	}
 	hash := crypto.Keccak256(hBuf.Bytes())
-	if err := validator.Verify(hash, time, sig); err != nil {
+	signer, err := validator.Verify(hash, time, sig)
+	if err != nil {
 		return nil, err
 	}
-	return &requestPayload{dbKeys: keys, dbValues: vals, timestamp: time, sig: sig}, nil
+	return &requestPayload{dbKeys: keys, dbValues: vals, timestamp: time, sig: sig, signer: signer}, nil
 }
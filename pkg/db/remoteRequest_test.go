@@ -103,7 +103,7 @@ func TestRequestForData(t *testing.T) {
 	encoded, err := encodeRequest(logger, req)
 
 	testutil.Ok(t, err)
-	data, err := remote.IncomingRequest(encoded)
+	data, err := remote.IncomingRequest("", encoded)
 	testutil.Ok(t, err)
 
 	resp, err := decodeResponse(data)
@@ -146,10 +146,21 @@ func TestRequestPut(t *testing.T) {
 	bts, err := encodeRequest(logger, req)
 	testutil.Ok(t, err)
 
-	_, err = remote.IncomingRequest(bts)
+	// With client certificates the signer must be the miner of the certificate.
+	data, err := remote.IncomingRequest("0x053b09e98ede40997546e8bb812cd838f18bb146", bts)
+	testutil.Ok(t, err)
+	resp, err := decodeResponse(data)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(resp.errorMsg, "client certificate"), "expected the request of another miner to be rejected")
+
+	req, err = createRequest(logger, []string{dbKey}, vals, remote.(*remoteImpl))
+	testutil.Ok(t, err)
+	bts, err = encodeRequest(logger, req)
+	testutil.Ok(t, err)
+	_, err = remote.IncomingRequest(pubKey, bts)
 	testutil.Ok(t, err)
 
-	data, err := DB.Get(dbKey)
+	data, err = DB.Get(dbKey)
 	testutil.Ok(t, err)
 
 	testutil.Assert(t, bytes.Equal(data, vals[0]), "DB bytes did not match expected put request data")
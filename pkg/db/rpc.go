@@ -0,0 +1,409 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// RPCPath is the path of the versioned JSON-RPC 2.0 endpoint of the data server.
+const RPCPath = "/rpc/v1"
+
+// rpcDomain separates the signatures of this protocol version from any other signed data.
+const rpcDomain = "telliot-rpc-v1"
+
+// The methods of the data server RPC.
+const (
+	MethodGet = "db.get"
+	MethodPut = "db.put"
+	// MethodScan gets the values of all the keys with the prefix given as the only key.
+	MethodScan = "db.scan"
+)
+
+// The error codes of the data server RPC.
+// The codes from -32700 to -32600 are the standard JSON-RPC 2.0 codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeUnauthorized is returned when the signer isn't whitelisted.
+	CodeUnauthorized = -32001
+	// CodeReplayed is returned when the nonce was already used.
+	CodeReplayed = -32002
+	// CodeExpired is returned when the timestamp is off by more than the allowed clock skew.
+	CodeExpired = -32003
+	// CodeInvalidKey is returned for keys that can't be read or written.
+	CodeInvalidKey = -32004
+	// CodeRateLimited is returned when the miner exceeds its request rate limit.
+	CodeRateLimited = -32005
+)
+
+// RPCError is an error returned by the data server.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("data server error code:%d msg:%s", e.Code, e.Message)
+}
+
+func rpcErrorf(code int, format string, args ...interface{}) *RPCError {
+	return &RPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+type rpcRequest struct {
+	JSONRPC string     `json:"jsonrpc"`
+	ID      uint64     `json:"id"`
+	Method  string     `json:"method"`
+	Params  *rpcParams `json:"params"`
+}
+
+type rpcParams struct {
+	Keys   []string        `json:"keys"`
+	Values []hexutil.Bytes `json:"values,omitempty"`
+	// Timestamp is the unix time when the request was signed.
+	Timestamp int64 `json:"timestamp"`
+	// Nonce is a random value that can be used only once by a miner.
+	Nonce string `json:"nonce"`
+	// Signature of the method, the nonce, the timestamp, the keys and the values.
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+type rpcResponse struct {
+	JSONRPC string     `json:"jsonrpc"`
+	ID      uint64     `json:"id"`
+	Result  *rpcResult `json:"result,omitempty"`
+	Error   *RPCError  `json:"error,omitempty"`
+	// Updated is the unix time of the last write to the data server DB.
+	Updated int64 `json:"updated,omitempty"`
+}
+
+type rpcResult struct {
+	Values map[string]hexutil.Bytes `json:"values"`
+}
+
+func (p *rpcParams) values() [][]byte {
+	if len(p.Values) == 0 {
+		return nil
+	}
+	values := make([][]byte, len(p.Values))
+	for i, v := range p.Values {
+		values[i] = v
+	}
+	return values
+}
+
+// hash returns the hash signed by the miner.
+func (p *rpcParams) hash(i *remoteImpl, method string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := encodeString(buf, rpcDomain); err != nil {
+		return nil, err
+	}
+	if err := encodeString(buf, method); err != nil {
+		return nil, err
+	}
+	if err := encodeString(buf, p.Nonce); err != nil {
+		return nil, err
+	}
+	if err := encodeKeysValuesAndTime(i.logger, buf, p.Keys, p.values(), p.Timestamp); err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(buf.Bytes()), nil
+}
+
+// nonceCache keeps the used nonces until their requests expire.
+type nonceCache struct {
+	mtx  sync.Mutex
+	seen map[string]time.Time
+}
+
+// add records the nonce and returns false when it was already used.
+func (c *nonceCache) add(nonce string, expiry, now time.Time) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for n, exp := range c.seen {
+		if now.After(exp) {
+			delete(c.seen, n)
+		}
+	}
+	if _, ok := c.seen[nonce]; ok {
+		return false
+	}
+	c.seen[nonce] = expiry
+	return true
+}
+
+// verifyRPC checks that the request is signed by a whitelisted miner, within the clock skew,
+// not replayed and within the rate limit of the miner, and returns the miner address.
+// With a client certificate the request must be signed by the miner of the certificate.
+// The rejections of a recovered signer are recorded in the audit log.
+func (i *remoteImpl) verifyRPC(peer, method string, p *rpcParams) (string, *RPCError) {
+	hash, err := p.hash(i, method)
+	if err != nil {
+		return "", rpcErrorf(CodeInvalidParams, "encoding the request: %v", err)
+	}
+	pubKey, err := crypto.SigToPub(hash, p.Signature)
+	if err != nil {
+		return "", rpcErrorf(CodeUnauthorized, "invalid signature")
+	}
+	addr := strings.ToLower(crypto.PubkeyToAddress(*pubKey).Hex())
+	if !i.whitelist[addr] {
+		return "", i.audit.reject(addr, method, p.Keys, rpcErrorf(CodeUnauthorized, "unauthorized address:%v", addr))
+	}
+	if peer != "" && !strings.EqualFold(peer, addr) {
+		return "", i.audit.reject(addr, method, p.Keys, rpcErrorf(CodeUnauthorized, "the signer:%v doesn't match the client certificate:%v", addr, peer))
+	}
+
+	now := time.Now()
+	signed := time.Unix(p.Timestamp, 0)
+	if signed.Before(now.Add(-i.clockSkew)) || signed.After(now.Add(i.clockSkew)) {
+		return "", i.audit.reject(addr, method, p.Keys, rpcErrorf(CodeExpired, "request time %v is more than %v off the server time %v", signed.UTC(), i.clockSkew, now.UTC()))
+	}
+	if p.Nonce == "" {
+		return "", rpcErrorf(CodeInvalidParams, "missing nonce")
+	}
+	if !i.nonces.add(addr+"-"+p.Nonce, signed.Add(i.clockSkew), now) {
+		return "", i.audit.reject(addr, method, p.Keys, rpcErrorf(CodeReplayed, "nonce already used:%v", p.Nonce))
+	}
+	if err := i.allow(addr, method, p.Keys); err != nil {
+		return "", err
+	}
+	return addr, nil
+}
+
+// allow checks the rate limit of the miner.
+func (i *remoteImpl) allow(signer, method string, keys []string) *RPCError {
+	scope, ok := i.scopes[signer]
+	if !ok {
+		return i.audit.reject(signer, method, keys, rpcErrorf(CodeUnauthorized, "unauthorized address:%v", signer))
+	}
+	if !scope.allow() {
+		return i.audit.reject(signer, method, keys, rpcErrorf(CodeRateLimited, "too many requests from address:%v", signer))
+	}
+	return nil
+}
+
+// IncomingRPC handles a JSON-RPC request of a remote miner.
+// Errors are returned to the miner in the response.
+func (i *remoteImpl) IncomingRPC(peer string, data []byte) ([]byte, error) {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return json.Marshal(&rpcResponse{JSONRPC: "2.0", Error: rpcErrorf(CodeParseError, "parsing the request: %v", err)})
+	}
+	resp := &rpcResponse{JSONRPC: "2.0", ID: req.ID, Updated: i.Health().Updated}
+	vals, rpcErr := i.handleRPC(peer, &req)
+	if rpcErr != nil {
+		resp.Error = rpcErr
+	} else {
+		resp.Result = &rpcResult{Values: make(map[string]hexutil.Bytes, len(vals))}
+		for k, v := range vals {
+			resp.Result.Values[k] = v
+		}
+	}
+	return json.Marshal(resp)
+}
+
+func (i *remoteImpl) handleRPC(peer string, req *rpcRequest) (map[string][]byte, *RPCError) {
+	if req.JSONRPC != "2.0" {
+		return nil, rpcErrorf(CodeInvalidRequest, "unsupported jsonrpc version:%v", req.JSONRPC)
+	}
+	if req.Method != MethodGet && req.Method != MethodPut && req.Method != MethodScan {
+		return nil, rpcErrorf(CodeMethodNotFound, "unknown method:%v", req.Method)
+	}
+	if req.Params == nil || len(req.Params.Keys) == 0 {
+		return nil, rpcErrorf(CodeInvalidParams, "no keys in the request")
+	}
+	if req.Method != MethodPut && len(req.Params.Values) > 0 {
+		return nil, rpcErrorf(CodeInvalidParams, "%v doesn't accept values", req.Method)
+	}
+	if req.Method == MethodScan && len(req.Params.Keys) != 1 {
+		return nil, rpcErrorf(CodeInvalidParams, "%v accepts a single prefix", MethodScan)
+	}
+	if req.Method == MethodPut && len(req.Params.Values) != len(req.Params.Keys) {
+		return nil, rpcErrorf(CodeInvalidParams, "keys and values must have the same array dimensions")
+	}
+	signer, err := i.verifyRPC(peer, req.Method, req.Params)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == MethodScan {
+		return i.scan(signer, req.Params.Keys[0])
+	}
+	return i.serve(signer, req.Params.Keys, req.Params.values())
+}
+
+// scan reads the values of the keys with the prefix that the signer can read.
+func (i *remoteImpl) scan(signer, prefix string) (map[string][]byte, *RPCError) {
+	if i.localDB == nil {
+		return nil, rpcErrorf(CodeInternalError, "missing localDB instance")
+	}
+	i.rwLock.RLock()
+	defer i.rwLock.RUnlock()
+
+	scope, ok := i.scopes[signer]
+	if !ok {
+		return nil, i.audit.reject(signer, MethodScan, []string{prefix}, rpcErrorf(CodeUnauthorized, "unauthorized address:%v", signer))
+	}
+	level.Info(i.logger).Log("msg", "scanning remote request prefix", "prefix", prefix, "address", signer)
+	outMap := map[string][]byte{}
+	if err := i.localDB.Iterate(prefix, func(key string, value []byte) error {
+		if scope.canRead(key) {
+			outMap[key] = value
+		}
+		return nil
+	}); err != nil {
+		return nil, rpcErrorf(CodeInternalError, err.Error())
+	}
+	return outMap, nil
+}
+
+// serve writes the values when given and reads the keys from the local DB
+// after checking that the signer can write or read all the keys.
+func (i *remoteImpl) serve(signer string, keys []string, values [][]byte) (map[string][]byte, *RPCError) {
+	if i.localDB == nil {
+		return nil, rpcErrorf(CodeInternalError, "missing localDB instance")
+	}
+	method := MethodGet
+	if len(values) > 0 {
+		method = MethodPut
+	}
+	scope, ok := i.scopes[signer]
+	if !ok {
+		return nil, i.audit.reject(signer, method, keys, rpcErrorf(CodeUnauthorized, "unauthorized address:%v", signer))
+	}
+	if len(values) > 0 {
+		// Lock out other threads from reading/writing until the write is done.
+		i.rwLock.Lock()
+		defer i.rwLock.Unlock()
+
+		if len(keys) != len(values) {
+			return nil, rpcErrorf(CodeInvalidParams, "keys and values must have the same array dimensions")
+		}
+		// All the values are written or none.
+		b := NewBatch()
+		for idx, k := range keys {
+			// Make sure the key is prefixed with the address of the signer.
+			if !scope.canWrite(k) {
+				return nil, i.audit.reject(signer, method, keys, rpcErrorf(CodeInvalidKey, "key:%v isn't in the write scope, all remote data storage request keys must be prefixed with the miner public Ethereum address", k))
+			}
+			b.Put(k, values[idx])
+		}
+		if err := i.localDB.Batch(b); err != nil {
+			return nil, rpcErrorf(CodeInternalError, err.Error())
+		}
+	} else {
+		// Not writing so only a read lock is needed.
+		i.rwLock.RLock()
+		defer i.rwLock.RUnlock()
+	}
+
+	level.Info(i.logger).Log("msg", "getting remote request for keys", "keys", strings.Join(keys, ","))
+
+	outMap := map[string][]byte{}
+	for _, k := range keys {
+		if len(values) == 0 && !scope.canRead(k) {
+			return nil, i.audit.reject(signer, method, keys, rpcErrorf(CodeInvalidKey, "invalid lookup key: %v", k))
+		}
+		level.Debug(i.logger).Log("msg", "looking up for local DB key", "key", k)
+		bts, err := i.localDB.Get(k)
+		if err != nil {
+			return nil, rpcErrorf(CodeInternalError, err.Error())
+		}
+		if bts != nil {
+			outMap[k] = bts
+		}
+	}
+	return outMap, nil
+}
+
+// errRPCNotFound is returned by data servers that don't support the RPC.
+var errRPCNotFound = errors.New("the data server doesn't support the RPC")
+
+// call sends a signed RPC request to the data server.
+// It falls back to the legacy protocol for the data servers that don't support the RPC yet.
+func (i *remoteImpl) call(method string, keys []string, values [][]byte) (map[string][]byte, error) {
+	body, id, err := i.newRequest(method, keys, values)
+	if err != nil {
+		return nil, err
+	}
+	var legacyRequest func() ([]byte, error)
+	if method != MethodScan {
+		legacyRequest = func() ([]byte, error) {
+			return i.legacyRequest(keys, values)
+		}
+	}
+
+	data, ep, legacy, err := i.send(body, legacyRequest)
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		return i.legacyResponse(ep, data)
+	}
+	var resp rpcResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, errors.Wrap(err, "decoding the response")
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	if resp.ID != id {
+		return nil, errors.Errorf("response id:%v doesn't match the request id:%v", resp.ID, id)
+	}
+	i.endpoints.succeeded(ep, resp.Updated)
+	out := make(map[string][]byte)
+	if resp.Result != nil {
+		for k, v := range resp.Result.Values {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// newRequest creates a signed RPC request.
+func (i *remoteImpl) newRequest(method string, keys []string, values [][]byte) ([]byte, uint64, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, 0, errors.Wrap(err, "generating nonce")
+	}
+	params := &rpcParams{
+		Keys:      keys,
+		Timestamp: time.Now().Unix(),
+		Nonce:     hex.EncodeToString(nonce),
+	}
+	for _, v := range values {
+		params.Values = append(params.Values, v)
+	}
+	hash, err := params.hash(i, method)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "encoding the request")
+	}
+	if params.Signature, err = i.Sign(hash); err != nil {
+		return nil, 0, errors.Wrap(err, "signing the request")
+	}
+	i.rpcMtx.Lock()
+	i.rpcID++
+	id := i.rpcID
+	i.rpcMtx.Unlock()
+	body, err := json.Marshal(&rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "encoding the request")
+	}
+	return body, id, nil
+}
@@ -0,0 +1,265 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func openTestRPC(t *testing.T) (*config.Config, *remoteImpl, DB) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	DB, cleanup := OpenTestDB(t)
+	t.Cleanup(cleanup)
+	server, err := OpenLocal(logging.NewLogger(), cfg, DB)
+	testutil.Ok(t, err)
+	return cfg, server.(*remoteImpl), DB
+}
+
+// signedRPC creates a request for the method with a signature over signMethod.
+func signedRPC(t *testing.T, i *remoteImpl, method, signMethod string, keys []string, timestamp int64, nonce string) []byte {
+	params := &rpcParams{Keys: keys, Timestamp: timestamp, Nonce: nonce}
+	hash, err := params.hash(i, signMethod)
+	testutil.Ok(t, err)
+	params.Signature, err = i.Sign(hash)
+	testutil.Ok(t, err)
+	data, err := json.Marshal(&rpcRequest{JSONRPC: "2.0", ID: 7, Method: method, Params: params})
+	testutil.Ok(t, err)
+	return data
+}
+
+func incomingRPC(t *testing.T, i *remoteImpl, data []byte) *rpcResponse {
+	out, err := i.IncomingRPC("", data)
+	testutil.Ok(t, err)
+	resp := &rpcResponse{}
+	testutil.Ok(t, json.Unmarshal(out, resp))
+	return resp
+}
+
+func TestIncomingRPC(t *testing.T) {
+	_, server, DB := openTestRPC(t)
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
+	now := time.Now().Unix()
+
+	resp := incomingRPC(t, server, signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now, "1"))
+	testutil.Assert(t, resp.Error == nil, "unexpected error:%v", resp.Error)
+	testutil.Equals(t, uint64(7), resp.ID)
+	testutil.Equals(t, "2", string(resp.Result.Values[DifficultyKey]))
+
+	for name, tc := range map[string]struct {
+		data []byte
+		code int
+	}{
+		"replayed nonce":     {signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now, "1"), CodeReplayed},
+		"outside clock skew": {signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now-120, "2"), CodeExpired},
+		"in the future":      {signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now+120, "3"), CodeExpired},
+		"method not signed":  {signedRPC(t, server, MethodGet, "db.other", []string{DifficultyKey}, now, "4"), CodeUnauthorized},
+		"unknown method":     {signedRPC(t, server, "db.delete", "db.delete", []string{DifficultyKey}, now, "5"), CodeMethodNotFound},
+		"unknown key":        {signedRPC(t, server, MethodGet, MethodGet, []string{"unknown"}, now, "6"), CodeInvalidKey},
+		"missing nonce":      {signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now, ""), CodeInvalidParams},
+		"put without values": {signedRPC(t, server, MethodPut, MethodPut, []string{DifficultyKey}, now, "7"), CodeInvalidParams},
+		"scan of 2 prefixes": {signedRPC(t, server, MethodScan, MethodScan, []string{QueriedValuePrefix, QueryMetadataPrefix}, now, "10"), CodeInvalidParams},
+		"parse error":        {[]byte("{"), CodeParseError},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := incomingRPC(t, server, tc.data)
+			testutil.Assert(t, resp.Error != nil, "expected an error")
+			testutil.Equals(t, tc.code, resp.Error.Code)
+		})
+	}
+
+	// The signer must be the miner of the client certificate.
+	out, err := server.IncomingRPC("0x053b09e98ede40997546e8bb812cd838f18bb146", signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now, "9"))
+	testutil.Ok(t, err)
+	resp = &rpcResponse{}
+	testutil.Ok(t, json.Unmarshal(out, resp))
+	testutil.Equals(t, CodeUnauthorized, resp.Error.Code)
+
+	// A miner that isn't whitelisted.
+	server.whitelist = map[string]bool{}
+	resp = incomingRPC(t, server, signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now, "8"))
+	testutil.Equals(t, CodeUnauthorized, resp.Error.Code)
+}
+
+func setRemote(t *testing.T, cfg *config.Config, srvURL string) {
+	u, err := url.Parse(srvURL)
+	testutil.Ok(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(port)
+	testutil.Ok(t, err)
+	cfg.Mine.RemoteDBHost = host
+	cfg.Mine.RemoteDBPort = uint(p)
+}
+
+func TestRPCRoundTrip(t *testing.T) {
+	cfg, server, DB := openTestRPC(t)
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equals(t, RPCPath, r.URL.Path)
+		data, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		out, err := server.IncomingRPC("", data)
+		testutil.Ok(t, err)
+		_, err = w.Write(out)
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	setRemote(t, cfg, srv.URL)
+	client, err := OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+
+	vals, err := client.BatchGet([]string{DifficultyKey})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "2", string(vals[DifficultyKey]))
+
+	testutil.Ok(t, client.Put(CurrentChallengeKey, []byte("TEST_CHALLENGE")))
+	key := strings.ToLower(common.HexToAddress(cfg.PublicAddress).Hex()) + "-" + CurrentChallengeKey
+	v, err := DB.Get(key)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "TEST_CHALLENGE", string(v))
+
+	// Miners can read back their own keys.
+	v, err = client.Get(key)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "TEST_CHALLENGE", string(v))
+
+	_, err = client.Get("unknown")
+	testutil.NotOk(t, err)
+	rpcErr, ok := err.(*RPCError)
+	testutil.Assert(t, ok, "expected an rpc error:%v", err)
+	testutil.Equals(t, CodeInvalidKey, rpcErr.Code)
+
+	// The scan only returns the keys the miners can read.
+	testutil.Ok(t, DB.Put(PSRValueKey(1), []byte("1")))
+	testutil.Ok(t, DB.Put(PSRValueKey(2), []byte("2")))
+	testutil.Ok(t, DB.Put("private", []byte("secret")))
+	vals, err = client.Scan(QueriedValuePrefix)
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string][]byte{PSRValueKey(1): []byte("1"), PSRValueKey(2): []byte("2")}, vals)
+	vals, err = client.Scan("")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, len(vals))
+	_, ok = vals["private"]
+	testutil.Assert(t, !ok, "expected the unknown key to be skipped")
+
+	// The values of a put are written all or none.
+	_, rpcErr = server.serve(server.publicAddress, []string{key, "invalid"}, [][]byte{[]byte("NEW_CHALLENGE"), []byte("1")})
+	testutil.Equals(t, CodeInvalidKey, rpcErr.Code)
+	v, err = DB.Get(key)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "TEST_CHALLENGE", string(v))
+}
+
+func TestRPCLegacyFallback(t *testing.T) {
+	cfg, server, DB := openTestRPC(t)
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
+
+	// A data server that only supports the legacy protocol.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		out, err := server.IncomingRequest("", data)
+		testutil.Ok(t, err)
+		_, err = w.Write(out)
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	setRemote(t, cfg, srv.URL)
+	c, err := OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+	client := c.(*remoteImpl)
+
+	// A raw challenge that starts like a record.
+	challenge := append([]byte{recordMarker, SchemaVersion}, bytes.Repeat([]byte{1}, 30)...)
+	testutil.Ok(t, DB.Put(CurrentChallengeKey, newRecord(challenge).Encode()))
+
+	// The legacy values are received as records of version 0.
+	vals, err := client.BatchGet([]string{DifficultyKey, CurrentChallengeKey})
+	testutil.Ok(t, err)
+	for key, expected := range map[string][]byte{DifficultyKey: []byte("2"), CurrentChallengeKey: challenge} {
+		r, err := DecodeRecord(vals[key])
+		testutil.Ok(t, err)
+		testutil.Equals(t, uint8(0), r.Version)
+		testutil.Assert(t, r.Updated.IsZero(), "expected no write time")
+		testutil.Equals(t, expected, r.Value)
+	}
+	testutil.Assert(t, client.endpoints.isLegacy(client.endpoints.list[0], time.Now()), "expected the client to switch to the legacy protocol")
+	_, err = client.Scan(QueriedValuePrefix)
+	testutil.NotOk(t, err)
+
+	// The legacy data servers store the values without the records.
+	testutil.Ok(t, client.Put(GasUsedPrefix+"0", newRecord([]byte{1}).Encode()))
+	v, err := DB.Get(MinerKey(common.HexToAddress(client.publicAddress), GasUsedPrefix+"0"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, []byte{1}, v)
+
+	// Data servers can reject the legacy protocol.
+	server.legacyEnabled = false
+	_, err = client.BatchGet([]string{DifficultyKey})
+	testutil.NotOk(t, err)
+}
+
+func TestRPCTLS(t *testing.T) {
+	cfg, server, DB := openTestRPC(t)
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		out, err := server.IncomingRPC("", data)
+		testutil.Ok(t, err)
+		_, err = w.Write(out)
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	setRemote(t, cfg, srv.URL)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	testutil.Ok(t, ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+	cfg.Mine.RemoteDBTLS = config.TLS{Enabled: true, CAFile: caFile}
+	t.Cleanup(func() { cfg.Mine.RemoteDBTLS = config.TLS{} })
+	client, err := OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.HasPrefix(client.(*remoteImpl).endpoints.list[0].url, "https://"), "expected an https url")
+
+	vals, err := client.BatchGet([]string{DifficultyKey})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "2", string(vals[DifficultyKey]))
+
+	// The commands query the local data server with the same TLS config.
+	cfg.DataServer.TLS.Enabled = true
+	t.Cleanup(func() { cfg.DataServer.TLS.Enabled = false })
+	u, _, err := LocalDataServer(cfg)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.HasPrefix(u, "https://"), "expected an https url")
+
+	cfg.Mine.RemoteDBTLS.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.NotOk(t, err)
+	_, _, err = LocalDataServer(cfg)
+	testutil.NotOk(t, err)
+}
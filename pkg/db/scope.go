@@ -0,0 +1,164 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/tellor-io/telliot/pkg/config"
+	"golang.org/x/time/rate"
+)
+
+// minerScope is what a whitelisted miner can do on the data server.
+type minerScope struct {
+	// address is the lower case hex address of the miner.
+	address string
+	read    []string
+	write   []string
+	limiter *rate.Limiter
+}
+
+func newMinerScope(address string, cfg config.MinerScope) *minerScope {
+	limit := rate.Inf
+	if cfg.RateLimit > 0 {
+		limit = rate.Limit(cfg.RateLimit)
+	}
+	burst := cfg.RateBurst
+	if burst < 1 {
+		burst = 1
+	}
+	return &minerScope{
+		address: address,
+		read:    cfg.Read,
+		write:   cfg.Write,
+		limiter: rate.NewLimiter(limit, burst),
+	}
+}
+
+// newMinerScopes returns the scopes of the whitelisted miners.
+func newMinerScopes(whitelist map[string]bool, cfg map[string]config.MinerScope) map[string]*minerScope {
+	configured := make(map[string]config.MinerScope, len(cfg))
+	for addr, s := range cfg {
+		configured[strings.ToLower(common.HexToAddress(addr).Hex())] = s
+	}
+	scopes := make(map[string]*minerScope, len(whitelist))
+	for addr := range whitelist {
+		scopes[addr] = newMinerScope(addr, configured[addr])
+	}
+	return scopes
+}
+
+// ownKey returns the key without the address prefix of the miner
+// and whether the key is prefixed with it.
+func (s *minerScope) ownKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, s.address) {
+		return "", false
+	}
+	return strings.TrimPrefix(key[len(s.address):], "-"), true
+}
+
+// canRead returns whether the miner can read the key.
+// The keys of other miners can never be read.
+func (s *minerScope) canRead(key string) bool {
+	if _, own := s.ownKey(key); own {
+		return true
+	}
+	if !isRemoteKey(key) {
+		return false
+	}
+	return len(s.read) == 0 || matchesAny(s.read, key)
+}
+
+// canWrite returns whether the miner can write the key.
+// Only the keys prefixed with the address of the miner can be written.
+func (s *minerScope) canWrite(key string) bool {
+	k, own := s.ownKey(key)
+	if !own {
+		return false
+	}
+	return len(s.write) == 0 || matchesAny(s.write, k)
+}
+
+// allow returns whether the request is within the rate limit of the miner.
+func (s *minerScope) allow() bool {
+	return s.limiter.Allow()
+}
+
+// matchesAny returns whether the key matches one of the patterns,
+// a pattern ending with * matches all the keys with this prefix.
+func matchesAny(patterns []string, key string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(key, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if key == p {
+			return true
+		}
+	}
+	return false
+}
+
+// auditEntry is a line of the audit log of the rejected miner requests.
+type auditEntry struct {
+	Time    time.Time `json:"time"`
+	Address string    `json:"address"`
+	Method  string    `json:"method"`
+	Keys    []string  `json:"keys,omitempty"`
+	Code    int       `json:"code"`
+	Reason  string    `json:"reason"`
+}
+
+// auditLog records the rejected miner requests as JSON lines.
+type auditLog struct {
+	logger log.Logger
+	file   string
+	mtx    sync.Mutex
+}
+
+// reject logs the rejection of a request of the signer and appends it to the audit log.
+func (a *auditLog) reject(signer, method string, keys []string, rpcErr *RPCError) *RPCError {
+	level.Warn(a.logger).Log(
+		"msg", "rejected miner request",
+		"address", signer,
+		"method", method,
+		"keys", strings.Join(keys, ","),
+		"reason", rpcErr.Message,
+	)
+	if a.file == "" {
+		return rpcErr
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	line, err := json.Marshal(&auditEntry{
+		Time:    time.Now(),
+		Address: signer,
+		Method:  method,
+		Keys:    keys,
+		Code:    rpcErr.Code,
+		Reason:  rpcErr.Message,
+	})
+	if err != nil {
+		level.Error(a.logger).Log("msg", "encode data server audit entry", "err", err)
+		return rpcErr
+	}
+	f, err := os.OpenFile(a.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		level.Error(a.logger).Log("msg", "open data server audit log", "err", err)
+		return rpcErr
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		level.Error(a.logger).Log("msg", "write data server audit log", "err", err)
+	}
+	return rpcErr
+}
@@ -0,0 +1,138 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+const (
+	testSigner = "0x92f91500e105e3051f3cf94616831b58f6bce1e8"
+	otherMiner = "0x053b09e98ede40997546e8bb812cd838f18bb146"
+)
+
+func TestMinerScope(t *testing.T) {
+	scope := newMinerScope(testSigner, config.MinerScope{})
+	for key, readable := range map[string]bool{
+		DifficultyKey:                          true,
+		PSRValueKey(1):                         true,
+		testSigner + "-" + GasKey:              true,
+		otherMiner + "-" + GasKey:              false,
+		otherMiner + "-" + TimeOutKey:          false,
+		"unknown":                              false,
+		testSigner + "-" + DisputeStatusKey:    true,
+		DisputeCandidatesKey:                   false,
+		DisputeCheckerBlockKey:                 false,
+		DisputesFiledKey:                       false,
+		GasUsedPrefix + "1":                    false,
+		otherMiner + "-" + GasUsedPrefix + "1": false,
+	} {
+		testutil.Equals(t, readable, scope.canRead(key), "read key:%v", key)
+	}
+	testutil.Assert(t, scope.canWrite(testSigner+"-"+CurrentChallengeKey), "expected the own keys to be writable")
+	testutil.Assert(t, !scope.canWrite(otherMiner+"-"+CurrentChallengeKey), "expected the keys of another miner to be read only")
+	testutil.Assert(t, !scope.canWrite(CurrentChallengeKey), "expected the keys of the data server to be read only")
+
+	scope = newMinerScope(testSigner, config.MinerScope{
+		Read:  []string{QueriedValuePrefix + "*", DifficultyKey},
+		Write: []string{GasUsedPrefix + "*"},
+	})
+	testutil.Assert(t, scope.canRead(PSRValueKey(1)), "expected the prefix to be readable")
+	testutil.Assert(t, scope.canRead(DifficultyKey), "expected the key to be readable")
+	testutil.Assert(t, !scope.canRead(TotalTipKey), "expected the key outside of the scope to be rejected")
+	testutil.Assert(t, scope.canRead(testSigner+"-"+GasKey), "expected the own keys to be readable")
+	testutil.Assert(t, scope.canWrite(testSigner+"-"+GasUsedPrefix+"1"), "expected the prefix to be writable")
+	testutil.Assert(t, !scope.canWrite(testSigner+"-"+CurrentChallengeKey), "expected the own key outside of the scope to be rejected")
+}
+
+func TestScopeRejections(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{testSigner, otherMiner}
+	cfg.DataServer.AuditLog = filepath.Join(t.TempDir(), "audit.log")
+	cfg.DataServer.Miners = map[string]config.MinerScope{
+		testSigner: {Read: []string{QueriedValuePrefix + "*"}, RateLimit: 0.001, RateBurst: 3},
+	}
+	t.Cleanup(func() { cfg.DataServer.Miners = nil })
+	DB, cleanup := OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := OpenLocal(logging.NewLogger(), cfg, DB)
+	testutil.Ok(t, err)
+	server := proxy.(*remoteImpl)
+	testutil.Ok(t, DB.Put(PSRValueKey(1), []byte("1")))
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("2")))
+	now := time.Now().Unix()
+
+	resp := incomingRPC(t, server, signedRPC(t, server, MethodGet, MethodGet, []string{PSRValueKey(1)}, now, "1"))
+	testutil.Assert(t, resp.Error == nil, "unexpected error:%v", resp.Error)
+
+	// The key outside of the read scope.
+	resp = incomingRPC(t, server, signedRPC(t, server, MethodGet, MethodGet, []string{DifficultyKey}, now, "2"))
+	testutil.Equals(t, CodeInvalidKey, resp.Error.Code)
+
+	// The scan skips the keys outside of the read scope.
+	resp = incomingRPC(t, server, signedRPC(t, server, MethodScan, MethodScan, []string{""}, now, "3"))
+	testutil.Assert(t, resp.Error == nil, "unexpected error:%v", resp.Error)
+	testutil.Equals(t, 1, len(resp.Result.Values))
+
+	// The request quota is used up.
+	resp = incomingRPC(t, server, signedRPC(t, server, MethodGet, MethodGet, []string{PSRValueKey(1)}, now, "4"))
+	testutil.Equals(t, CodeRateLimited, resp.Error.Code)
+
+	// A miner can only write its own keys.
+	_, rpcErr := server.serve(testSigner, []string{otherMiner + "-" + CurrentChallengeKey}, [][]byte{[]byte("challenge")})
+	testutil.Equals(t, CodeInvalidKey, rpcErr.Code)
+	has, err := DB.Has(otherMiner + "-" + CurrentChallengeKey)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !has, "expected the key of another miner to be rejected")
+
+	// The rejections are audited with the signer.
+	f, err := os.Open(cfg.DataServer.AuditLog)
+	testutil.Ok(t, err)
+	defer f.Close()
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e auditEntry
+		testutil.Ok(t, json.Unmarshal(scanner.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	testutil.Ok(t, scanner.Err())
+	testutil.Equals(t, 3, len(entries))
+	for _, e := range entries {
+		testutil.Equals(t, testSigner, e.Address)
+	}
+	testutil.Equals(t, []int{CodeInvalidKey, CodeRateLimited, CodeInvalidKey}, []int{entries[0].Code, entries[1].Code, entries[2].Code})
+	testutil.Equals(t, MethodPut, entries[2].Method)
+}
+
+func TestInternalKeysRejected(t *testing.T) {
+	_, server, DB := openTestRPC(t)
+	internal := []string{DisputeCandidatesKey, DisputeCheckerBlockKey, DisputesFiledKey, GasUsedPrefix + "1"}
+	for _, key := range internal {
+		testutil.Ok(t, DB.Put(key, []byte("1")))
+	}
+	now := time.Now().Unix()
+
+	for i, key := range internal {
+		resp := incomingRPC(t, server, signedRPC(t, server, MethodGet, MethodGet, []string{key}, now, fmt.Sprint(i)))
+		testutil.Assert(t, resp.Error != nil, "expected the internal key:%v to be rejected", key)
+		testutil.Equals(t, CodeInvalidKey, resp.Error.Code)
+	}
+	resp := incomingRPC(t, server, signedRPC(t, server, MethodScan, MethodScan, []string{""}, now, "scan"))
+	testutil.Assert(t, resp.Error == nil, "unexpected error:%v", resp.Error)
+	for _, key := range internal {
+		_, ok := resp.Result.Values[key]
+		testutil.Assert(t, !ok, "expected the internal key:%v to be skipped", key)
+	}
+}
@@ -0,0 +1,446 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// KV is the access of the Store to the values, implemented by the DataServerProxy.
+type KV interface {
+	BatchGet(keys []string) (map[string][]byte, error)
+	Put(key string, value []byte) error
+	BatchPut(keys []string, values [][]byte) error
+	Scan(prefix string) (map[string][]byte, error)
+}
+
+// Store reads and writes the typed values of the DB.
+// Every value is stored as a record with its write time and the version of its encoding.
+// The getters return nil values and a zero time when the values aren't written yet.
+type Store struct {
+	kv KV
+}
+
+// NewStore returns a store reading and writing through the proxy,
+// the local DB of a data server or the DB of a remote data server.
+func NewStore(kv KV) *Store {
+	return &Store{kv: kv}
+}
+
+// put stores the value as a record after checking that the key holds values of this type.
+func (s *Store) put(key string, t valueType, value []byte) error {
+	if typ, ok := typeOf(key); !ok || typ != t {
+		return errors.Errorf("key:%v doesn't hold values of this type", key)
+	}
+	return errors.Wrapf(s.kv.Put(key, newRecord(value).Encode()), "storing key:%v", key)
+}
+
+// typedValue is a value written by putAll.
+type typedValue struct {
+	key   string
+	t     valueType
+	value []byte
+}
+
+// putAll stores the values as records in a single atomic write.
+func (s *Store) putAll(values ...typedValue) error {
+	keys := make([]string, len(values))
+	data := make([][]byte, len(values))
+	for i, v := range values {
+		if typ, ok := typeOf(v.key); !ok || typ != v.t {
+			return errors.Errorf("key:%v doesn't hold values of this type", v.key)
+		}
+		keys[i] = v.key
+		data[i] = newRecord(v.value).Encode()
+	}
+	return errors.Wrapf(s.kv.BatchPut(keys, data), "storing keys:%v", keys)
+}
+
+func (s *Store) get(keys ...string) (map[string]*Record, error) {
+	m, err := s.kv.BatchGet(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the values")
+	}
+	return decodeRecords(m)
+}
+
+// scan returns the records of all the keys with the prefix.
+func (s *Store) scan(prefix string) (map[string]*Record, error) {
+	m, err := s.kv.Scan(prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "scanning the values")
+	}
+	return decodeRecords(m)
+}
+
+func decodeRecords(m map[string][]byte) (map[string]*Record, error) {
+	records := make(map[string]*Record, len(m))
+	for k, v := range m {
+		if len(v) == 0 {
+			continue
+		}
+		r, err := DecodeRecord(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding key:%v", k)
+		}
+		records[k] = r
+	}
+	return records, nil
+}
+
+func (s *Store) putBig(key string, v *big.Int) error {
+	return s.put(key, typeBig, []byte(hexutil.EncodeBig(v)))
+}
+
+func bigValue(key string, v *big.Int) typedValue {
+	return typedValue{key: key, t: typeBig, value: []byte(hexutil.EncodeBig(v))}
+}
+
+func (s *Store) getBig(key string) (*big.Int, time.Time, error) {
+	records, err := s.get(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	r, ok := records[key]
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	v, err := decodeBig(key, r)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return v, r.Updated, nil
+}
+
+func decodeBig(key string, r *Record) (*big.Int, error) {
+	v, err := hexutil.DecodeBig(string(r.Value))
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding key:%v", key)
+	}
+	return v, nil
+}
+
+// SetBalance stores the ETH balance of the account.
+func (s *Store) SetBalance(balance *big.Int) error {
+	return s.putBig(BalanceKey, balance)
+}
+
+// GetBalance returns the ETH balance of the account.
+func (s *Store) GetBalance() (*big.Int, time.Time, error) {
+	return s.getBig(BalanceKey)
+}
+
+// SetTributeBalance stores the TRB balance of the account.
+func (s *Store) SetTributeBalance(balance *big.Int) error {
+	return s.putBig(TributeBalanceKey, balance)
+}
+
+// GetTributeBalance returns the TRB balance of the account.
+func (s *Store) GetTributeBalance() (*big.Int, time.Time, error) {
+	return s.getBig(TributeBalanceKey)
+}
+
+// SetGasPrice stores the gas price in wei.
+func (s *Store) SetGasPrice(price *big.Int) error {
+	return s.putBig(GasKey, price)
+}
+
+// GetGasPrice returns the gas price in wei.
+func (s *Store) GetGasPrice() (*big.Int, time.Time, error) {
+	return s.getBig(GasKey)
+}
+
+// SetDisputeStatus stores the staker status of the account.
+func (s *Store) SetDisputeStatus(status *big.Int) error {
+	return s.putBig(DisputeStatusKey, status)
+}
+
+// GetDisputeStatus returns the staker status of the account.
+func (s *Store) GetDisputeStatus() (*big.Int, time.Time, error) {
+	return s.getBig(DisputeStatusKey)
+}
+
+// SetMinerDisputeStatus stores the staker status of a miner address.
+func (s *Store) SetMinerDisputeStatus(address common.Address, status *big.Int) error {
+	return s.putBig(MinerKey(address, DisputeStatusKey), status)
+}
+
+// GetMinerDisputeStatus returns the staker status of a miner address.
+func (s *Store) GetMinerDisputeStatus(address common.Address) (*big.Int, time.Time, error) {
+	return s.getBig(MinerKey(address, DisputeStatusKey))
+}
+
+// SetTimeOut stores the time of the last submission of the account, zero when it never submitted.
+func (s *Store) SetTimeOut(last time.Time) error {
+	return s.putBig(TimeOutKey, unixBig(last))
+}
+
+// SetMinerTimeOut stores the time of the last submission of a miner address.
+func (s *Store) SetMinerTimeOut(address common.Address, last time.Time) error {
+	return s.putBig(MinerKey(address, TimeOutKey), unixBig(last))
+}
+
+// GetMinerTimeOut returns the time of the last submission of a miner address, zero when it never submitted.
+func (s *Store) GetMinerTimeOut(address common.Address) (time.Time, time.Time, error) {
+	return s.getTime(MinerKey(address, TimeOutKey))
+}
+
+// SetLastNewValue stores the time of the last mined value.
+func (s *Store) SetLastNewValue(last time.Time) error {
+	return s.putBig(LastNewValueKey, unixBig(last))
+}
+
+// unixBig returns the unix time, 0 for the zero time.
+func unixBig(t time.Time) *big.Int {
+	if t.IsZero() {
+		return big.NewInt(0)
+	}
+	return big.NewInt(t.Unix())
+}
+
+// GetLastNewValue returns the time of the last mined value.
+func (s *Store) GetLastNewValue() (time.Time, time.Time, error) {
+	return s.getTime(LastNewValueKey)
+}
+
+func (s *Store) getTime(key string) (time.Time, time.Time, error) {
+	v, updated, err := s.getBig(key)
+	if err != nil || v == nil || v.Sign() == 0 {
+		return time.Time{}, updated, err
+	}
+	return time.Unix(v.Int64(), 0), updated, nil
+}
+
+// SetMiningStatus stores whether the account already mined the current challenge.
+func (s *Store) SetMiningStatus(mined bool) error {
+	status := []byte{0}
+	if mined {
+		status = []byte{1}
+	}
+	return s.put(MiningStatusKey, typeBool, status)
+}
+
+// Challenge is the current mining challenge.
+type Challenge struct {
+	Challenge  []byte
+	RequestIDs [5]*big.Int
+	Difficulty *big.Int
+	TotalTip   *big.Int
+}
+
+var requestIDKeys = [5]string{RequestIdKey0, RequestIdKey1, RequestIdKey2, RequestIdKey3, RequestIdKey4}
+
+// SetChallenge stores the current mining challenge.
+// All its keys are written at once so that they are never read from different challenges.
+func (s *Store) SetChallenge(c *Challenge) error {
+	values := []typedValue{
+		{key: CurrentChallengeKey, t: typeBytes, value: c.Challenge},
+		bigValue(DifficultyKey, c.Difficulty),
+		bigValue(TotalTipKey, c.TotalTip),
+	}
+	for i, id := range c.RequestIDs {
+		values = append(values, bigValue(requestIDKeys[i], id))
+	}
+	return s.putAll(values...)
+}
+
+// GetChallenge returns the current mining challenge and when it was written.
+func (s *Store) GetChallenge() (*Challenge, time.Time, error) {
+	keys := append([]string{CurrentChallengeKey, DifficultyKey, TotalTipKey}, requestIDKeys[:]...)
+	records, err := s.get(keys...)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for _, k := range keys {
+		if _, ok := records[k]; !ok {
+			return nil, time.Time{}, nil
+		}
+	}
+	c := &Challenge{Challenge: records[CurrentChallengeKey].Value}
+	for i, k := range requestIDKeys {
+		if c.RequestIDs[i], err = decodeBig(k, records[k]); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	if c.Difficulty, err = decodeBig(DifficultyKey, records[DifficultyKey]); err != nil {
+		return nil, time.Time{}, err
+	}
+	if c.TotalTip, err = decodeBig(TotalTipKey, records[TotalTipKey]); err != nil {
+		return nil, time.Time{}, err
+	}
+	return c, records[CurrentChallengeKey].Updated, nil
+}
+
+// PSRValue is the value of a PSR.
+type PSRValue struct {
+	Value *big.Int
+	// Confidence is unknown and zero for the values written before the records.
+	Confidence float64
+	// Timestamp is the time of the value, zero when unknown.
+	Timestamp time.Time
+}
+
+// psrValue is the encoding of a PSRValue.
+type psrValue struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+	Timestamp  int64   `json:"timestamp"`
+}
+
+// PSRValueKey returns the key of the value of a PSR.
+func PSRValueKey(requestID uint64) string {
+	return QueriedValuePrefix + strconv.FormatUint(requestID, 10)
+}
+
+// PutPSRValue stores the value of a PSR with its confidence and the time it is for.
+func (s *Store) PutPSRValue(requestID uint64, value *big.Int, confidence float64, ts time.Time) error {
+	data, err := json.Marshal(&psrValue{
+		Value:      hexutil.EncodeBig(value),
+		Confidence: confidence,
+		Timestamp:  ts.Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "encoding the PSR value")
+	}
+	return s.put(PSRValueKey(requestID), typePSR, data)
+}
+
+// GetPSRValue returns the value of a PSR and when it was written.
+func (s *Store) GetPSRValue(requestID uint64) (*PSRValue, time.Time, error) {
+	key := PSRValueKey(requestID)
+	records, err := s.get(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	r, ok := records[key]
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	v, err := decodePSRValue(key, r)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return v, r.Updated, nil
+}
+
+// StoredPSRValue is a PSR value and when it was written.
+type StoredPSRValue struct {
+	*PSRValue
+	Updated time.Time
+}
+
+// GetPSRValues returns all the stored PSR values by their request ID.
+func (s *Store) GetPSRValues() (map[uint64]*StoredPSRValue, error) {
+	records, err := s.scan(QueriedValuePrefix)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64]*StoredPSRValue, len(records))
+	for k, r := range records {
+		requestID, err := strconv.ParseUint(strings.TrimPrefix(k, QueriedValuePrefix), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding the request id of key:%v", k)
+		}
+		v, err := decodePSRValue(k, r)
+		if err != nil {
+			return nil, err
+		}
+		values[requestID] = &StoredPSRValue{PSRValue: v, Updated: r.Updated}
+	}
+	return values, nil
+}
+
+func decodePSRValue(key string, r *Record) (*PSRValue, error) {
+	if r.Version == 0 {
+		v, err := decodeBig(key, r)
+		if err != nil {
+			return nil, err
+		}
+		return &PSRValue{Value: v}, nil
+	}
+	var enc psrValue
+	if err := json.Unmarshal(r.Value, &enc); err != nil {
+		return nil, errors.Wrapf(err, "decoding key:%v", key)
+	}
+	v, err := hexutil.DecodeBig(enc.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding key:%v", key)
+	}
+	return &PSRValue{Value: v, Confidence: enc.Confidence, Timestamp: time.Unix(enc.Timestamp, 0)}, nil
+}
+
+// SetDisputeCheckerBlock stores the last block checked by the dispute checker.
+func (s *Store) SetDisputeCheckerBlock(block uint64) error {
+	return s.put(DisputeCheckerBlockKey, typeUint64, []byte(hexutil.EncodeUint64(block)))
+}
+
+// GetDisputeCheckerBlock returns the last block checked by the dispute checker, zero when none.
+func (s *Store) GetDisputeCheckerBlock() (uint64, time.Time, error) {
+	records, err := s.get(DisputeCheckerBlockKey)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	r, ok := records[DisputeCheckerBlockKey]
+	if !ok {
+		return 0, time.Time{}, nil
+	}
+	block, err := hexutil.DecodeUint64(string(r.Value))
+	if err != nil {
+		return 0, time.Time{}, errors.Wrapf(err, "decoding key:%v", DisputeCheckerBlockKey)
+	}
+	return block, r.Updated, nil
+}
+
+// SetGasUsed stores the gas used by the transaction of the miner in the slot.
+func (s *Store) SetGasUsed(slot *big.Int, gas *big.Int) error {
+	return s.put(GasUsedPrefix+slot.String(), typeBigBytes, gas.Bytes())
+}
+
+// GetGasUsed returns the gas used by the transaction of the miner in the slot, nil when unknown.
+func (s *Store) GetGasUsed(slot *big.Int) (*big.Int, time.Time, error) {
+	key := GasUsedPrefix + slot.String()
+	records, err := s.get(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	r, ok := records[key]
+	if !ok {
+		return nil, time.Time{}, nil
+	}
+	return new(big.Int).SetBytes(r.Value), r.Updated, nil
+}
+
+// PutJSON stores the JSON encoding of the value of a key holding the values of other packages.
+func (s *Store) PutJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrapf(err, "encoding key:%v", key)
+	}
+	return s.put(key, typeJSON, data)
+}
+
+// GetJSON decodes the value of the key into v and returns when it was written.
+// It leaves v unchanged when the key isn't written yet.
+func (s *Store) GetJSON(key string, v interface{}) (time.Time, error) {
+	records, err := s.get(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	r, ok := records[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+	if err := json.Unmarshal(r.Value, v); err != nil {
+		return time.Time{}, errors.Wrapf(err, "decoding key:%v", key)
+	}
+	return r.Updated, nil
+}
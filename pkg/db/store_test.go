@@ -0,0 +1,91 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestStore(t *testing.T) {
+	_, proxy, DB := openTestRPC(t)
+	store := NewStore(proxy)
+
+	// Nothing is written yet.
+	c, updated, err := store.GetChallenge()
+	testutil.Ok(t, err)
+	testutil.Assert(t, c == nil && updated.IsZero(), "expected no challenge")
+	v, _, err := store.GetPSRValue(1)
+	testutil.Ok(t, err)
+	testutil.Assert(t, v == nil, "expected no PSR value")
+
+	challenge := &Challenge{
+		Challenge:  []byte{0xab, 0xcd},
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Difficulty: big.NewInt(1000),
+		TotalTip:   big.NewInt(20),
+	}
+	testutil.Ok(t, store.SetChallenge(challenge))
+	c, updated, err = store.GetChallenge()
+	testutil.Ok(t, err)
+	testutil.Equals(t, challenge, c)
+	testutil.Assert(t, time.Since(updated) < time.Minute, "expected the write time")
+
+	ts := time.Unix(1600000000, 0)
+	testutil.Ok(t, store.PutPSRValue(1, big.NewInt(350), 0.8, ts))
+	v, _, err = store.GetPSRValue(1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, &PSRValue{Value: big.NewInt(350), Confidence: 0.8, Timestamp: ts}, v)
+
+	miner := common.HexToAddress("0x92f91500e105e3051f3cf94616831b58f6bce1e8")
+	testutil.Ok(t, store.SetMinerTimeOut(miner, time.Time{}))
+	last, updated, err := store.GetMinerTimeOut(miner)
+	testutil.Ok(t, err)
+	testutil.Assert(t, last.IsZero() && !updated.IsZero(), "expected a miner that never submitted")
+
+	// The values written before the records have no write time.
+	testutil.Ok(t, DB.Put(GasKey, []byte(hexutil.EncodeBig(big.NewInt(7)))))
+	gas, updated, err := store.GetGasPrice()
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(7), gas)
+	testutil.Assert(t, updated.IsZero(), "expected no write time")
+	testutil.Ok(t, DB.Put(PSRValueKey(2), []byte(hexutil.EncodeBig(big.NewInt(9)))))
+	v, _, err = store.GetPSRValue(2)
+	testutil.Ok(t, err)
+	testutil.Equals(t, &PSRValue{Value: big.NewInt(9)}, v)
+
+	// The records of newer versions aren't decoded.
+	r := newRecord([]byte("0x1"))
+	r.Version = SchemaVersion + 1
+	testutil.Ok(t, DB.Put(GasKey, r.Encode()))
+	_, _, err = store.GetGasPrice()
+	testutil.NotOk(t, err)
+
+	// The schema is enforced.
+	testutil.NotOk(t, store.put(GasKey, typeBytes, []byte{1}))
+	testutil.NotOk(t, store.put("unknown", typeBig, []byte("0x1")))
+}
+
+func TestLegacyValue(t *testing.T) {
+	_, proxy, DB := openTestRPC(t)
+	store := NewStore(proxy)
+	testutil.Ok(t, store.SetGasPrice(big.NewInt(7)))
+	testutil.Ok(t, store.PutPSRValue(1, big.NewInt(350), 0.8, time.Now()))
+	testutil.Ok(t, DB.Put(DifficultyKey, []byte("0x2")))
+
+	for key, expected := range map[string]string{
+		GasKey:         "0x7",
+		PSRValueKey(1): "0x15e",
+		DifficultyKey:  "0x2",
+	} {
+		data, err := DB.Get(key)
+		testutil.Ok(t, err)
+		testutil.Equals(t, expected, string(legacyValue(key, data)))
+	}
+}
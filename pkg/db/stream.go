@@ -0,0 +1,439 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// StreamPath is the path of the stream of the key updates of the data server.
+const StreamPath = "/stream/v1"
+
+// MethodSubscribe subscribes to the updates of keys at the StreamPath.
+const MethodSubscribe = "db.subscribe"
+
+// streamDomain separates the signatures of the stream events from any other signed data.
+const streamDomain = "telliot-stream-v1"
+
+const (
+	// streamCoalesce is how long the updates are collected before sending them as a single event
+	// so that the keys written together by a tracker are received together.
+	streamCoalesce = 200 * time.Millisecond
+	// streamBuffer is how many updates a subscriber can fall behind before it is disconnected.
+	streamBuffer = 256
+	// StreamKeepalive is how often a comment is sent on an idle stream to keep the connection open.
+	StreamKeepalive = 30 * time.Second
+	// streamIdleTimeout is how long a miner waits for an event or a keepalive before closing the stream
+	// so that a broken connection doesn't keep it on stale values.
+	streamIdleTimeout = 2 * StreamKeepalive
+)
+
+// ErrStreamUnsupported is returned when the updates can't be streamed
+// and the keys need to be polled instead.
+var ErrStreamUnsupported = errors.New("the data server doesn't stream updates")
+
+// isPrefixKey returns whether a subscription key subscribes to all the keys with this prefix.
+func isPrefixKey(key string) bool {
+	return key == QueriedValuePrefix || key == QueryMetadataPrefix
+}
+
+type subscription struct {
+	keys    []string
+	updates chan map[string][]byte
+}
+
+func (s *subscription) matches(key string) bool {
+	for _, k := range s.keys {
+		if k == key || (isPrefixKey(k) && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier is a DB that notifies the subscribers of the written keys.
+type Notifier struct {
+	DB
+
+	mtx        sync.Mutex
+	subs       map[*subscription]bool
+	lastUpdate time.Time
+}
+
+// NewNotifier wraps the DB to notify the writes.
+func NewNotifier(db DB) *Notifier {
+	return &Notifier{DB: db, subs: make(map[*subscription]bool)}
+}
+
+func (n *Notifier) Put(key string, value []byte) error {
+	if err := n.DB.Put(key, value); err != nil {
+		return err
+	}
+	n.notify(map[string][]byte{key: value})
+	return nil
+}
+
+// Batch notifies the keys written by the batch as a single update.
+func (n *Notifier) Batch(b *Batch) error {
+	if err := n.DB.Batch(b); err != nil {
+		return err
+	}
+	n.notify(b.puts)
+	return nil
+}
+
+func (n *Notifier) notify(values map[string][]byte) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.lastUpdate = time.Now()
+	for s := range n.subs {
+		update := make(map[string][]byte)
+		for k, v := range values {
+			if s.matches(k) {
+				update[k] = v
+			}
+		}
+		if len(update) == 0 {
+			continue
+		}
+		select {
+		case s.updates <- update:
+		default:
+			// A subscriber that can't keep up would miss updates
+			// so disconnect it to reconnect and get the current values.
+			delete(n.subs, s)
+			close(s.updates)
+		}
+	}
+}
+
+// LastUpdate returns the time of the last write.
+func (n *Notifier) LastUpdate() time.Time {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.lastUpdate
+}
+
+func (n *Notifier) subscribe(keys []string) *subscription {
+	s := &subscription{keys: keys, updates: make(chan map[string][]byte, streamBuffer)}
+	n.mtx.Lock()
+	n.subs[s] = true
+	n.mtx.Unlock()
+	return s
+}
+
+func (n *Notifier) unsubscribe(s *subscription) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.subs[s] {
+		delete(n.subs, s)
+		close(s.updates)
+	}
+}
+
+// StreamEvent is an update of keys signed by the data server.
+type StreamEvent struct {
+	Values    map[string]hexutil.Bytes `json:"values"`
+	Timestamp int64                    `json:"timestamp"`
+	Signature hexutil.Bytes            `json:"signature"`
+}
+
+// hash returns the hash signed by the data server.
+func (e *StreamEvent) hash(i *remoteImpl) ([]byte, error) {
+	keys := make([]string, 0, len(e.Values))
+	for k := range e.Values {
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no values in the event")
+	}
+	sort.Strings(keys)
+	values := make([][]byte, len(keys))
+	for idx, k := range keys {
+		values[idx] = e.Values[k]
+	}
+	buf := new(bytes.Buffer)
+	if err := encodeString(buf, streamDomain); err != nil {
+		return nil, err
+	}
+	if err := encodeKeysValuesAndTime(i.logger, buf, keys, values, e.Timestamp); err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(buf.Bytes()), nil
+}
+
+func (i *remoteImpl) signEvent(values map[string][]byte) (*StreamEvent, error) {
+	e := &StreamEvent{Values: make(map[string]hexutil.Bytes, len(values)), Timestamp: time.Now().Unix()}
+	for k, v := range values {
+		e.Values[k] = v
+	}
+	hash, err := e.hash(i)
+	if err != nil {
+		return nil, err
+	}
+	if e.Signature, err = i.Sign(hash); err != nil {
+		return nil, errors.Wrap(err, "signing the event")
+	}
+	return e, nil
+}
+
+// Subscribe verifies a signed subscription of a remote miner and returns the signed updates of the
+// subscribed keys, starting with their current values, until cancel is called.
+// The updates are closed when the subscriber falls behind.
+func (i *remoteImpl) Subscribe(peer string, data []byte) (<-chan *StreamEvent, func(), error) {
+	notifier, ok := i.localDB.(*Notifier)
+	if !ok {
+		return nil, nil, rpcErrorf(CodeMethodNotFound, "the data server doesn't stream updates")
+	}
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, nil, rpcErrorf(CodeParseError, "parsing the request: %v", err)
+	}
+	if req.Method != MethodSubscribe {
+		return nil, nil, rpcErrorf(CodeMethodNotFound, "unknown method:%v", req.Method)
+	}
+	if req.Params == nil || len(req.Params.Keys) == 0 {
+		return nil, nil, rpcErrorf(CodeInvalidParams, "no keys in the request")
+	}
+	signer, rpcErr := i.verifyRPC(peer, req.Method, req.Params)
+	if rpcErr != nil {
+		return nil, nil, rpcErr
+	}
+	for _, k := range req.Params.Keys {
+		if !i.scopes[signer].canRead(k) {
+			return nil, nil, i.audit.reject(signer, req.Method, req.Params.Keys, rpcErrorf(CodeInvalidKey, "invalid subscription key: %v", k))
+		}
+	}
+
+	// Subscribe before reading the current values so that no update is missed.
+	sub := notifier.subscribe(req.Params.Keys)
+	done := make(chan struct{})
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(done)
+			notifier.unsubscribe(sub)
+		})
+	}
+	events := make(chan *StreamEvent)
+	go func() {
+		defer close(events)
+		send := func(values map[string][]byte) bool {
+			if len(values) == 0 {
+				return true
+			}
+			e, err := i.signEvent(values)
+			if err != nil {
+				level.Error(i.logger).Log("msg", "signing a stream event", "err", err)
+				return false
+			}
+			select {
+			case events <- e:
+				return true
+			case <-done:
+				return false
+			}
+		}
+
+		current := make(map[string][]byte)
+		for _, k := range req.Params.Keys {
+			if isPrefixKey(k) {
+				continue
+			}
+			v, err := i.localDB.Get(k)
+			if err != nil {
+				level.Error(i.logger).Log("msg", "getting the current value", "key", k, "err", err)
+				continue
+			}
+			if v != nil {
+				current[k] = v
+			}
+		}
+		if !send(current) {
+			return
+		}
+
+		for {
+			var values map[string][]byte
+			select {
+			case update, ok := <-sub.updates:
+				if !ok {
+					return
+				}
+				values = update
+			case <-done:
+				return
+			}
+			// Collect the keys written together.
+			coalesce := time.NewTimer(streamCoalesce)
+		collect:
+			for {
+				select {
+				case more, ok := <-sub.updates:
+					if !ok {
+						break collect
+					}
+					for k, v := range more {
+						values[k] = v
+					}
+				case <-coalesce.C:
+					break collect
+				case <-done:
+					coalesce.Stop()
+					return
+				}
+			}
+			coalesce.Stop()
+			if !send(values) {
+				return
+			}
+		}
+	}()
+	return events, cancel, nil
+}
+
+// Stream subscribes to the updates of the keys on the remote data server and sends their values,
+// starting with the current ones, until the context is done or the stream breaks.
+// The updates are only streamed when the address of the data server that signs them is known.
+func (i *remoteImpl) Stream(ctx context.Context, keys []string) (<-chan map[string][]byte, error) {
+	if !i.isRemote || i.streamSigner == "" {
+		return nil, ErrStreamUnsupported
+	}
+	if len(i.endpoints.list) == 0 {
+		return nil, errNoEndpoints
+	}
+	// The freshest data server that supports the RPC.
+	var ep *endpoint
+	for _, e := range i.endpoints.ordered(time.Now()) {
+		if !i.endpoints.isLegacy(e, time.Now()) {
+			ep = e
+			break
+		}
+	}
+	if ep == nil {
+		return nil, ErrStreamUnsupported
+	}
+	body, _, err := i.newRequest(MethodSubscribe, keys, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url+StreamPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the stream request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	// The stream stays open so it can't use the request timeout of the other requests.
+	resp, err := (&http.Client{Transport: i.httpClient.Transport}).Do(req)
+	if err != nil {
+		i.endpoints.failed(ep, time.Now())
+		return nil, errors.Wrap(err, "connecting to the stream")
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrStreamUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		var rpcErr RPCError
+		if err := json.Unmarshal(data, &rpcErr); err == nil && rpcErr.Code != 0 {
+			if rpcErr.Code == CodeMethodNotFound {
+				return nil, ErrStreamUnsupported
+			}
+			return nil, &rpcErr
+		}
+		return nil, errors.Errorf("stream response status:%v", resp.Status)
+	}
+
+	out := make(chan map[string][]byte)
+	go func() {
+		defer close(out)
+		defer resp.Body.Close()
+		// The data server sends keepalives so a stream without them is broken.
+		idle := time.AfterFunc(i.streamIdle, func() {
+			level.Warn(i.logger).Log("msg", "no events or keepalives from the data server, closing the stream", "timeout", i.streamIdle)
+			resp.Body.Close()
+		})
+		defer idle.Stop()
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		var data []byte
+		var last int64
+		for scanner.Scan() {
+			idle.Reset(i.streamIdle)
+			line := scanner.Text()
+			if strings.HasPrefix(line, "data:") {
+				data = append(data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+				continue
+			}
+			// Comments are keepalives and a blank line ends an event.
+			if line != "" || len(data) == 0 {
+				continue
+			}
+			values, timestamp, err := i.verifyEvent(data)
+			data = nil
+			if err == nil && timestamp < last {
+				err = errors.Errorf("event time:%v is before the previous event", time.Unix(timestamp, 0))
+			}
+			last = timestamp
+			if err != nil {
+				level.Error(i.logger).Log("msg", "rejected stream event, closing the stream", "err", err)
+				return
+			}
+			select {
+			case out <- values:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			level.Warn(i.logger).Log("msg", "the stream broke", "err", err)
+		}
+	}()
+	return out, nil
+}
+
+// verifyEvent checks that the event is signed by the data server within the clock skew
+// and returns its values and time.
+func (i *remoteImpl) verifyEvent(data []byte) (map[string][]byte, int64, error) {
+	var e StreamEvent
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, 0, errors.Wrap(err, "decoding the event")
+	}
+	hash, err := e.hash(i)
+	if err != nil {
+		return nil, 0, err
+	}
+	pubKey, err := crypto.SigToPub(hash, e.Signature)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "invalid event signature")
+	}
+	signer := strings.ToLower(crypto.PubkeyToAddress(*pubKey).Hex())
+	if signer != i.streamSigner {
+		return nil, 0, errors.Errorf("event signed by:%v instead of the data server:%v", signer, i.streamSigner)
+	}
+	if skew := time.Since(time.Unix(e.Timestamp, 0)); skew > i.clockSkew || skew < -i.clockSkew {
+		return nil, 0, errors.Errorf("event time:%v is outside of the clock skew:%v", time.Unix(e.Timestamp, 0), i.clockSkew)
+	}
+	values := make(map[string][]byte, len(e.Values))
+	for k, v := range e.Values {
+		values[k] = v
+	}
+	return values, e.Timestamp, nil
+}
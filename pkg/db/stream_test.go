@@ -0,0 +1,177 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func receive(t *testing.T, i *remoteImpl, events <-chan *StreamEvent) map[string][]byte {
+	select {
+	case e, ok := <-events:
+		testutil.Assert(t, ok, "the stream closed")
+		data, err := json.Marshal(e)
+		testutil.Ok(t, err)
+		values, _, err := i.verifyEvent(data)
+		testutil.Ok(t, err)
+		return values
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stream event")
+	}
+	return nil
+}
+
+func TestSubscribe(t *testing.T) {
+	cfg, _, DB := openTestRPC(t)
+	cfg.Mine.RemoteDBSigner = "0x92f91500e105e3051f3cf94616831b58f6bce1e8"
+	notifier := NewNotifier(DB)
+	proxy, err := OpenLocal(logging.NewLogger(), cfg, notifier)
+	testutil.Ok(t, err)
+	server := proxy.(*remoteImpl)
+	testutil.Ok(t, notifier.Put(DifficultyKey, []byte("2")))
+
+	now := time.Now().Unix()
+	keys := []string{DifficultyKey, CurrentChallengeKey, RequestIdKey0, QueriedValuePrefix}
+	events, cancel, err := server.Subscribe("", signedRPC(t, server, MethodSubscribe, MethodSubscribe, keys, now, "1"))
+	testutil.Ok(t, err)
+
+	// The current values come first.
+	testutil.Equals(t, map[string][]byte{DifficultyKey: []byte("2")}, receive(t, server, events))
+
+	// The keys written together are sent together.
+	testutil.Ok(t, notifier.Put(CurrentChallengeKey, []byte("challenge")))
+	testutil.Ok(t, notifier.Put(TotalTipKey, []byte("not subscribed")))
+	testutil.Ok(t, notifier.Put(RequestIdKey0, []byte("1")))
+	testutil.Equals(t, map[string][]byte{
+		CurrentChallengeKey: []byte("challenge"),
+		RequestIdKey0:       []byte("1"),
+	}, receive(t, server, events))
+
+	testutil.Ok(t, notifier.Put(QueriedValuePrefix+"1", []byte("100")))
+	testutil.Equals(t, map[string][]byte{QueriedValuePrefix + "1": []byte("100")}, receive(t, server, events))
+
+	cancel()
+	for range events {
+	}
+
+	// Events of another signer are rejected.
+	server.streamSigner = "0x053b09e98ede40997546e8bb812cd838f18bb146"
+	e, err := server.signEvent(map[string][]byte{DifficultyKey: []byte("3")})
+	testutil.Ok(t, err)
+	data, err := json.Marshal(e)
+	testutil.Ok(t, err)
+	_, _, err = server.verifyEvent(data)
+	testutil.NotOk(t, err)
+
+	// Events outside of the clock skew are rejected.
+	server.streamSigner = server.publicAddress
+	_, _, err = server.verifyEvent(data)
+	testutil.Ok(t, err)
+	e.Timestamp -= 120
+	hash, err := e.hash(server)
+	testutil.Ok(t, err)
+	e.Signature, err = server.Sign(hash)
+	testutil.Ok(t, err)
+	data, err = json.Marshal(e)
+	testutil.Ok(t, err)
+	_, _, err = server.verifyEvent(data)
+	testutil.NotOk(t, err)
+
+	for name, tc := range map[string]struct {
+		data []byte
+		code int
+	}{
+		"replayed nonce": {signedRPC(t, server, MethodSubscribe, MethodSubscribe, keys, now, "1"), CodeReplayed},
+		"unknown key":    {signedRPC(t, server, MethodSubscribe, MethodSubscribe, []string{"unknown"}, now, "2"), CodeInvalidKey},
+		"other method":   {signedRPC(t, server, MethodGet, MethodGet, keys, now, "3"), CodeMethodNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := server.Subscribe("", tc.data)
+			testutil.NotOk(t, err)
+			testutil.Equals(t, tc.code, err.(*RPCError).Code)
+		})
+	}
+
+	// Streaming needs a notifier.
+	_, polling, _ := openTestRPC(t)
+	_, _, err = polling.Subscribe("", signedRPC(t, polling, MethodSubscribe, MethodSubscribe, keys, now, "4"))
+	testutil.Equals(t, CodeMethodNotFound, err.(*RPCError).Code)
+}
+
+func TestStreamIdle(t *testing.T) {
+	cfg, _, _ := openTestRPC(t)
+	// A data server that stops sending keepalives.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+	setRemote(t, cfg, srv.URL)
+
+	// The updates aren't streamed without the address of the data server.
+	cfg.Mine.RemoteDBSigner = ""
+	c, err := OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+	_, err = c.Stream(context.Background(), []string{CurrentChallengeKey})
+	testutil.Equals(t, ErrStreamUnsupported, err)
+
+	cfg.Mine.RemoteDBSigner = "0x92f91500e105e3051f3cf94616831b58f6bce1e8"
+	c, err = OpenRemote(logging.NewLogger(), cfg, nil)
+	testutil.Ok(t, err)
+	client := c.(*remoteImpl)
+	client.streamIdle = 100 * time.Millisecond
+	stream, err := client.Stream(context.Background(), []string{CurrentChallengeKey})
+	testutil.Ok(t, err)
+	select {
+	case _, ok := <-stream:
+		testutil.Assert(t, !ok, "expected no stream events")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the idle stream to close")
+	}
+}
+
+func TestNotifierSlowSubscriber(t *testing.T) {
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	notifier := NewNotifier(DB)
+	sub := notifier.subscribe([]string{DifficultyKey})
+	for i := 0; i <= streamBuffer; i++ {
+		testutil.Ok(t, notifier.Put(DifficultyKey, []byte("1")))
+	}
+	n := 0
+	for range sub.updates {
+		n++
+	}
+	testutil.Equals(t, streamBuffer, n)
+	// Unsubscribing a disconnected subscriber is safe.
+	notifier.unsubscribe(sub)
+}
+
+func TestNotifierBatch(t *testing.T) {
+	DB, cleanup := OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	notifier := NewNotifier(DB)
+	sub := notifier.subscribe([]string{DifficultyKey, QueriedValuePrefix})
+	b := NewBatch()
+	b.Put(DifficultyKey, []byte("1"))
+	b.Put(TotalTipKey, []byte("not subscribed"))
+	b.Put(PSRValueKey(1), []byte("100"))
+	testutil.Ok(t, notifier.Batch(b))
+	// The subscribed keys of the batch are a single update.
+	testutil.Equals(t, map[string][]byte{DifficultyKey: []byte("1"), PSRValueKey(1): []byte("100")}, <-sub.updates)
+	testutil.Assert(t, !notifier.LastUpdate().IsZero(), "expected the time of the batch")
+	notifier.unsubscribe(sub)
+}
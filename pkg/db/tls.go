@@ -0,0 +1,53 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package db
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// LocalDataServer returns the URL and the HTTP client of the data server listening on this host,
+// it uses https with the TLS config of the remote DB when the data server TLS is enabled.
+func LocalDataServer(cfg *config.Config) (string, *http.Client, error) {
+	addr := net.JoinHostPort(cfg.DataServer.ListenHost, strconv.FormatUint(uint64(cfg.DataServer.ListenPort), 10))
+	if !cfg.DataServer.TLS.Enabled {
+		return "http://" + addr, &http.Client{}, nil
+	}
+	tlsConfig, err := clientTLSConfig(cfg.Mine.RemoteDBTLS)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "creating the data server TLS config")
+	}
+	return "https://" + addr, &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}, nil
+}
+
+// clientTLSConfig creates the TLS config of the connection to the remote data server.
+func clientTLSConfig(cfg config.TLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading the CA file")
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates in the CA file:%v", cfg.CAFile)
+		}
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading the client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,336 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package events
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/contracts/master"
+	"github.com/tellor-io/telliot/pkg/logging"
+)
+
+const ComponentName = "events"
+
+// The indexed events of the Tellor contract.
+const (
+	NewDispute         = "NewDispute"
+	Voted              = "Voted"
+	DisputeVoteTallied = "DisputeVoteTallied"
+	NonceSubmitted     = "NonceSubmitted"
+	NewValue           = "NewValue"
+	TipAdded           = "TipAdded"
+)
+
+const (
+	// eventPrefix prefixes the event keys which are followed by the event name,
+	// a separator and the big endian block number and log index
+	// so that the events of each name are sorted by block.
+	eventPrefix = "e:"
+	// blockTimePrefix prefixes the keys of the cached block times.
+	blockTimePrefix = "b:"
+	checkpointKey   = "checkpoint"
+	eventSeparator  = 0
+)
+
+// Event is an indexed log of the Tellor contract with the time of its block.
+type Event struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	Log  types.Log `json:"log"`
+}
+
+// Source provides the indexed events.
+type Source interface {
+	// Events returns the events with the given name between the blocks, inclusive, oldest first.
+	// A zero toBlock returns all events since fromBlock.
+	Events(ctx context.Context, name string, fromBlock, toBlock uint64) ([]*Event, error)
+}
+
+// Index ingests the Tellor contract events incrementally into a LevelDB.
+// Every sync continues from the last processed block.
+type Index struct {
+	logger        log.Logger
+	db            *leveldb.DB
+	client        contracts.ETHClient
+	address       common.Address
+	names         map[common.Hash]string
+	startBlocks   uint64
+	confirmations uint64
+	batchBlocks   uint64
+
+	// syncMtx serializes the syncs so that the checkpoint only moves forward.
+	syncMtx sync.Mutex
+
+	// done and stopped control the sync loop started by Start.
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// Open opens the event index configured in cfg.
+func Open(logger log.Logger, cfg *config.Config, client contracts.ETHClient, contract *contracts.Tellor) (*Index, error) {
+	logger, err := logging.ApplyFilter(*cfg, ComponentName, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply filter logger")
+	}
+	names, err := eventNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var db *leveldb.DB
+	if cfg.Events.File == "" {
+		db, err = leveldb.Open(storage.NewMemStorage(), nil)
+	} else {
+		db, err = leveldb.OpenFile(cfg.Events.File, nil)
+		if _, corrupted := err.(*leveldbErrors.ErrCorrupted); corrupted {
+			db, err = leveldb.RecoverFile(cfg.Events.File, nil)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "open events db:%v", cfg.Events.File)
+	}
+
+	batchBlocks := cfg.Events.BatchBlocks
+	if batchBlocks == 0 {
+		batchBlocks = 1
+	}
+	return &Index{
+		logger:        log.With(logger, "component", ComponentName),
+		db:            db,
+		client:        client,
+		address:       contract.Address,
+		names:         names,
+		startBlocks:   cfg.Events.StartBlocks,
+		confirmations: cfg.Events.Confirmations,
+		batchBlocks:   batchBlocks,
+	}, nil
+}
+
+// eventNames maps the topic of every indexed event to its name.
+func eventNames() (map[common.Hash]string, error) {
+	names := make(map[common.Hash]string)
+	for _, contractABI := range []string{master.TellorDisputeABI, master.TellorLibraryABI} {
+		parsed, err := abi.JSON(strings.NewReader(contractABI))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse abi")
+		}
+		for _, name := range []string{NewDispute, Voted, DisputeVoteTallied, NonceSubmitted, NewValue, TipAdded} {
+			if ev, ok := parsed.Events[name]; ok {
+				names[ev.ID] = name
+			}
+		}
+	}
+	if len(names) != 6 {
+		return nil, errors.Errorf("missing events in the abi, found:%v", len(names))
+	}
+	return names, nil
+}
+
+// Start keeps the index in sync until Close.
+func (i *Index) Start(ctx context.Context, interval time.Duration) {
+	i.done = make(chan struct{})
+	i.stopped = make(chan struct{})
+	go func() {
+		defer close(i.stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := i.Sync(ctx); err != nil {
+				level.Error(i.logger).Log("msg", "sync events", "err", err)
+			}
+			select {
+			case <-i.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops the sync loop and closes the DB.
+func (i *Index) Close() error {
+	if i.done != nil {
+		close(i.done)
+		<-i.stopped
+		i.done = nil
+	}
+	return errors.Wrap(i.db.Close(), "close events db")
+}
+
+// Checkpoint returns the last processed block and
+// false when nothing is indexed yet.
+func (i *Index) Checkpoint() (uint64, bool, error) {
+	data, err := i.db.Get([]byte(checkpointKey), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrap(err, "get checkpoint")
+	}
+	return binary.BigEndian.Uint64(data), true, nil
+}
+
+// Sync indexes the events of the blocks after the checkpoint up to
+// the head of the chain minus the configured confirmations.
+func (i *Index) Sync(ctx context.Context) error {
+	i.syncMtx.Lock()
+	defer i.syncMtx.Unlock()
+
+	header, err := i.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "get latest eth block header")
+	}
+	head := header.Number.Uint64()
+	if head < i.confirmations {
+		return nil
+	}
+	to := head - i.confirmations
+
+	checkpoint, ok, err := i.Checkpoint()
+	if err != nil {
+		return err
+	}
+	from := checkpoint + 1
+	if !ok {
+		from = 0
+		if to > i.startBlocks {
+			from = to - i.startBlocks
+		}
+	}
+
+	topics := make([]common.Hash, 0, len(i.names))
+	for topic := range i.names {
+		topics = append(topics, topic)
+	}
+	for from <= to {
+		end := from + i.batchBlocks - 1
+		if end > to {
+			end = to
+		}
+		query := ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{i.address},
+			Topics:    [][]common.Hash{topics},
+		}
+		logs, err := i.client.FilterLogs(ctx, query)
+		if err != nil {
+			return errors.Wrapf(err, "filter logs from:%v to:%v", from, end)
+		}
+
+		batch := new(leveldb.Batch)
+		for _, l := range logs {
+			if l.Removed || len(l.Topics) == 0 {
+				continue
+			}
+			name, ok := i.names[l.Topics[0]]
+			if !ok {
+				continue
+			}
+			blockTime, err := i.BlockTime(ctx, l.BlockNumber)
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(&Event{Name: name, Time: blockTime, Log: l})
+			if err != nil {
+				return errors.Wrap(err, "encode event")
+			}
+			batch.Put(eventKey(name, l.BlockNumber, l.Index), data)
+		}
+		batch.Put([]byte(checkpointKey), uint64Bytes(end))
+		if err := i.db.Write(batch, nil); err != nil {
+			return errors.Wrap(err, "write events")
+		}
+		level.Debug(i.logger).Log("msg", "indexed events", "from", from, "to", end, "logs", len(logs))
+		from = end + 1
+	}
+	return nil
+}
+
+// BlockTime returns the time of a block, cached after the first lookup.
+func (i *Index) BlockTime(ctx context.Context, block uint64) (time.Time, error) {
+	key := append([]byte(blockTimePrefix), uint64Bytes(block)...)
+	data, err := i.db.Get(key, nil)
+	if err == nil {
+		return time.Unix(int64(binary.BigEndian.Uint64(data)), 0), nil
+	}
+	if err != leveldb.ErrNotFound {
+		return time.Time{}, errors.Wrap(err, "get block time")
+	}
+	header, err := i.client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "get block header:%v", block)
+	}
+	if err := i.db.Put(key, uint64Bytes(header.Time), nil); err != nil {
+		return time.Time{}, errors.Wrap(err, "put block time")
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// Events implements Source.
+func (i *Index) Events(ctx context.Context, name string, fromBlock, toBlock uint64) ([]*Event, error) {
+	events, _, err := i.page(name, fromBlock, toBlock, 0)
+	return events, err
+}
+
+// page returns the events like Events but stops at the first block after limit events
+// and returns that block to continue from, zero when there are no more events.
+// The events of a block are never split between pages. A zero limit returns all the events.
+func (i *Index) page(name string, fromBlock, toBlock uint64, limit int) ([]*Event, uint64, error) {
+	r := &util.Range{Start: eventKey(name, fromBlock, 0)}
+	if toBlock == 0 {
+		r.Limit = append([]byte(eventPrefix+name), eventSeparator+1)
+	} else {
+		r.Limit = eventKey(name, toBlock+1, 0)
+	}
+	iter := i.db.NewIterator(r, nil)
+	defer iter.Release()
+	var events []*Event
+	for iter.Next() {
+		e := &Event{}
+		if err := json.Unmarshal(iter.Value(), e); err != nil {
+			return nil, 0, errors.Wrap(err, "decode event")
+		}
+		if limit > 0 && len(events) >= limit && e.Log.BlockNumber != events[len(events)-1].Log.BlockNumber {
+			return events, e.Log.BlockNumber, errors.Wrap(iter.Error(), "iterate events")
+		}
+		events = append(events, e)
+	}
+	return events, 0, errors.Wrap(iter.Error(), "iterate events")
+}
+
+func eventKey(name string, block uint64, index uint) []byte {
+	key := append([]byte(eventPrefix+name), eventSeparator)
+	key = append(key, uint64Bytes(block)...)
+	idx := make([]byte, 4)
+	binary.BigEndian.PutUint32(idx, uint32(index))
+	return append(key, idx...)
+}
+
+func uint64Bytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,142 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+// fakeClient serves the logs of a chain with the given head.
+type fakeClient struct {
+	contracts.ETHClient
+	head    uint64
+	logs    []types.Log
+	queries [][2]uint64
+	headers map[uint64]int
+}
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, num *big.Int) (*types.Header, error) {
+	if num == nil {
+		return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+	}
+	c.headers[num.Uint64()]++
+	return &types.Header{Number: num, Time: 1600000000 + num.Uint64()*15}, nil
+}
+
+func (c *fakeClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	c.queries = append(c.queries, [2]uint64{from, to})
+	var logs []types.Log
+	for _, l := range c.logs {
+		if l.BlockNumber >= from && l.BlockNumber <= to {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func TestIndexSync(t *testing.T) {
+	names, err := eventNames()
+	testutil.Ok(t, err)
+	topics := make(map[string]common.Hash)
+	for topic, name := range names {
+		topics[name] = topic
+	}
+	newLog := func(name string, block uint64, index uint) types.Log {
+		return types.Log{Topics: []common.Hash{topics[name]}, BlockNumber: block, Index: index}
+	}
+	client := &fakeClient{
+		head: 120,
+		logs: []types.Log{
+			newLog(NewDispute, 10, 0),
+			newLog(NewDispute, 100, 0),
+			newLog(NonceSubmitted, 100, 1),
+			newLog(NonceSubmitted, 110, 0),
+			newLog(Voted, 116, 0),
+			{Topics: []common.Hash{{1}}, BlockNumber: 101},
+		},
+		headers: make(map[uint64]int),
+	}
+
+	cfg := config.OpenTestConfig(t)
+	cfg.Events.File = ""
+	cfg.Events.StartBlocks = 100
+	cfg.Events.Confirmations = 5
+	cfg.Events.BatchBlocks = 50
+	index, err := Open(logging.NewLogger(), cfg, client, &contracts.Tellor{})
+	testutil.Ok(t, err)
+	defer index.Close()
+	ctx := context.Background()
+
+	// The first sync starts StartBlocks before the head minus the confirmations.
+	testutil.Ok(t, index.Sync(ctx))
+	testutil.Equals(t, [][2]uint64{{15, 64}, {65, 114}, {115, 115}}, client.queries)
+	checkpoint, ok, err := index.Checkpoint()
+	testutil.Ok(t, err)
+	testutil.Assert(t, ok, "expected a checkpoint")
+	testutil.Equals(t, uint64(115), checkpoint)
+
+	disputes, err := index.Events(ctx, NewDispute, 0, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(disputes))
+	testutil.Equals(t, uint64(100), disputes[0].Log.BlockNumber)
+	testutil.Equals(t, int64(1600000000+100*15), disputes[0].Time.Unix())
+	// Block times are cached.
+	testutil.Equals(t, 1, client.headers[100])
+
+	// The next sync continues from the checkpoint.
+	client.queries = nil
+	client.head = 130
+	testutil.Ok(t, index.Sync(ctx))
+	testutil.Equals(t, [][2]uint64{{116, 125}}, client.queries)
+
+	nonces, err := index.Events(ctx, NonceSubmitted, 101, 110)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(nonces))
+	testutil.Equals(t, uint64(110), nonces[0].Log.BlockNumber)
+
+	// The index is served over HTTPS.
+	srv := httptest.NewTLSServer(index)
+	defer srv.Close()
+	votes, err := NewRemote(srv.URL, srv.Client()).Events(ctx, Voted, 0, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(votes))
+	testutil.Equals(t, Voted, votes[0].Name)
+	testutil.Equals(t, uint64(116), votes[0].Log.BlockNumber)
+
+	// The served events are paged.
+	defer func(size int) { eventsPageSize = size }(eventsPageSize)
+	eventsPageSize = 1
+	nonces, err = NewRemote(srv.URL, srv.Client()).Events(ctx, NonceSubmitted, 0, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(nonces))
+	testutil.Equals(t, uint64(100), nonces[0].Log.BlockNumber)
+	testutil.Equals(t, uint64(110), nonces[1].Log.BlockNumber)
+	var page eventsPage
+	resp, err := srv.Client().Get(srv.URL + "/events?name=" + NonceSubmitted + "&from=0")
+	testutil.Ok(t, err)
+	defer resp.Body.Close()
+	testutil.Ok(t, json.NewDecoder(resp.Body).Decode(&page))
+	testutil.Equals(t, 1, len(page.Events))
+	testutil.Equals(t, uint64(110), page.Next)
+
+	// The first block is required.
+	resp, err = srv.Client().Get(srv.URL + "/events?name=" + NonceSubmitted)
+	testutil.Ok(t, err)
+	resp.Body.Close()
+	testutil.Equals(t, http.StatusBadRequest, resp.StatusCode)
+}
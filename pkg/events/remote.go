@@ -0,0 +1,123 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// eventsPageSize is the number of events after which a response ends at the next block.
+var eventsPageSize = 1000
+
+// eventsPage is a page of the indexed events served by a data server.
+type eventsPage struct {
+	Events []*Event `json:"events"`
+	// Next is the block to continue from, zero when there are no more events.
+	Next uint64 `json:"next"`
+}
+
+// ServeHTTP responds with a page of the indexed events as JSON.
+// The name query parameter selects the event,
+// the from parameter is the first block and the optional to parameter the last one.
+// A page ends at the first block after eventsPageSize events
+// and the next page continues from the block in its next field.
+func (i *Index) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("name")
+	if name == "" {
+		http.Error(w, "missing the event name", http.StatusBadRequest)
+		return
+	}
+	if query.Get("from") == "" {
+		http.Error(w, "missing the from block", http.StatusBadRequest)
+		return
+	}
+	var blocks [2]uint64
+	for n, param := range []string{"from", "to"} {
+		if v := query.Get(param); v != "" {
+			block, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %v block:%v", param, v), http.StatusBadRequest)
+				return
+			}
+			blocks[n] = block
+		}
+	}
+	events, next, err := i.page(name, blocks[0], blocks[1], eventsPageSize)
+	if err != nil {
+		level.Error(i.logger).Log("msg", "get events", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []*Event{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(eventsPage{Events: events, Next: next}); err != nil {
+		level.Error(i.logger).Log("msg", "encode events", "err", err)
+	}
+}
+
+// Remote queries the index served by a data server.
+type Remote struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemote creates a Source for the index of the data server at the given URL
+// which is queried with the client.
+func NewRemote(url string, client *http.Client) *Remote {
+	return &Remote{url: url + "/events", client: client}
+}
+
+// Events implements Source by fetching all the pages of the events.
+func (r *Remote) Events(ctx context.Context, name string, fromBlock, toBlock uint64) ([]*Event, error) {
+	var events []*Event
+	for {
+		page, err := r.page(ctx, name, fromBlock, toBlock)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Events...)
+		if page.Next == 0 {
+			return events, nil
+		}
+		if page.Next <= fromBlock {
+			return nil, errors.Errorf("the data server returned the next block:%v before the requested:%v", page.Next, fromBlock)
+		}
+		fromBlock = page.Next
+	}
+}
+
+func (r *Remote) page(ctx context.Context, name string, fromBlock, toBlock uint64) (*eventsPage, error) {
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("from", strconv.FormatUint(fromBlock, 10))
+	params.Set("to", strconv.FormatUint(toBlock, 10))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "create events request")
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch events from the data server")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch events from the data server status:%v", resp.Status)
+	}
+	page := &eventsPage{}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, errors.Wrap(err, "decode events")
+	}
+	return page, nil
+}
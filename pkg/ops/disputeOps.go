@@ -11,7 +11,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -22,6 +21,7 @@ import (
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/master"
+	"github.com/tellor-io/telliot/pkg/events"
 	"github.com/tellor-io/telliot/pkg/rpc"
 	"github.com/tellor-io/telliot/pkg/tracker"
 	"github.com/tellor-io/telliot/pkg/util"
@@ -50,9 +50,7 @@ func Dispute(
 	if err != nil {
 		return errors.Wrap(err, "fetch balance")
 	}
-	var asBytes32 [32]byte
-	copy(asBytes32[:], "0x8b75eb45d88e80f0e4ec77d23936268694c0e7ac2e0c9085c5c6bdfcfbc49239") // keccak256(disputeFee).
-	disputeCost, err := contract.Getter.GetUintVar(nil, asBytes32)
+	disputeCost, err := tracker.DisputeFee(contract)
 	if err != nil {
 		return errors.Wrap(err, "get dispute cost")
 	}
@@ -63,7 +61,7 @@ func Dispute(
 			util.FormatERC20Balance(disputeCost))
 	}
 
-	auth, err := PrepareEthTransaction(ctx, client, account)
+	auth, err := rpc.PrepareEthTransaction(ctx, client, account)
 	if err != nil {
 		return errors.Wrapf(err, "prepare ethereum transaction")
 	}
@@ -86,7 +84,7 @@ func Vote(
 	supportsDispute bool,
 ) error {
 
-	voted, err := contract.Getter.DidVote(nil, disputeId, contract.Address)
+	voted, err := contract.Getter.DidVote(nil, disputeId, account.Address)
 	if err != nil {
 		return errors.Wrapf(err, "check if you've already voted")
 	}
@@ -95,7 +93,7 @@ func Vote(
 		return nil
 	}
 
-	auth, err := PrepareEthTransaction(ctx, client, account)
+	auth, err := rpc.PrepareEthTransaction(ctx, client, account)
 	if err != nil {
 		return errors.Wrapf(err, "prepare ethereum transaction")
 	}
@@ -108,10 +106,261 @@ func Vote(
 	return nil
 }
 
-func getNonceSubmissions(
+// DisputeInfo is the state of a dispute as stored in the contract.
+type DisputeInfo struct {
+	ID                  *big.Int
+	Hash                [32]byte
+	Executed            bool
+	VotePassed          bool
+	IsPropFork          bool
+	ReportedMiner       common.Address
+	ReportingParty      common.Address
+	ProposedForkAddress common.Address
+	RequestID           *big.Int
+	Timestamp           *big.Int
+	Value               *big.Int
+	MinExecutionDate    time.Time
+	NumberOfVotes       *big.Int
+	BlockNumber         *big.Int
+	MinerSlot           *big.Int
+	Quorum              *big.Int
+	Fee                 *big.Int
+	Tally               *big.Int
+	// TallyDate is zero until the votes are tallied.
+	TallyDate time.Time
+	FeePaid   bool
+}
+
+// GetDisputeInfo reads the state of a dispute from the contract.
+func GetDisputeInfo(contract *contracts.Tellor, disputeId *big.Int) (*DisputeInfo, error) {
+	hash, executed, votePassed, isPropFork, reportedMiner, reportingParty, proposedFork, uintVars, tally, err := contract.Getter.GetAllDisputeVars(nil, disputeId)
+	if err != nil {
+		return nil, errors.Wrap(err, "get dispute details")
+	}
+	if reportingParty == (common.Address{}) {
+		return nil, errors.Errorf("dispute doesn't exist:%v", disputeId)
+	}
+	tallyDate, err := contract.Getter.GetDisputeUintVars(nil, disputeId, rpc.Keccak256([]byte("tallyDate")))
+	if err != nil {
+		return nil, errors.Wrap(err, "get dispute tally date")
+	}
+	paid, err := contract.Getter.GetDisputeUintVars(nil, disputeId, rpc.Keccak256([]byte("paid")))
+	if err != nil {
+		return nil, errors.Wrap(err, "get dispute fee paid status")
+	}
+	info := &DisputeInfo{
+		ID:                  disputeId,
+		Hash:                hash,
+		Executed:            executed,
+		VotePassed:          votePassed,
+		IsPropFork:          isPropFork,
+		ReportedMiner:       reportedMiner,
+		ReportingParty:      reportingParty,
+		ProposedForkAddress: proposedFork,
+		RequestID:           uintVars[0],
+		Timestamp:           uintVars[1],
+		Value:               uintVars[2],
+		MinExecutionDate:    time.Unix(uintVars[3].Int64(), 0),
+		NumberOfVotes:       uintVars[4],
+		BlockNumber:         uintVars[5],
+		MinerSlot:           uintVars[6],
+		Quorum:              uintVars[7],
+		Fee:                 uintVars[8],
+		Tally:               tally,
+		FeePaid:             paid.Sign() != 0,
+	}
+	if tallyDate.Sign() != 0 {
+		info.TallyDate = time.Unix(tallyDate.Int64(), 0)
+	}
+	return info, nil
+}
+
+// canTally returns an error when the votes of the dispute can't be tallied at the given time.
+func canTally(info *DisputeInfo, now time.Time) error {
+	if info.Executed {
+		return errors.Errorf("dispute %v is already tallied", info.ID)
+	}
+	if now.Before(info.MinExecutionDate) {
+		return errors.Errorf("voting on dispute %v ends at %v", info.ID, info.MinExecutionDate.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// disputeFeeLockPeriod is how long after the tally the dispute fee stays locked.
+const disputeFeeLockPeriod = 24 * time.Hour
+
+// canUnlockFee returns an error when the fee of the dispute can't be unlocked at the given time.
+func canUnlockFee(info *DisputeInfo, now time.Time) error {
+	if !info.Executed || info.TallyDate.IsZero() {
+		return errors.Errorf("dispute %v isn't tallied yet", info.ID)
+	}
+	if info.FeePaid {
+		return errors.Errorf("the fee of dispute %v is already paid out", info.ID)
+	}
+	if unlock := info.TallyDate.Add(disputeFeeLockPeriod); !now.After(unlock) {
+		return errors.Errorf("the fee of dispute %v unlocks after %v", info.ID, unlock.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Tally tallies the votes of a dispute once the voting has ended.
+func Tally(
+	ctx context.Context,
+	logger log.Logger,
+	client contracts.ETHClient,
+	contract *contracts.Tellor,
+	account *rpc.Account,
+	disputeId *big.Int,
+) error {
+	info, err := GetDisputeInfo(contract, disputeId)
+	if err != nil {
+		return err
+	}
+	if err := canTally(info, time.Now()); err != nil {
+		return err
+	}
+
+	auth, err := rpc.PrepareEthTransaction(ctx, client, account)
+	if err != nil {
+		return errors.Wrapf(err, "prepare ethereum transaction")
+	}
+	tx, err := contract.Caller.TallyVotes(auth, disputeId)
+	if err != nil {
+		return errors.Wrapf(err, "submit tally transaction")
+	}
+	level.Info(logger).Log("msg", "tally submitted with transaction", "tx", tx.Hash().Hex())
+	return nil
+}
+
+// UnlockDisputeFee pays out the fee of a tallied dispute once the lock period has passed.
+func UnlockDisputeFee(
 	ctx context.Context,
+	logger log.Logger,
 	client contracts.ETHClient,
 	contract *contracts.Tellor,
+	account *rpc.Account,
+	disputeId *big.Int,
+) error {
+	info, err := GetDisputeInfo(contract, disputeId)
+	if err != nil {
+		return err
+	}
+	if err := canUnlockFee(info, time.Now()); err != nil {
+		return err
+	}
+
+	auth, err := rpc.PrepareEthTransaction(ctx, client, account)
+	if err != nil {
+		return errors.Wrapf(err, "prepare ethereum transaction")
+	}
+	tx, err := contract.Caller.UnlockDisputeFee(auth, disputeId)
+	if err != nil {
+		return errors.Wrapf(err, "submit unlock fee transaction")
+	}
+	level.Info(logger).Log("msg", "unlock fee submitted with transaction", "tx", tx.Hash().Hex())
+	return nil
+}
+
+// ShowDispute logs all details of a dispute and its indexed votes.
+func ShowDispute(ctx context.Context, logger log.Logger, contract *contracts.Tellor, source events.Source, disputeId *big.Int) error {
+	info, err := GetDisputeInfo(contract, disputeId)
+	if err != nil {
+		return err
+	}
+	status := "in progress"
+	if info.Executed {
+		status = "complete, rejected"
+		if info.VotePassed {
+			status = "complete, successful"
+		}
+	}
+	// The tally is signed, positive when the votes support the dispute.
+	tally := util.FormatERC20Balance(new(big.Int).Abs(info.Tally))
+	if info.Tally.Sign() < 0 {
+		tally = "-" + tally
+	}
+	tallyDate := "not tallied"
+	if !info.TallyDate.IsZero() {
+		tallyDate = info.TallyDate.Format(time.RFC3339)
+	}
+	level.Info(logger).Log(
+		"msg", "dispute",
+		"disputeId", info.ID.String(),
+		"hash", common.Hash(info.Hash).Hex(),
+		"status", status,
+		"requestId", info.RequestID.String(),
+		"timestamp", info.Timestamp.String(),
+		"value", info.Value.String(),
+		"reportedMiner", info.ReportedMiner.Hex(),
+		"reportingParty", info.ReportingParty.Hex(),
+		"minerSlot", info.MinerSlot.String(),
+		"blockNumber", info.BlockNumber.String(),
+		"votingEnds", info.MinExecutionDate.Format(time.RFC3339),
+		"votes", info.NumberOfVotes.String(),
+		"tally", tally,
+		"quorum", util.FormatERC20Balance(info.Quorum),
+		"tallyDate", tallyDate,
+		"fee", util.FormatERC20Balance(info.Fee),
+		"feePaid", info.FeePaid,
+		"isPropFork", info.IsPropFork,
+		"proposedForkAddress", info.ProposedForkAddress.Hex(),
+	)
+	if err := showVotes(ctx, logger, contract, source, info); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := canTally(info, now); err == nil {
+		level.Info(logger).Log("msg", "the votes can be tallied with `telliot dispute tally`")
+	}
+	if err := canUnlockFee(info, now); err == nil {
+		level.Info(logger).Log("msg", "the fee can be unlocked with `telliot dispute unlock-fee`")
+	}
+	return nil
+}
+
+// showVotes logs the indexed votes of a dispute.
+func showVotes(ctx context.Context, logger log.Logger, contract *contracts.Tellor, source events.Source, info *DisputeInfo) error {
+	tokenAbi, err := abi.JSON(strings.NewReader(master.TellorDisputeABI))
+	if err != nil {
+		return errors.Wrap(err, "parse abi")
+	}
+	bar := bind.NewBoundContract(contract.Address, tokenAbi, nil, nil, nil)
+
+	// Votes can only happen after the disputed value.
+	votes, err := source.Events(ctx, events.Voted, info.BlockNumber.Uint64(), 0)
+	if err != nil {
+		return errors.Wrap(err, "get vote events")
+	}
+	disputeTopic := common.BigToHash(info.ID)
+	for _, e := range votes {
+		if len(e.Log.Topics) < 2 || e.Log.Topics[1] != disputeTopic {
+			continue
+		}
+		vote := master.TellorDisputeVoted{}
+		if err := bar.UnpackLog(&vote, "Voted", e.Log); err != nil {
+			return errors.Wrap(err, "unpack vote event")
+		}
+		level.Info(logger).Log(
+			"msg", "vote",
+			"voter", vote.Voter.Hex(),
+			"supports", vote.Position,
+			"weight", util.FormatERC20Balance(vote.VoteWeight),
+			"time", e.Time.Format(time.RFC3339),
+			"tx", e.Log.TxHash.Hex(),
+		)
+	}
+	return nil
+}
+
+// nonceSubmissionBlocks is how many blocks before a new value its nonce submissions are looked up.
+const nonceSubmissionBlocks = 1000
+
+// getNonceSubmissions returns the values and times submitted by the five miners of a disputed value.
+// The entry of a miner is nil when its submission isn't found within nonceSubmissionBlocks blocks.
+func getNonceSubmissions(
+	ctx context.Context,
+	source events.Source,
+	contract *contracts.Tellor,
 	valueBlock *big.Int,
 	dispute *master.TellorDisputeNewDispute,
 ) ([]*apiOracle.PriceStamp, error) {
@@ -133,64 +382,67 @@ func getNonceSubmissions(
 		return nil, errors.Wrap(err, "get miner addresses for dispute")
 	}
 
-	const blockStep = 100
-	high := int64(valueBlock.Uint64())
-	low := high - blockStep
-	nonceSubmitID := tokenAbi.Events["NonceSubmitted"].ID
+	high := valueBlock.Uint64()
+	var low uint64
+	if high > nonceSubmissionBlocks {
+		low = high - nonceSubmissionBlocks
+	}
+	nonces, err := source.Events(ctx, events.NonceSubmitted, low, high)
+	if err != nil {
+		return nil, errors.Wrap(err, "get nonce events")
+	}
+
 	timedValues := make([]*apiOracle.PriceStamp, 5)
 	found := 0
-	for found < 5 {
-		query := ethereum.FilterQuery{
-			FromBlock: big.NewInt(low),
-			ToBlock:   big.NewInt(high),
-			Addresses: []common.Address{contract.Address},
-			Topics:    [][]common.Hash{{nonceSubmitID}},
+	// The latest submission of each miner before the new value is the one included in it.
+	for n := len(nonces) - 1; n >= 0 && found < 5; n-- {
+		nonceSubmit := master.TellorLibraryNonceSubmitted{}
+		if err := bar.UnpackLog(&nonceSubmit, "NonceSubmitted", nonces[n].Log); err != nil {
+			return nil, errors.Wrap(err, "unpack into object")
 		}
-
-		logs, err := client.FilterLogs(ctx, query)
-		if err != nil {
-			return nil, errors.Wrap(err, "get nonce logs")
+		if !containsRequestID(nonceSubmit.RequestId, dispute.RequestId) {
+			continue
 		}
-
-		for _, l := range logs {
-			nonceSubmit := master.TellorLibraryNonceSubmitted{}
-			err := bar.UnpackLog(&nonceSubmit, "NonceSubmitted", l)
-			if err != nil {
-				return nil, errors.Wrap(err, "unpack into object")
-			}
-			header, err := client.HeaderByNumber(ctx, big.NewInt(int64(l.BlockNumber)))
-			if err != nil {
-				return nil, errors.Wrap(err, "get nonce block header")
-			}
-			for i := 0; i < 5; i++ {
-				if nonceSubmit.Miner == allAddrs[i] {
-					valTime := time.Unix(int64(header.Time), 0)
-
-					bigF := new(big.Float)
-					bigF.SetInt(allVals[i])
-					f, _ := bigF.Float64()
-
-					timedValues[i] = &apiOracle.PriceStamp{
-						Created:   valTime,
-						PriceInfo: apiOracle.PriceInfo{Price: f},
-					}
-					found++
-					break
+		for i := 0; i < 5; i++ {
+			if timedValues[i] == nil && nonceSubmit.Miner == allAddrs[i] {
+				bigF := new(big.Float)
+				bigF.SetInt(allVals[i])
+				f, _ := bigF.Float64()
+
+				timedValues[i] = &apiOracle.PriceStamp{
+					Created:   nonces[n].Time,
+					PriceInfo: apiOracle.PriceInfo{Price: f},
 				}
+				found++
+				break
 			}
 		}
-		high -= blockStep
-		low = high - blockStep
 	}
 	return timedValues, nil
 }
 
+func containsRequestID(ids [5]*big.Int, id *big.Int) bool {
+	for _, i := range ids {
+		if i != nil && i.Cmp(id) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// listBlocks is how many blocks back List shows the disputes.
+const listBlocks = 10e3 * 14
+
+// List shows the disputes of the last weeks with a vote recommendation.
+// With a policy it also votes on the open disputes according to the recommendation.
 func List(
 	ctx context.Context,
 	logger log.Logger,
 	client contracts.ETHClient,
 	contract *contracts.Tellor,
 	account *rpc.Account,
+	source events.Source,
+	policy *tracker.DisputePolicy,
 ) error {
 	cfg := config.GetConfig()
 	tokenAbi, err := abi.JSON(strings.NewReader(master.TellorDisputeABI))
@@ -206,25 +458,19 @@ func List(
 		return errors.Wrap(err, "get latest eth block header")
 	}
 
-	startBlock := big.NewInt(10e3 * 14)
-	startBlock.Sub(header.Number, startBlock)
-	newDisputeID := tokenAbi.Events["NewDispute"].ID
-	query := ethereum.FilterQuery{
-		FromBlock: startBlock,
-		ToBlock:   nil,
-		Addresses: []common.Address{contract.Address},
-		Topics:    [][]common.Hash{{newDisputeID}},
+	var startBlock uint64
+	if head := header.Number.Uint64(); head > listBlocks {
+		startBlock = head - listBlocks
 	}
-
-	logs, err := client.FilterLogs(ctx, query)
+	disputes, err := source.Events(ctx, events.NewDispute, startBlock, 0)
 	if err != nil {
-		return errors.Wrap(err, "filter eth logs")
+		return errors.Wrap(err, "get dispute events")
 	}
 
-	level.Info(logger).Log("msg", "get currently open disputes", "open", len(logs))
-	for _, rawDispute := range logs {
+	level.Info(logger).Log("msg", "get currently open disputes", "open", len(disputes))
+	for _, rawDispute := range disputes {
 		dispute := master.TellorDisputeNewDispute{}
-		err := bar.UnpackLog(&dispute, "NewDispute", rawDispute)
+		err := bar.UnpackLog(&dispute, "NewDispute", rawDispute.Log)
 		if err != nil {
 			return errors.Wrap(err, "unpack dispute event from logs")
 		}
@@ -258,14 +504,22 @@ func List(
 			"requestId", dispute.RequestId.Uint64(),
 		)
 
-		allSubmitted, err := getNonceSubmissions(ctx, client, contract, uintVars[5], &dispute)
+		allSubmitted, err := getNonceSubmissions(ctx, source, contract, uintVars[5], &dispute)
 		if err != nil {
 			return errors.Wrapf(err, "get the values submitted by other miners for the disputed block")
 		}
-		disputedValTime := allSubmitted[uintVars[6].Uint64()].Created
+		disputed := allSubmitted[uintVars[6].Uint64()]
+		if disputed == nil {
+			level.Info(logger).Log("msg", "the disputed submission isn't indexed", "block", uintVars[5])
+			continue
+		}
+		disputedValTime := disputed.Created
 
 		for i := len(allSubmitted) - 1; i >= 0; i-- {
 			sub := allSubmitted[i]
+			if sub == nil {
+				continue
+			}
 			valStr := fmt.Sprintf("%f\n", sub.Price)
 			var pointerStr string
 			if i == int(uintVars[6].Uint64()) {
@@ -298,7 +552,7 @@ func List(
 		result, err := tracker.CheckValueAtTime(cfg, dispute.RequestId.Uint64(), uintVars[2], disputedValTime)
 		if err != nil {
 			return err
-		} else if result == nil || len(result.Datapoints) < 0 {
+		} else if result == nil || len(result.Datapoints) == 0 {
 			level.Info(logger).Log("msg", "no data available for recommendation")
 			continue
 		}
@@ -308,6 +562,11 @@ func List(
 			"subValue", uintVars[2].String(),
 			"range", fmt.Sprintf("%.0f to %0.f", result.Low, result.High),
 		)
+		if policy != nil && !executed {
+			if err := policy.VoteOnDispute(ctx, dispute.DisputeId, dispute.RequestId.Uint64(), uintVars[2], result); err != nil {
+				return err
+			}
+		}
 
 		numToShow := 3
 		if numToShow > len(result.Datapoints) {
@@ -0,0 +1,32 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ops
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestDisputePreconditions(t *testing.T) {
+	now := time.Now()
+	info := &DisputeInfo{ID: big.NewInt(1), MinExecutionDate: now.Add(time.Hour)}
+
+	testutil.NotOk(t, canTally(info, now), "voting hasn't ended")
+	testutil.NotOk(t, canUnlockFee(info, now), "the dispute isn't tallied")
+	info.MinExecutionDate = now.Add(-time.Hour)
+	testutil.Ok(t, canTally(info, now))
+
+	info.Executed = true
+	info.TallyDate = now.Add(-time.Hour)
+	testutil.NotOk(t, canTally(info, now), "the dispute is already tallied")
+	testutil.NotOk(t, canUnlockFee(info, now), "the fee is still locked")
+	info.TallyDate = now.Add(-disputeFeeLockPeriod - time.Second)
+	testutil.Ok(t, canUnlockFee(info, now))
+
+	info.FeePaid = true
+	testutil.NotOk(t, canUnlockFee(info, now), "the fee is already paid out")
+}
@@ -9,18 +9,17 @@ import (
 	"math/big"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/alerting"
 	tellorCommon "github.com/tellor-io/telliot/pkg/common"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
@@ -34,6 +33,8 @@ import (
 
 type WorkSource interface {
 	GetWork() (*pow.Work, bool)
+	// Subscribe returns a channel notified on new challenges, nil when they are polled.
+	Subscribe(ctx context.Context) <-chan struct{}
 }
 
 type SolutionSink interface {
@@ -56,6 +57,7 @@ type MiningMgr struct {
 	solHandler      SolutionSink
 	solutionPending *pow.Result
 	database        db.DataServerProxy
+	store           *db.Store
 	contractGetter  *proxy.TellorGetters
 	cfg             *config.Config
 
@@ -66,6 +68,14 @@ type MiningMgr struct {
 	submitProfit    *prometheus.GaugeVec
 	submitCost      *prometheus.GaugeVec
 	submitReward    *prometheus.GaugeVec
+
+	// consecutiveFails counts the failed submissions since the last successful one.
+	consecutiveFails int
+
+	// stake pauses the mining group while solutions can't be submitted.
+	stake     *StakeMonitor
+	paused    bool
+	groupDone chan struct{}
 }
 
 // CreateMiningManager is the MiningMgr constructor.
@@ -110,6 +120,7 @@ func CreateMiningManager(
 		contractGetter:  getter,
 		cfg:             cfg,
 		database:        database,
+		store:           db.NewStore(database),
 		ethClient:       client,
 		toMineInput:     make(chan *pow.Work),
 		solutionOutput:  make(chan *pow.Result),
@@ -151,18 +162,28 @@ func CreateMiningManager(
 		),
 	}
 
+	mng.stake = NewStakeMonitor(logger, database, account.Address)
 	mng.tasker = pow.CreateTasker(logger, cfg, database)
 	mng.solHandler = pow.CreateSolutionHandler(cfg, logger, submitter, database)
 	return mng, nil
 }
 
+// Stake returns the monitor of the stake state that pauses and resumes mining.
+func (mgr *MiningMgr) Stake() *StakeMonitor {
+	return mgr.stake
+}
+
 // Start will start the mining run loop.
 func (mgr *MiningMgr) Start(ctx context.Context) {
 	mgr.Running = true
 	ticker := time.NewTicker(mgr.cfg.MiningInterruptCheckInterval.Duration)
+	// With a remote data server that streams the updates the new challenges are received without waiting for the ticker.
+	challenges := mgr.tasker.Subscribe(ctx)
 
-	// Start the mining group.
-	go mgr.group.Mine(mgr.toMineInput, mgr.solutionOutput)
+	// Start the mining group only when the stake allows submitting.
+	mgr.paused = true
+	mgr.stake.SetPaused(true)
+	mgr.checkStake()
 
 	for {
 		select {
@@ -172,6 +193,9 @@ func (mgr *MiningMgr) Start(ctx context.Context) {
 			return
 		// Found a solution.
 		case solution := <-mgr.solutionOutput:
+			if mgr.paused {
+				continue
+			}
 			// There is no new challenge so resend any pending solution.
 			if solution == nil {
 				if mgr.solutionPending == nil {
@@ -213,8 +237,18 @@ func (mgr *MiningMgr) Start(ctx context.Context) {
 			if err != nil {
 				level.Error(mgr.logger).Log("msg", "submiting a solution", "err", err)
 				mgr.submitFailCount.Inc()
+				mgr.consecutiveFails++
+				if fails := mgr.cfg.Alerting.Rules.SubmitFails; fails > 0 && mgr.consecutiveFails >= fails {
+					alerting.Notify(&alerting.Alert{
+						Rule:     alerting.RuleSubmitFails,
+						Severity: alerting.SeverityCritical,
+						Summary:  fmt.Sprintf("%d consecutive solution submissions failed", mgr.consecutiveFails),
+						Details:  map[string]string{"error": err.Error()},
+					})
+				}
 				continue
 			}
+			mgr.consecutiveFails = 0
 			level.Debug(mgr.logger).Log("msg", "submited a solution", "txHash", tx.Hash().String())
 			mgr.saveGasUsed(ctx, tx)
 			mgr.submitCount.Inc()
@@ -225,11 +259,60 @@ func (mgr *MiningMgr) Start(ctx context.Context) {
 
 		// Time to check for a new challenge.
 		case <-ticker.C:
-			mgr.newWork()
+			if mgr.checkStake() {
+				mgr.newWork()
+			}
+		// The data server streamed a new challenge.
+		case <-challenges:
+			if mgr.checkStake() {
+				mgr.newWork()
+			}
 		}
 	}
 }
 
+// checkStake updates the stake state and pauses the mining group when solutions can't be submitted
+// or resumes it when they can again. It returns whether mining is active.
+func (mgr *MiningMgr) checkStake() bool {
+	state, _, err := mgr.stake.Update()
+	if err != nil {
+		level.Error(mgr.logger).Log("msg", "checking the stake status", "err", err)
+	}
+	if !state.CanMine() {
+		if !mgr.paused {
+			level.Warn(mgr.logger).Log("msg", "pausing mining, solutions can't be submitted", "stakeStatus", state)
+			mgr.paused = true
+			mgr.solutionPending = nil
+			mgr.stake.SetPaused(true)
+			// A nil work stops the mining group.
+			go func() {
+				mgr.toMineInput <- nil
+			}()
+		}
+		return false
+	}
+	if mgr.paused {
+		if mgr.groupDone != nil {
+			select {
+			case <-mgr.groupDone:
+			default:
+				// Still waiting for the hashers to finish their chunks.
+				return false
+			}
+		}
+		level.Info(mgr.logger).Log("msg", "starting mining", "stakeStatus", state)
+		mgr.paused = false
+		mgr.stake.SetPaused(false)
+		done := make(chan struct{})
+		mgr.groupDone = done
+		go func() {
+			mgr.group.Mine(mgr.toMineInput, mgr.solutionOutput)
+			close(done)
+		}()
+	}
+	return true
+}
+
 // newWork is non blocking worker that sends new work to the pow workers
 // or re-sends a current pending solution to the submitter when the challenge hasn't changes.
 func (mgr *MiningMgr) newWork() {
@@ -258,25 +341,16 @@ func (mgr *MiningMgr) newWork() {
 }
 
 func (mgr *MiningMgr) lastSubmit() (time.Duration, error) {
-	fromAddress := common.HexToAddress(mgr.cfg.PublicAddress)
-	pubKey := strings.ToLower(fromAddress.Hex())
-
-	address := common.HexToAddress(pubKey)
-	dbKey := fmt.Sprintf("%s-%s", strings.ToLower(address.Hex()), db.TimeOutKey)
-	last, err := mgr.database.Get(dbKey)
+	last, updated, err := mgr.store.GetMinerTimeOut(common.HexToAddress(mgr.cfg.PublicAddress))
 	if err != nil {
 		return time.Duration(0), errors.Wrapf(err, "timeout retrieval error")
 	}
-	lastDecoded, err := hexutil.DecodeBig(string(last))
-	if err != nil {
-		return time.Duration(0), errors.Wrapf(err, "timeout key decode last:%v", last)
+	if last.IsZero() && updated.IsZero() {
+		return time.Duration(0), errors.New("the db doesn't have the last submit time")
 	}
-	lastInt := lastDecoded.Int64()
-	now := time.Now()
 	var lastSubmit time.Duration
-	if lastInt > 0 {
-		tm := time.Unix(lastInt, 0)
-		lastSubmit = now.Sub(tm)
+	if !last.IsZero() {
+		lastSubmit = time.Since(last)
 	}
 
 	return lastSubmit, nil
@@ -312,17 +386,14 @@ func (mgr *MiningMgr) currentReward() (*big.Int, error) {
 }
 
 func (mgr *MiningMgr) convertTRBtoETH(trb *big.Int) (*big.Int, error) {
-	val, err := mgr.database.Get(db.QueriedValuePrefix + strconv.Itoa(tracker.RequestID_TRB_ETH))
+	val, _, err := mgr.store.GetPSRValue(uint64(tracker.RequestID_TRB_ETH))
 	if err != nil {
-		return nil, errors.New("getting the trb price from the db")
+		return nil, errors.Wrap(err, "getting the trb price from the db")
 	}
-	if len(val) == 0 {
+	if val == nil {
 		return nil, errors.New("the db doesn't have the trb price")
 	}
-	priceTRB, err := hexutil.DecodeBig(string(val))
-	if err != nil {
-		return nil, errors.New("decoding trb price from the db")
-	}
+	priceTRB := val.Value
 	wei := big.NewInt(tellorCommon.WEI)
 	precisionUpscale := big.NewInt(0).Div(wei, big.NewInt(tracker.PSRs[tracker.RequestID_TRB_ETH].Granularity()))
 	priceTRB.Mul(priceTRB, precisionUpscale)
@@ -342,17 +413,16 @@ func (mgr *MiningMgr) gasUsed() (*big.Int, *big.Int, error) {
 	// Slots numbers should be from 0 to 4 so
 	// use mod of 5 in order to save 5 as slot 0.
 	slotNum.Add(slotNum, big.NewInt(1)).Mod(slotNum, big.NewInt(5))
-	txID := tellorCommon.PriceTXs + slotNum.String()
-	gas, err := mgr.database.Get(txID)
+	gas, _, err := mgr.store.GetGasUsed(slotNum)
 	if err != nil {
-		return nil, nil, errors.New("getting the tx eth cost from the db")
+		return nil, nil, errors.Wrap(err, "getting the tx eth cost from the db")
 	}
 	// No price record in the db yet.
 	if gas == nil {
 		return big.NewInt(0), slotNum, nil
 	}
 
-	return big.NewInt(0).SetBytes(gas), slotNum, nil
+	return gas, slotNum, nil
 }
 
 // saveGasUsed calculates the price for a given slot.
@@ -383,8 +453,7 @@ func (mgr *MiningMgr) saveGasUsed(ctx context.Context, tx *types.Transaction) {
 			level.Error(mgr.logger).Log("msg", "getting slotProgress for calculating transaction cost", "err", err)
 		}
 
-		txID := tellorCommon.PriceTXs + slotNum.String()
-		err = mgr.database.Put(txID, gasUsed.Bytes())
+		err = mgr.store.SetGasUsed(slotNum, gasUsed)
 		if err != nil {
 			level.Error(mgr.logger).Log("msg", "saving transaction cost", "err", err)
 		}
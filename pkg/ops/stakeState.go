@@ -0,0 +1,162 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ops
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/tracker"
+)
+
+// StakeTransition is a change of the stake state.
+type StakeTransition struct {
+	From tracker.StakeState `json:"from"`
+	To   tracker.StakeState `json:"to"`
+	Time time.Time          `json:"time"`
+}
+
+// maxStakeTransitions is how many of the last transitions are kept.
+const maxStakeTransitions = 20
+
+// StakeMonitor tracks the transitions of the stake state of the miner address
+// from the staker status saved in the DB by the dispute status tracker.
+type StakeMonitor struct {
+	logger  log.Logger
+	store   *db.Store
+	address common.Address
+
+	mtx         sync.Mutex
+	state       tracker.StakeState
+	since       time.Time
+	paused      bool
+	transitions []StakeTransition
+
+	status          prometheus.Gauge
+	pausedGauge     prometheus.Gauge
+	transitionCount *prometheus.CounterVec
+}
+
+// NewStakeMonitor creates a monitor in the unknown state.
+func NewStakeMonitor(logger log.Logger, proxy db.DataServerProxy, address common.Address) *StakeMonitor {
+	return &StakeMonitor{
+		logger:  log.With(logger, "component", ComponentName),
+		store:   db.NewStore(proxy),
+		address: address,
+		state:   tracker.StakeUnknown,
+		since:   time.Now(),
+		status: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "telliot",
+			Subsystem: "mining",
+			Name:      "stake_status",
+			Help:      "The staker status of the account, 1 is staked, 2 locked for withdraw and 3 in dispute",
+		}),
+		pausedGauge: promauto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "telliot",
+			Subsystem: "mining",
+			Name:      "paused",
+			Help:      "Whether mining is paused because solutions can't be submitted with the current stake status",
+		}),
+		transitionCount: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "telliot",
+			Subsystem: "mining",
+			Name:      "stake_transitions_total",
+			Help:      "The total number of stake status transitions",
+		},
+			[]string{"from", "to"},
+		),
+	}
+}
+
+// Update reads the staker status of the miner address from the DB and records any transition.
+// It returns the current state and whether it changed.
+func (m *StakeMonitor) Update() (tracker.StakeState, bool, error) {
+	status, _, err := m.store.GetMinerDisputeStatus(m.address)
+	if err != nil {
+		return m.State(), false, errors.Wrap(err, "getting the stake status")
+	}
+	if status == nil {
+		return m.State(), false, nil
+	}
+	return m.set(stakeStateOf(status), time.Now())
+}
+
+func stakeStateOf(status *big.Int) tracker.StakeState {
+	if !status.IsInt64() {
+		return tracker.StakeUnknown
+	}
+	return tracker.StakeState(status.Int64())
+}
+
+func (m *StakeMonitor) set(state tracker.StakeState, now time.Time) (tracker.StakeState, bool, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.status.Set(float64(state))
+	if state == m.state {
+		return state, false, nil
+	}
+	level.Info(m.logger).Log("msg", "stake status changed", "from", m.state, "to", state)
+	m.transitionCount.WithLabelValues(m.state.String(), state.String()).Inc()
+	m.transitions = append(m.transitions, StakeTransition{From: m.state, To: state, Time: now})
+	if len(m.transitions) > maxStakeTransitions {
+		m.transitions = m.transitions[len(m.transitions)-maxStakeTransitions:]
+	}
+	m.state = state
+	m.since = now
+	return state, true, nil
+}
+
+// State returns the current stake state.
+func (m *StakeMonitor) State() tracker.StakeState {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.state
+}
+
+// SetPaused records whether mining is paused.
+func (m *StakeMonitor) SetPaused(paused bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.paused = paused
+	if paused {
+		m.pausedGauge.Set(1)
+	} else {
+		m.pausedGauge.Set(0)
+	}
+}
+
+// ServeHTTP responds with the stake state, whether mining is paused and the last transitions as JSON.
+func (m *StakeMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mtx.Lock()
+	resp := struct {
+		State       tracker.StakeState `json:"state"`
+		Status      int64              `json:"status"`
+		Since       time.Time          `json:"since"`
+		Paused      bool               `json:"paused"`
+		Transitions []StakeTransition  `json:"transitions"`
+	}{
+		State:       m.state,
+		Status:      int64(m.state),
+		Since:       m.since,
+		Paused:      m.paused,
+		Transitions: append([]StakeTransition{}, m.transitions...),
+	}
+	m.mtx.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		level.Error(m.logger).Log("msg", "encode stake state", "err", err)
+	}
+}
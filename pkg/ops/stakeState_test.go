@@ -0,0 +1,104 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package ops
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/rest"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"github.com/tellor-io/telliot/pkg/tracker"
+)
+
+// openTestRemote returns a remote proxy to a data server with the given local proxy.
+func openTestRemote(t *testing.T, cfg *config.Config, proxy db.DataServerProxy) db.DataServerProxy {
+	logger := logging.NewLogger()
+	router, err := rest.CreateRemoteProxy(logger, cfg, context.Background(), proxy)
+	testutil.Ok(t, err)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	srvHost, srvPort, err := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(srvPort)
+	testutil.Ok(t, err)
+	host, port := cfg.Mine.RemoteDBHost, cfg.Mine.RemoteDBPort
+	t.Cleanup(func() { cfg.Mine.RemoteDBHost, cfg.Mine.RemoteDBPort = host, port })
+	cfg.Mine.RemoteDBHost = srvHost
+	cfg.Mine.RemoteDBPort = uint(p)
+	remote, err := db.OpenRemote(logger, cfg, nil)
+	testutil.Ok(t, err)
+	return remote
+}
+
+func TestStakeMonitor(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	address := common.HexToAddress(cfg.PublicAddress)
+	cfg.ServerWhitelist = []string{address.Hex()}
+	t.Cleanup(func() { cfg.ServerWhitelist = nil })
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logger, cfg, DB)
+	testutil.Ok(t, err)
+
+	// A remote miner follows its own status and not the status of the data server account.
+	m := NewStakeMonitor(logger, openTestRemote(t, cfg, proxy), address)
+	store := db.NewStore(proxy)
+	testutil.Ok(t, store.SetDisputeStatus(big.NewInt(2)))
+
+	// Without a status the state stays unknown.
+	state, changed, err := m.Update()
+	testutil.Ok(t, err)
+	testutil.Equals(t, tracker.StakeUnknown, state)
+	testutil.Assert(t, !changed, "expected no transition without a status")
+
+	for _, tc := range []struct {
+		status   int64
+		expected tracker.StakeState
+		changed  bool
+		canMine  bool
+	}{
+		{status: 1, expected: tracker.StakeStaked, changed: true, canMine: true},
+		{status: 1, expected: tracker.StakeStaked, changed: false, canMine: true},
+		{status: 3, expected: tracker.StakeInDispute, changed: true, canMine: false},
+		{status: 1, expected: tracker.StakeStaked, changed: true, canMine: true},
+		{status: 2, expected: tracker.StakeLockedForWithdraw, changed: true, canMine: false},
+	} {
+		testutil.Ok(t, store.SetMinerDisputeStatus(address, big.NewInt(tc.status)))
+		state, changed, err := m.Update()
+		testutil.Ok(t, err)
+		testutil.Equals(t, tc.expected, state)
+		testutil.Equals(t, tc.changed, changed)
+		testutil.Equals(t, tc.canMine, state.CanMine())
+	}
+	m.SetPaused(true)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/stake", nil))
+	var resp struct {
+		State       string
+		Status      int64
+		Paused      bool
+		Transitions []struct{ From, To string }
+	}
+	testutil.Ok(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	testutil.Equals(t, "locked for withdraw", resp.State)
+	testutil.Equals(t, int64(2), resp.Status)
+	testutil.Assert(t, resp.Paused, "expected mining to be paused")
+	testutil.Equals(t, 4, len(resp.Transitions))
+	testutil.Equals(t, "unknown", resp.Transitions[0].From)
+	testutil.Equals(t, "in dispute", resp.Transitions[1].To)
+}
@@ -5,22 +5,21 @@ package pow
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
 	"math"
 	"math/big"
 	"math/rand"
-	"os"
-	"path/filepath"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/tracker"
 )
 
 const (
@@ -46,95 +45,184 @@ const (
 type MiningTasker struct {
 	logger        log.Logger
 	proxy         db.DataServerProxy
+	store         *db.Store
+	cache         *streamCache
 	pubKey        string
+	address       common.Address
 	currChallenge *MiningChallenge
+	// workMtx serializes GetWork as it runs on the new challenges and on the interrupt checks.
+	workMtx sync.Mutex
 }
 
-func CreateTasker(logger log.Logger, cfg *config.Config, proxy db.DataServerProxy) *MiningTasker {
+// streamRetry is how long to wait before reconnecting a broken stream.
+const streamRetry = 5 * time.Second
 
+func CreateTasker(logger log.Logger, cfg *config.Config, proxy db.DataServerProxy) *MiningTasker {
+	cache := &streamCache{DataServerProxy: proxy}
 	return &MiningTasker{
-		proxy:  proxy,
-		pubKey: "0x" + cfg.PublicAddress,
-		logger: log.With(logger, "component", ComponentName),
+		proxy:   proxy,
+		store:   db.NewStore(cache),
+		cache:   cache,
+		pubKey:  "0x" + cfg.PublicAddress,
+		address: common.HexToAddress(cfg.PublicAddress),
+		logger:  log.With(logger, "component", ComponentName),
 	}
 }
 
-func (mt *MiningTasker) GetWork() (*Work, bool) {
-	dispKey := mt.pubKey + "-" + db.DisputeStatusKey
-	keys := []string{
+func (mt *MiningTasker) workKeys() []string {
+	return []string{
 		db.DifficultyKey,
 		db.CurrentChallengeKey,
-		db.RequestIdKey,
+		db.TotalTipKey,
 		db.RequestIdKey0,
 		db.RequestIdKey1,
 		db.RequestIdKey2,
 		db.RequestIdKey3,
 		db.RequestIdKey4,
 		db.LastNewValueKey,
-		dispKey,
-		db.LastSubmissionKey,
+		db.MinerKey(mt.address, db.DisputeStatusKey),
 	}
+}
 
-	m, err := mt.proxy.BatchGet(keys)
-	if err != nil {
-		level.Error(mt.logger).Log("msg", "get data from data proxy, cannot continue at all")
-		return nil, false
+// Subscribe streams the values of the challenge from a remote data server
+// and returns a channel notified when the challenge changes.
+// It returns nil when the data server doesn't stream the updates so the values are polled.
+func (mt *MiningTasker) Subscribe(ctx context.Context) <-chan struct{} {
+	keys := append(mt.workKeys(), db.QueriedValuePrefix)
+	stream, err := mt.proxy.Stream(ctx, keys)
+	if err == db.ErrStreamUnsupported {
+		level.Info(mt.logger).Log("msg", "the data server doesn't stream updates, polling for new challenges")
+		return nil
 	}
+	changed := make(chan struct{}, 1)
+	go func() {
+		for {
+			if err != nil {
+				level.Warn(mt.logger).Log("msg", "connecting to the data server stream, polling until connected", "err", err)
+			} else {
+				level.Info(mt.logger).Log("msg", "streaming the challenge from the data server")
+				mt.cache.consume(stream, changed)
+				level.Warn(mt.logger).Log("msg", "the data server stream closed, polling until reconnected")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamRetry):
+			}
+			stream, err = mt.proxy.Stream(ctx, keys)
+			if err == db.ErrStreamUnsupported {
+				level.Info(mt.logger).Log("msg", "the data server no longer streams updates, polling for new challenges")
+				return
+			}
+		}
+	}()
+	return changed
+}
 
-	level.Debug(mt.logger).Log("msg", "received data", "data", m)
+// streamCache serves the values streamed from a remote data server
+// and requests only the missing ones from the data server.
+type streamCache struct {
+	db.DataServerProxy
 
-	if mt.checkDispute(m[dispKey]) == statusWaitNext {
-		return nil, false
-	}
-	diff, stat := mt.getInt(m[db.DifficultyKey])
-	if stat == statusWaitNext || stat == statusFailure {
-		return nil, false
-	}
-	var reqIDs [5]*big.Int
+	// The streamed values, nil while polling.
+	mtx    sync.Mutex
+	values map[string][]byte
+}
 
-	l, _ := mt.getInt(m[db.LastNewValueKey])
-	instantSubmit := false
+// consume caches the streamed values until the stream closes.
+func (c *streamCache) consume(stream <-chan map[string][]byte, changed chan struct{}) {
+	c.mtx.Lock()
+	c.values = make(map[string][]byte)
+	c.mtx.Unlock()
+	defer func() {
+		c.mtx.Lock()
+		c.values = nil
+		c.mtx.Unlock()
+	}()
 
-	today := time.Now()
-	tm := time.Unix(l.Int64(), 0)
-	level.Debug(mt.logger).Log("msg", "since last value", "time", today.Sub(tm))
-	if today.Sub(tm) >= time.Duration(15)*time.Minute {
-		instantSubmit = true
+	for update := range stream {
+		challenge := false
+		c.mtx.Lock()
+		for k, v := range update {
+			c.values[k] = v
+			if !strings.HasPrefix(k, db.QueriedValuePrefix) {
+				challenge = true
+			}
+		}
+		c.mtx.Unlock()
+		if challenge {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
 	}
+}
 
-	r, stat := mt.getInt(m[db.RequestIdKey0])
-	if stat == statusWaitNext || stat == statusFailure {
-		return nil, false
+func (c *streamCache) BatchGet(keys []string) (map[string][]byte, error) {
+	c.mtx.Lock()
+	if c.values == nil {
+		c.mtx.Unlock()
+		return c.DataServerProxy.BatchGet(keys)
+	}
+	m := make(map[string][]byte)
+	var missing []string
+	for _, k := range keys {
+		if v, ok := c.values[k]; ok {
+			m[k] = v
+		} else {
+			missing = append(missing, k)
+		}
 	}
-	reqIDs[0] = r
+	c.mtx.Unlock()
+	if len(missing) == 0 {
+		return m, nil
+	}
+	m2, err := c.DataServerProxy.BatchGet(missing)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range m2 {
+		m[k] = v
+	}
+	return m, nil
+}
+
+func (mt *MiningTasker) GetWork() (*Work, bool) {
+	mt.workMtx.Lock()
+	defer mt.workMtx.Unlock()
 
-	r, stat = mt.getInt(m[db.RequestIdKey1])
-	if stat == statusWaitNext || stat == statusFailure {
+	disputed, _, err := mt.store.GetMinerDisputeStatus(mt.address)
+	if err != nil {
+		level.Error(mt.logger).Log("msg", "get data from data proxy, cannot continue at all", "err", err)
 		return nil, false
 	}
-	reqIDs[1] = r
-
-	r, stat = mt.getInt(m[db.RequestIdKey2])
-	if stat == statusWaitNext || stat == statusFailure {
+	if mt.checkDispute(disputed) == statusWaitNext {
 		return nil, false
 	}
-	reqIDs[2] = r
-
-	r, stat = mt.getInt(m[db.RequestIdKey3])
-	if stat == statusWaitNext || stat == statusFailure {
+	challenge, updated, err := mt.store.GetChallenge()
+	if err != nil {
+		level.Error(mt.logger).Log("msg", "get the challenge from data proxy", "err", err)
 		return nil, false
 	}
-	reqIDs[3] = r
+	if challenge == nil {
+		return nil, false
+	}
+	level.Debug(mt.logger).Log("msg", "received challenge", "challenge", fmt.Sprintf("%x", challenge.Challenge), "updated", updated)
 
-	r, stat = mt.getInt(m[db.RequestIdKey4])
-	if stat == statusWaitNext || stat == statusFailure {
+	last, _, err := mt.store.GetLastNewValue()
+	if err != nil {
+		level.Error(mt.logger).Log("msg", "get the time of the last value from data proxy", "err", err)
 		return nil, false
 	}
-	reqIDs[4] = r
+	instantSubmit := false
+	level.Debug(mt.logger).Log("msg", "since last value", "time", time.Since(last))
+	if time.Since(last) >= time.Duration(15)*time.Minute {
+		instantSubmit = true
+	}
 
-	for i := 0; i < 5; i++ {
-		valKey := fmt.Sprintf("%s%d", db.QueriedValuePrefix, reqIDs[i].Uint64())
-		m2, err := mt.proxy.BatchGet([]string{valKey})
+	for _, id := range challenge.RequestIDs {
+		val, updated, err := mt.store.GetPSRValue(id.Uint64())
 		if err != nil {
 			level.Info(mt.logger).Log(
 				"msg", "retrieve pricing data for current request id",
@@ -142,35 +230,25 @@ func (mt *MiningTasker) GetWork() (*Work, bool) {
 			)
 			//return nil, false
 		}
-		val := m2[valKey]
-		if len(val) == 0 {
-			cfg := config.GetConfig()
-			indexPath := filepath.Join(cfg.ConfigFolder, "manualData.json")
-			jsonFile, err := os.Open(indexPath)
-			if err != nil {
-				return nil, false
-			}
-			defer jsonFile.Close()
-			byteValue, _ := ioutil.ReadAll(jsonFile)
-			var result map[string]map[string]uint
-			_ = json.Unmarshal([]byte(byteValue), &result)
-			_id := strconv.FormatUint(reqIDs[i].Uint64(), 10)
-			val := result[_id]["VALUE"]
-			if val == 0 {
+		if val == nil {
+			if _, err := tracker.GetManualValue(id.Uint64(), time.Now()); err != nil {
 				level.Info(mt.logger).Log(
 					"msg", "pricing data not available for request",
-					"request", reqIDs[i].Uint64(),
+					"request", id.Uint64(),
+					"err", err,
 				)
 				return nil, false
 			}
 			level.Info(mt.logger).Log("msg", "USING MANUALLY ENTERED VALUE!!!! USE CAUTION")
+			continue
 		}
+		level.Debug(mt.logger).Log("msg", "pricing data", "request", id.Uint64(), "confidence", val.Confidence, "updated", updated)
 	}
 
 	newChallenge := &MiningChallenge{
-		Challenge:  m[db.CurrentChallengeKey],
-		Difficulty: diff,
-		RequestIDs: reqIDs,
+		Challenge:  challenge.Challenge,
+		Difficulty: challenge.Difficulty,
+		RequestIDs: challenge.RequestIDs,
 	}
 
 	// If this chalange is already sent out, don't do it again.
@@ -181,13 +259,10 @@ func (mt *MiningTasker) GetWork() (*Work, bool) {
 	return &Work{Challenge: newChallenge, PublicAddr: mt.pubKey[2:], Start: uint64(rand.Int63()), N: math.MaxInt64}, instantSubmit
 }
 
-func (mt *MiningTasker) checkDispute(disp []byte) int {
-	disputed, stat := mt.getInt(disp)
-	if stat == statusWaitNext || stat == statusFailure {
-		if stat == statusWaitNext {
-			level.Info(mt.logger).Log("msg", "no dispute results from data server, waiting for next cycle")
-		}
-		return stat
+func (mt *MiningTasker) checkDispute(disputed *big.Int) int {
+	if disputed == nil {
+		level.Info(mt.logger).Log("msg", "no dispute results from data server, waiting for next cycle")
+		return statusWaitNext
 	}
 
 	if disputed.Cmp(big.NewInt(1)) != 0 {
@@ -197,16 +272,3 @@ func (mt *MiningTasker) checkDispute(disp []byte) int {
 	level.Debug(mt.logger).Log("msg", "miner is not in dispute, continuing")
 	return statusSuccess
 }
-
-func (mt *MiningTasker) getInt(data []byte) (*big.Int, int) {
-	if len(data) == 0 {
-		return nil, statusWaitNext
-	}
-
-	val, err := hexutil.DecodeBig(string(data))
-	if err != nil {
-		level.Error(mt.logger).Log("msg", "decoding int", "err", err)
-		return nil, statusFailure
-	}
-	return val, statusSuccess
-}
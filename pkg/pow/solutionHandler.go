@@ -5,18 +5,11 @@ package pow
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
 	"math/big"
-	"os"
-	"path/filepath"
-	"strconv"
+	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	tellorCommon "github.com/tellor-io/telliot/pkg/common"
 	"github.com/tellor-io/telliot/pkg/config"
@@ -33,6 +26,7 @@ import (
 type SolutionHandler struct {
 	logger           log.Logger
 	proxy            db.DataServerProxy
+	store            *db.Store
 	currentChallenge *MiningChallenge
 	currentNonce     string
 	currentValues    [5]*big.Int
@@ -43,6 +37,7 @@ func CreateSolutionHandler(cfg *config.Config, logger log.Logger, submitter tell
 
 	return &SolutionHandler{
 		proxy:     proxy,
+		store:     db.NewStore(proxy),
 		submitter: submitter,
 		logger:    log.With(logger, "component", ComponentName),
 	}
@@ -55,46 +50,18 @@ func (s *SolutionHandler) Submit(ctx context.Context, result *Result) (*types.Tr
 	s.currentNonce = nonce
 
 	for i := 0; i < 5; i++ {
-		valKey := fmt.Sprintf("%s%d", db.QueriedValuePrefix, challenge.RequestIDs[i].Uint64())
-		m, err := s.proxy.BatchGet([]string{valKey})
+		val, _, err := s.store.GetPSRValue(challenge.RequestIDs[i].Uint64())
 		if err != nil {
-			return nil, errors.Wrapf(err, "retrieve pricing data for current request id")
+			return nil, errors.Wrapf(err, "retrieve pricing data for current request id:%v", challenge.RequestIDs[i].Uint64())
 		}
-		val := m[valKey]
 		var value *big.Int
-		if len(val) == 0 {
-			cfg := config.GetConfig()
-			indexPath := filepath.Join(cfg.ConfigFolder, "manualData.json")
-			jsonFile, err := os.Open(indexPath)
+		if val == nil {
+			value, err = tracker.GetManualValue(challenge.RequestIDs[i].Uint64(), time.Now())
 			if err != nil {
-				return nil, errors.Wrapf(err, "manualData read Error")
+				return nil, errors.Wrap(err, "retrieve pricing data for current request id")
 			}
-			defer jsonFile.Close()
-			byteValue, _ := ioutil.ReadAll(jsonFile)
-			var result map[string]map[string]uint
-			_ = json.Unmarshal([]byte(byteValue), &result)
-			_id := strconv.FormatUint(challenge.RequestIDs[i].Uint64(), 10)
-			val := result[_id]["VALUE"]
-			if val == 0 {
-				return nil, errors.Errorf("retrieve pricing data for current request id")
-			}
-			value = big.NewInt(int64(val))
 		} else {
-			value, err = hexutil.DecodeBig(string(val))
-			if err != nil {
-				if challenge.RequestIDs[i].Uint64() > tracker.MaxPSRID() {
-					level.Error(s.logger).Log(
-						"msg", "decoding price value prior to submitt ing solution",
-						"err", err,
-					)
-					if len(val) == 0 {
-						level.Error(s.logger).Log("msg", "0 value being submitted")
-						s.currentValues[i] = big.NewInt(0)
-					}
-					continue
-				}
-				return nil, errors.Errorf("no value in database,  reg id:%v", challenge.RequestIDs[i].Uint64())
-			}
+			value = val.Value
 		}
 		s.currentValues[i] = value
 	}
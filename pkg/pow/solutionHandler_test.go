@@ -0,0 +1,66 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package pow
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	tellorCommon "github.com/tellor-io/telliot/pkg/common"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+	"github.com/tellor-io/telliot/pkg/tracker"
+)
+
+type testSubmitter struct{}
+
+func (testSubmitter) Submit(ctx context.Context, proxy db.DataServerProxy, ctxName string, factoryFn tellorCommon.TransactionGeneratorFN) (*types.Transaction, error) {
+	return nil, nil
+}
+
+func TestSubmitManualValue(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	cfg.ConfigFolder = t.TempDir()
+	path := filepath.Join(cfg.ConfigFolder, "manualData.json")
+	now := time.Now()
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"USPCE": {"VALUE": 111.85, "DATE": %d}}`, now.Add(time.Hour).Unix())), 0600))
+	testutil.Ok(t, tracker.LoadManualData(&cfg))
+
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logger, &cfg, DB)
+	testutil.Ok(t, err)
+	store := db.NewStore(proxy)
+	challenge := &MiningChallenge{Challenge: make([]byte, 32), Difficulty: big.NewInt(1)}
+	for i := range challenge.RequestIDs {
+		challenge.RequestIDs[i] = big.NewInt(int64(i + 1))
+	}
+	for i := 0; i < 4; i++ {
+		testutil.Ok(t, store.PutPSRValue(uint64(i+1), big.NewInt(int64(i+1)), 1, now))
+	}
+	// There is no value of the PSR so the manual value is submitted with the PSR granularity.
+	challenge.RequestIDs[4] = big.NewInt(41)
+
+	handler := CreateSolutionHandler(&cfg, logger, testSubmitter{}, proxy)
+	_, err = handler.Submit(context.Background(), &Result{Work: &Work{Challenge: challenge}, Nonce: "1"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(111850)}, handler.currentValues[:])
+
+	// An expired manual value isn't submitted.
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"USPCE": {"VALUE": 111.85, "DATE": %d}}`, now.Add(-time.Hour).Unix())), 0600))
+	modTime := now.Add(time.Minute)
+	testutil.Ok(t, os.Chtimes(path, modTime, modTime))
+	_, err = handler.Submit(context.Background(), &Result{Work: &Work{Challenge: challenge}, Nonce: "2"})
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/tracker"
+)
+
+// APIPrefix is the path of the JSON API.
+const APIPrefix = "/api/v1/"
+
+// API is a read-only JSON API for the prices, the current challenge and the status of the miners.
+// Unlike the signed requests on / it is meant for dashboards and other services.
+type API struct {
+	logger   log.Logger
+	proxy    db.DataServerProxy
+	client   contracts.ETHClient
+	contract *contracts.Tellor
+	token    string
+}
+
+// NewAPI creates the JSON API handler.
+// When token isn't empty every request needs it as a bearer token.
+func NewAPI(logger log.Logger, cfg *config.Config, proxy db.DataServerProxy, client contracts.ETHClient, contract *contracts.Tellor, token string) (*API, error) {
+	filterLog, err := logging.ApplyFilter(*cfg, ComponentName, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "applying filter to logger")
+	}
+	return &API{
+		logger:   log.With(filterLog, "component", ComponentName),
+		proxy:    proxy,
+		client:   client,
+		contract: contract,
+		token:    token,
+	}, nil
+}
+
+// ServeHTTP routes the requests under APIPrefix.
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		a.error(w, http.StatusMethodNotAllowed, errors.Errorf("method not allowed:%v", r.Method))
+		return
+	}
+	if !a.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		a.error(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, APIPrefix), "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "psr":
+		a.psrs(w)
+	case len(parts) == 2 && parts[0] == "psr":
+		a.psr(w, parts[1])
+	case len(parts) == 1 && parts[0] == "challenge":
+		a.challenge(w)
+	case len(parts) == 2 && parts[0] == "miners":
+		a.miner(w, r, parts[1])
+	case len(parts) == 1 && parts[0] == "sources":
+		a.respond(w, tracker.SourcesHealth())
+	default:
+		a.error(w, http.StatusNotFound, errors.Errorf("unknown route:%v", r.URL.Path))
+	}
+}
+
+func (a *API) authorized(r *http.Request) bool {
+	if a.token == "" {
+		return true
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(a.token)) == 1
+}
+
+func (a *API) respond(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		level.Error(a.logger).Log("msg", "encode api response", "err", err)
+	}
+}
+
+func (a *API) error(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		level.Error(a.logger).Log("msg", "api request", "err", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); err != nil {
+		level.Error(a.logger).Log("msg", "encode api error", "err", err)
+	}
+}
+
+func (a *API) psr(w http.ResponseWriter, id string) {
+	requestID, err := strconv.Atoi(id)
+	if err != nil {
+		a.error(w, http.StatusBadRequest, errors.Errorf("invalid request id:%v", id))
+		return
+	}
+	if _, ok := tracker.PSRs[requestID]; !ok {
+		a.error(w, http.StatusNotFound, errors.Errorf("no PSR for request id:%v", requestID))
+		return
+	}
+	status, err := tracker.GetPSRStatus(requestID, time.Now())
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "computing the PSR value"))
+		return
+	}
+	a.respond(w, status)
+}
+
+// StoredPSR is a PSR value as stored in the DB by the trackers.
+type StoredPSR struct {
+	RequestID  uint64  `json:"requestId"`
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence"`
+	// Timestamp is the time of the value and Updated when it was written, omitted when unknown.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Updated   *time.Time `json:"updated,omitempty"`
+}
+
+func (a *API) psrs(w http.ResponseWriter) {
+	values, err := db.NewStore(a.proxy).GetPSRValues()
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "getting the PSR values from the DB"))
+		return
+	}
+	psrs := make([]*StoredPSR, 0, len(values))
+	for id, v := range values {
+		p := &StoredPSR{
+			RequestID:  id,
+			Value:      v.Value.String(),
+			Confidence: v.Confidence,
+		}
+		if !v.Timestamp.IsZero() {
+			ts := v.Timestamp.UTC()
+			p.Timestamp = &ts
+		}
+		if !v.Updated.IsZero() {
+			updated := v.Updated.UTC()
+			p.Updated = &updated
+		}
+		psrs = append(psrs, p)
+	}
+	sort.Slice(psrs, func(i, j int) bool { return psrs[i].RequestID < psrs[j].RequestID })
+	a.respond(w, psrs)
+}
+
+// Challenge is the current mining challenge.
+type Challenge struct {
+	Challenge  string   `json:"challenge"`
+	RequestIDs []string `json:"requestIds"`
+	Difficulty string   `json:"difficulty"`
+	TotalTip   string   `json:"totalTip"`
+	// Updated is when the challenge was written, omitted when unknown.
+	Updated *time.Time `json:"updated,omitempty"`
+}
+
+func (a *API) challenge(w http.ResponseWriter) {
+	challenge, updated, err := db.NewStore(a.proxy).GetChallenge()
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "getting the challenge from the DB"))
+		return
+	}
+	if challenge == nil {
+		a.error(w, http.StatusServiceUnavailable, errors.New("no challenge yet"))
+		return
+	}
+	c := &Challenge{
+		Challenge:  "0x" + hex.EncodeToString(challenge.Challenge),
+		Difficulty: challenge.Difficulty.String(),
+		TotalTip:   challenge.TotalTip.String(),
+	}
+	for _, id := range challenge.RequestIDs {
+		c.RequestIDs = append(c.RequestIDs, id.String())
+	}
+	if !updated.IsZero() {
+		c.Updated = &updated
+	}
+	a.respond(w, c)
+}
+
+// Miner is the status of a miner address.
+type Miner struct {
+	Address     string             `json:"address"`
+	StakeStatus int64              `json:"stakeStatus"`
+	StakeState  tracker.StakeState `json:"stakeState"`
+	// StakeDate and LastSubmit are omitted when the miner never staked or submitted.
+	StakeDate  *time.Time `json:"stakeDate,omitempty"`
+	LastSubmit *time.Time `json:"lastSubmit,omitempty"`
+	ETHBalance string     `json:"ethBalance"`
+	TRBBalance string     `json:"trbBalance"`
+}
+
+func (a *API) miner(w http.ResponseWriter, r *http.Request, addr string) {
+	if !common.IsHexAddress(addr) {
+		a.error(w, http.StatusBadRequest, errors.Errorf("invalid address:%v", addr))
+		return
+	}
+	if a.contract == nil || a.client == nil {
+		a.error(w, http.StatusServiceUnavailable, errors.New("no connection to the contract"))
+		return
+	}
+	address := common.HexToAddress(addr)
+	ctx := r.Context()
+
+	status, stakeDate, err := a.contract.Getter.GetStakerInfo(nil, address)
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "getting the staker info"))
+		return
+	}
+	// The contract saves the time of the last submission under the hash of the address.
+	lastSubmit, err := a.contract.Getter.GetUintVar(nil, rpc.Keccak256(common.LeftPadBytes(address.Bytes(), 32)))
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "getting the last submit time"))
+		return
+	}
+	ethBalance, err := a.client.BalanceAt(ctx, address, nil)
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "getting the ETH balance"))
+		return
+	}
+	trbBalance, err := a.contract.Getter.BalanceOf(nil, address)
+	if err != nil {
+		a.error(w, http.StatusInternalServerError, errors.Wrap(err, "getting the TRB balance"))
+		return
+	}
+	a.respond(w, &Miner{
+		Address:     address.Hex(),
+		StakeStatus: status.Int64(),
+		StakeState:  tracker.StakeState(status.Int64()),
+		StakeDate:   unixTime(stakeDate),
+		LastSubmit:  unixTime(lastSubmit),
+		ETHBalance:  ethBalance.String(),
+		TRBBalance:  trbBalance.String(),
+	})
+}
+
+func unixTime(v *big.Int) *time.Time {
+	if v == nil || v.Sign() == 0 {
+		return nil
+	}
+	t := time.Unix(v.Int64(), 0).UTC()
+	return &t
+}
@@ -0,0 +1,108 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestAPI(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logger, cfg, DB)
+	testutil.Ok(t, err)
+
+	client := rpc.NewMockClientWithValues(&rpc.MockOptions{
+		ETHBalance:    big.NewInt(3e18),
+		TokenBalance:  big.NewInt(500),
+		DisputeStatus: big.NewInt(3),
+	})
+	contract, err := contracts.NewTellor(client)
+	testutil.Ok(t, err)
+
+	api, err := NewAPI(logger, cfg, proxy, client, &contract, "secret")
+	testutil.Ok(t, err)
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	get := func(path, token string, v interface{}) int {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		testutil.Ok(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		testutil.Ok(t, err)
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			testutil.Ok(t, json.NewDecoder(resp.Body).Decode(v))
+		}
+		return resp.StatusCode
+	}
+
+	// The bearer token is required.
+	testutil.Equals(t, http.StatusUnauthorized, get(APIPrefix+"challenge", "", nil))
+	testutil.Equals(t, http.StatusUnauthorized, get(APIPrefix+"challenge", "wrong", nil))
+
+	// No challenge has been saved yet.
+	testutil.Equals(t, http.StatusServiceUnavailable, get(APIPrefix+"challenge", "secret", nil))
+	testutil.Ok(t, db.NewStore(proxy).SetChallenge(&db.Challenge{
+		Challenge:  []byte{0xab, 0xcd},
+		RequestIDs: [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)},
+		Difficulty: big.NewInt(1000),
+		TotalTip:   big.NewInt(20),
+	}))
+	var challenge Challenge
+	testutil.Equals(t, http.StatusOK, get(APIPrefix+"challenge", "secret", &challenge))
+	testutil.Assert(t, challenge.Updated != nil, "expected the time the challenge was written")
+	challenge.Updated = nil
+	testutil.Equals(t, Challenge{
+		Challenge:  "0xabcd",
+		RequestIDs: []string{"1", "2", "3", "4", "5"},
+		Difficulty: "1000",
+		TotalTip:   "20",
+	}, challenge)
+
+	var miner map[string]interface{}
+	testutil.Equals(t, http.StatusOK, get(APIPrefix+"miners/0x053b09e98ede40997546e8bb812cd838f18bb146", "secret", &miner))
+	testutil.Equals(t, "in dispute", miner["stakeState"])
+	testutil.Equals(t, "3000000000000000000", miner["ethBalance"])
+	testutil.Equals(t, "500", miner["trbBalance"])
+	testutil.Equals(t, http.StatusBadRequest, get(APIPrefix+"miners/0x123", "secret", nil))
+
+	var sources []interface{}
+	testutil.Equals(t, http.StatusOK, get(APIPrefix+"sources", "secret", &sources))
+	testutil.Equals(t, 0, len(sources))
+
+	// The stored PSR values are listed by their request id.
+	store := db.NewStore(proxy)
+	testutil.Ok(t, store.PutPSRValue(10, big.NewInt(300), 0.8, time.Unix(1000, 0)))
+	testutil.Ok(t, store.PutPSRValue(2, big.NewInt(100), 1, time.Unix(1000, 0)))
+	var psrs []StoredPSR
+	testutil.Equals(t, http.StatusOK, get(APIPrefix+"psr", "secret", &psrs))
+	testutil.Equals(t, 2, len(psrs))
+	testutil.Equals(t, uint64(2), psrs[0].RequestID)
+	testutil.Equals(t, "100", psrs[0].Value)
+	testutil.Equals(t, uint64(10), psrs[1].RequestID)
+	testutil.Equals(t, 0.8, psrs[1].Confidence)
+	testutil.Assert(t, psrs[1].Updated != nil, "expected the time the value was written")
+
+	testutil.Equals(t, http.StatusNotFound, get(APIPrefix+"psr/100000", "secret", nil))
+	testutil.Equals(t, http.StatusBadRequest, get(APIPrefix+"psr/abc", "secret", nil))
+	testutil.Equals(t, http.StatusNotFound, get(APIPrefix+"unknown", "secret", nil))
+}
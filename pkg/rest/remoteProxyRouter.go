@@ -5,9 +5,14 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -20,6 +25,9 @@ import (
 type RemoteProxyRouter struct {
 	dataProxy db.DataServerProxy
 	logger    log.Logger
+	// clientCerts requires a client certificate of a whitelisted miner.
+	clientCerts bool
+	whitelist   map[string]bool
 }
 
 // CreateRemoteProxy creates a remote proxy instance.
@@ -28,19 +36,66 @@ func CreateRemoteProxy(logger log.Logger, cfg *config.Config, ctx context.Contex
 	if err != nil {
 		return nil, err
 	}
+	whitelist := make(map[string]bool)
+	for _, a := range cfg.ServerWhitelist {
+		whitelist[strings.ToLower(common.HexToAddress(a).Hex())] = true
+	}
 	return &RemoteProxyRouter{
-		dataProxy: proxy,
-		logger:    log.With(logger, "component", ComponentName),
+		dataProxy:   proxy,
+		logger:      log.With(logger, "component", ComponentName),
+		clientCerts: cfg.DataServer.TLS.Enabled && cfg.DataServer.TLS.CAFile != "",
+		whitelist:   whitelist,
 	}, nil
 }
 
+// authorize returns the miner address of the client certificate when client certificates are required.
+// It responds with an error and returns false when the certificate is missing or not whitelisted.
+func (r *RemoteProxyRouter) authorize(w http.ResponseWriter, req *http.Request) (string, bool) {
+	if !r.clientCerts {
+		return "", true
+	}
+	peer, err := peerAddress(req)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "rejected request without a valid client certificate", "path", req.URL.Path, "err", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", false
+	}
+	if !r.whitelist[peer] {
+		level.Warn(r.logger).Log("msg", "rejected client certificate of an unauthorized miner", "path", req.URL.Path, "address", peer)
+		http.Error(w, "unauthorized address:"+peer, http.StatusForbidden)
+		return "", false
+	}
+	return peer, true
+}
+
+// requirePeer serves the handler only for the requests that pass the client certificate check of the miners.
+func (r *RemoteProxyRouter) requirePeer(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := r.authorize(w, req); ok {
+			handler.ServeHTTP(w, req)
+		}
+	})
+}
+
 // Default http handler callback which will route to appropriate handler internally.
 func (r *RemoteProxyRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// The "/" pattern matches everything, so this is to disable any other requests.
-	if req.URL.Path != "/" {
+	if req.URL.Path != "/" && req.URL.Path != db.RPCPath && req.URL.Path != db.StreamPath {
 		http.NotFound(w, req)
 		return
 	}
+	peer, ok := r.authorize(w, req)
+	if !ok {
+		return
+	}
+	switch req.URL.Path {
+	case db.RPCPath:
+		r.serveRPC(w, req, peer)
+		return
+	case db.StreamPath:
+		r.serveStream(w, req, peer)
+		return
+	}
 	w.Header().Add("Content-Type", "application/octet-stream")
 
 	if e := recover(); e != nil {
@@ -55,7 +110,7 @@ func (r *RemoteProxyRouter) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		return
 	}
 	level.Info(r.logger).Log("msg", "getting request", "bytes", len(data))
-	outData, err := r.dataProxy.IncomingRequest(data)
+	outData, err := r.dataProxy.IncomingRequest(peer, data)
 
 	if err != nil {
 		level.Error(r.logger).Log("msg", "handling incoming request", "err", err)
@@ -71,3 +126,92 @@ func (r *RemoteProxyRouter) ServeHTTP(w http.ResponseWriter, req *http.Request)
 		return
 	}
 }
+
+// serveRPC handles the JSON-RPC requests.
+func (r *RemoteProxyRouter) serveRPC(w http.ResponseWriter, req *http.Request, peer string) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		level.Error(r.logger).Log("msg", "reading rpc request", "err", err)
+		http.Error(w, "read request data", http.StatusBadRequest)
+		return
+	}
+	outData, err := r.dataProxy.IncomingRPC(peer, data)
+	if err != nil {
+		level.Error(r.logger).Log("msg", "handling rpc request", "err", err)
+		http.Error(w, "handle request", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(outData); err != nil {
+		level.Error(r.logger).Log("msg", "write rpc response", "err", err)
+	}
+}
+
+// serveStream sends the updates of the subscribed keys as server-sent events.
+func (r *RemoteProxyRouter) serveStream(w http.ResponseWriter, req *http.Request, peer string) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		level.Error(r.logger).Log("msg", "reading stream request", "err", err)
+		http.Error(w, "read request data", http.StatusBadRequest)
+		return
+	}
+	events, cancel, err := r.dataProxy.Subscribe(peer, data)
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "rejected stream subscription", "err", err)
+		status := http.StatusBadRequest
+		if rpcErr, ok := err.(*db.RPCError); ok && rpcErr.Code == db.CodeUnauthorized {
+			status = http.StatusUnauthorized
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(err); err != nil {
+			level.Error(r.logger).Log("msg", "write stream error", "err", err)
+		}
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(db.StreamKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				level.Error(r.logger).Log("msg", "encode stream event", "err", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: update\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-req.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestStream(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	notifier := db.NewNotifier(DB)
+	testutil.Ok(t, notifier.Put(db.CurrentChallengeKey, []byte("first")))
+	proxy, err := db.OpenLocal(logger, cfg, notifier)
+	testutil.Ok(t, err)
+	router, err := CreateRemoteProxy(logger, cfg, context.Background(), proxy)
+	testutil.Ok(t, err)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	testutil.Ok(t, err)
+	p, err := strconv.Atoi(port)
+	testutil.Ok(t, err)
+	cfg.Mine.RemoteDBHost = host
+	cfg.Mine.RemoteDBPort = uint(p)
+	cfg.Mine.RemoteDBSigner = "0x92f91500e105e3051f3cf94616831b58f6bce1e8"
+	remote, err := db.OpenRemote(logger, cfg, nil)
+	testutil.Ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := remote.Stream(ctx, []string{db.CurrentChallengeKey})
+	testutil.Ok(t, err)
+	receive := func() map[string][]byte {
+		select {
+		case values := <-stream:
+			return values
+		case <-time.After(5 * time.Second):
+			t.Fatal("no stream update")
+		}
+		return nil
+	}
+	testutil.Equals(t, map[string][]byte{db.CurrentChallengeKey: []byte("first")}, receive())
+	testutil.Ok(t, notifier.Put(db.CurrentChallengeKey, []byte("second")))
+	testutil.Equals(t, map[string][]byte{db.CurrentChallengeKey: []byte("second")}, receive())
+
+	cancel()
+	for range stream {
+	}
+
+	// Local proxies poll.
+	_, err = proxy.Stream(context.Background(), []string{db.CurrentChallengeKey})
+	testutil.Equals(t, db.ErrStreamUnsupported, err)
+}
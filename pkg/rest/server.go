@@ -5,6 +5,7 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/tracker"
 )
 
 const ComponentName = "rest"
@@ -21,8 +23,12 @@ const ComponentName = "rest"
 // Server wraps http server with pre-configured paths.
 type Server struct {
 	server    *http.Server
+	mux       *http.ServeMux
+	router    *RemoteProxyRouter
 	dataProxy db.DataServerProxy
 	logger    log.Logger
+	certs     *certReloader
+	done      chan struct{}
 }
 
 // Create a new server instance for the given host/port.
@@ -42,20 +48,91 @@ func Create(logger log.Logger, cfg *config.Config, ctx context.Context, proxy db
 		return nil, errors.Wrap(err, "applying filter to looger")
 	}
 
-	http.Handle("/", remoteHandler)
-	return &Server{
+	s := &Server{
 		server:    srv,
+		mux:       http.NewServeMux(),
+		router:    remoteHandler,
 		dataProxy: proxy,
 		logger:    log.With(filterLog, "component", ComponentName),
-	}, nil
+		done:      make(chan struct{}),
+	}
+	if cfg.DataServer.TLS.Enabled {
+		s.certs, err = newCertReloader(s.logger, cfg.DataServer.TLS.CertFile, cfg.DataServer.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if srv.TLSConfig, err = serverTLSConfig(cfg.DataServer.TLS, s.certs); err != nil {
+			return nil, err
+		}
+	}
+	srv.Handler = s.mux
+	s.mux.Handle("/", remoteHandler)
+	s.Handle("/dispute/candidates", http.HandlerFunc(s.disputeCandidates))
+	s.Handle(db.HealthPath, http.HandlerFunc(s.health))
+	return s, nil
+}
+
+// Handle registers a handler on the data server behind the same client certificate check as the miner requests.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, s.router.requirePeer(handler))
+}
+
+// health responds with the health of the data server for the miners and load balancers.
+// It is unhealthy when its DB wasn't written recently.
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	health := s.dataProxy.Health()
+	w.Header().Set("Content-Type", "application/json")
+	if !health.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		level.Error(s.logger).Log("msg", "encode health", "err", err)
+	}
+}
+
+// disputeCandidates responds with the stored dispute candidates as JSON
+// or as Markdown when requested with format=markdown.
+func (s *Server) disputeCandidates(w http.ResponseWriter, r *http.Request) {
+	candidates, err := tracker.GetDisputeCandidates(s.dataProxy)
+	if err != nil {
+		level.Error(s.logger).Log("msg", "get dispute candidates", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(candidates); err != nil {
+			level.Error(s.logger).Log("msg", "encode dispute candidates", "err", err)
+		}
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown")
+		for _, c := range candidates {
+			fmt.Fprintln(w, c.Markdown())
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unknown format:%v", format), http.StatusBadRequest)
+	}
 }
 
 // Start the server listening for incoming requests.
 func (s *Server) Start() {
+	if s.certs != nil {
+		if err := s.certs.watch(s.done); err != nil {
+			level.Error(s.logger).Log("msg", "the certificate won't be reloaded on changes", "err", err)
+		}
+	}
 	go func() {
-		level.Info(s.logger).Log("msg", "starting server", "addr", s.server.Addr)
+		level.Info(s.logger).Log("msg", "starting server", "addr", s.server.Addr, "tls", s.certs != nil)
+		var err error
+		if s.certs != nil {
+			// The certificate is served by the TLS config.
+			err = s.server.ListenAndServeTLS("", "")
+		} else {
+			err = s.server.ListenAndServe()
+		}
 		// returns ErrServerClosed on graceful close
-		if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
+		if err != http.ErrServerClosed {
 			// NOTE: there is a chance that next line won't have time to run,
 			// as main() doesn't wait for this goroutine to stop. don't use
 			// code with race conditions like these for production. see post
@@ -69,5 +146,6 @@ func (s *Server) Start() {
 // Stop stops the server listening.
 func (s *Server) Stop() error {
 	level.Info(s.logger).Log("msg", "stopping server")
+	close(s.done)
 	return s.server.Close()
 }
@@ -0,0 +1,129 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/rjeczalik/notify"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// certSettleTime is how long to wait after a certificate change before reloading it.
+const certSettleTime = time.Second
+
+// certReloader serves the data server certificate and reloads it when its files change.
+type certReloader struct {
+	logger   log.Logger
+	certFile string
+	keyFile  string
+
+	mtx  sync.RWMutex
+	cert *tls.Certificate
+}
+
+func newCertReloader(logger log.Logger, certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{logger: logger, certFile: certFile, keyFile: keyFile}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reload loads the certificate files and keeps the current certificate when they are invalid.
+func (c *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "loading the certificate")
+	}
+	c.mtx.Lock()
+	c.cert = &cert
+	c.mtx.Unlock()
+	return nil
+}
+
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.cert, nil
+}
+
+// watch reloads the certificate when the files in its folders change, until done is closed.
+func (c *certReloader) watch(done chan struct{}) error {
+	events := make(chan notify.EventInfo, 10)
+	for _, dir := range []string{filepath.Dir(c.certFile), filepath.Dir(c.keyFile)} {
+		if err := notify.Watch(dir, events, notify.Create, notify.Write, notify.Rename, notify.Remove); err != nil {
+			notify.Stop(events)
+			return errors.Wrapf(err, "watching the certificate folder:%v", dir)
+		}
+	}
+	go func() {
+		defer notify.Stop(events)
+		// Certificates are often renewed by replacing both files
+		// so wait for the changes to settle before reloading.
+		settle := time.NewTimer(0)
+		<-settle.C
+		for {
+			select {
+			case <-done:
+				settle.Stop()
+				return
+			case <-events:
+				settle.Reset(certSettleTime)
+			case <-settle.C:
+				if err := c.reload(); err != nil {
+					level.Error(c.logger).Log("msg", "rejected the changed certificate, keeping the current one", "err", err)
+					continue
+				}
+				level.Info(c.logger).Log("msg", "reloaded the certificate")
+			}
+		}
+	}()
+	return nil
+}
+
+// serverTLSConfig creates the TLS config of the data server.
+// With a CA file the client certificates are verified when given,
+// the remote proxy requires them from the miners.
+func serverTLSConfig(cfg config.TLS, certs *certReloader) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+	if cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading the CA file")
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates in the CA file:%v", cfg.CAFile)
+		}
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+// peerAddress returns the miner address in the common name of the verified client certificate.
+func peerAddress(req *http.Request) (string, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 {
+		return "", errors.New("missing client certificate")
+	}
+	cn := req.TLS.VerifiedChains[0][0].Subject.CommonName
+	if !common.IsHexAddress(cn) {
+		return "", errors.Errorf("the client certificate common name isn't an address:%v", cn)
+	}
+	return strings.ToLower(common.HexToAddress(cn).Hex()), nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package rest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.Ok(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	testutil.Ok(t, err)
+	cert, err := x509.ParseCertificate(der)
+	testutil.Ok(t, err)
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	testutil.Ok(t, ioutil.WriteFile(ca.file("ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return ca
+}
+
+func (ca *testCA) file(name string) string {
+	return filepath.Join(ca.dir, name)
+}
+
+// issue writes a certificate signed by the CA and its key.
+func (ca *testCA) issue(t *testing.T, name, commonName string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.Ok(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	testutil.Ok(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	testutil.Ok(t, err)
+	testutil.Ok(t, ioutil.WriteFile(ca.file(name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	testutil.Ok(t, ioutil.WriteFile(ca.file(name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+}
+
+func TestCertReloader(t *testing.T) {
+	ca := newTestCA(t)
+	ca.issue(t, "server", "localhost", 2)
+	certs, err := newCertReloader(logging.NewLogger(), ca.file("server.pem"), ca.file("server.key"))
+	testutil.Ok(t, err)
+	first, err := certs.GetCertificate(nil)
+	testutil.Ok(t, err)
+
+	ca.issue(t, "server", "localhost", 3)
+	testutil.Ok(t, certs.reload())
+	second, err := certs.GetCertificate(nil)
+	testutil.Ok(t, err)
+	leaf, err := x509.ParseCertificate(second.Certificate[0])
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(3), leaf.SerialNumber.Int64())
+	testutil.Assert(t, first != second, "expected a new certificate")
+
+	// Invalid files keep the current certificate.
+	testutil.Ok(t, ioutil.WriteFile(ca.file("server.key"), []byte("invalid"), 0600))
+	testutil.NotOk(t, certs.reload())
+	current, err := certs.GetCertificate(nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, current == second, "expected the current certificate to be kept")
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	ca.issue(t, "server", "localhost", 2)
+	ca.issue(t, "miner", "0x92f91500e105e3051f3cf94616831b58f6bce1e8", 3)
+	ca.issue(t, "other", "0x053b09e98ede40997546e8bb812cd838f18bb146", 4)
+
+	cfg := config.OpenTestConfig(t)
+	cfg.ServerWhitelist = []string{"0x92f91500e105e3051f3cf94616831b58f6bce1e8"}
+	cfg.DataServer.TLS = config.TLS{
+		Enabled:  true,
+		CertFile: ca.file("server.pem"),
+		KeyFile:  ca.file("server.key"),
+		CAFile:   ca.file("ca.pem"),
+	}
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	testutil.Ok(t, DB.Put(db.DifficultyKey, []byte("2")))
+	proxy, err := db.OpenLocal(logger, cfg, DB)
+	testutil.Ok(t, err)
+
+	server, err := Create(logger, cfg, context.Background(), proxy, "127.0.0.1", 0)
+	testutil.Ok(t, err)
+	server.Handle("/events", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tlsConfig := server.server.TLSConfig
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	testutil.Ok(t, err)
+	srv := &http.Server{Handler: server.server.Handler, TLSConfig: tlsConfig}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	defer srv.Close()
+
+	cfg.Mine.RemoteDBHost = "127.0.0.1"
+	cfg.Mine.RemoteDBPort = uint(ln.Addr().(*net.TCPAddr).Port)
+	cfg.Mine.RemoteDBTLS = config.TLS{
+		Enabled:  true,
+		CertFile: ca.file("miner.pem"),
+		KeyFile:  ca.file("miner.key"),
+		CAFile:   ca.file("ca.pem"),
+	}
+	remote, err := db.OpenRemote(logger, cfg, nil)
+	testutil.Ok(t, err)
+	vals, err := remote.BatchGet([]string{db.DifficultyKey})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "2", string(vals[db.DifficultyKey]))
+
+	// The requests are retried until the timeout so only check the server responses for the rejected certificates.
+	// The other data server routes require the same certificates.
+	for name, status := range map[string]int{"": http.StatusUnauthorized, "other": http.StatusForbidden, "miner": http.StatusOK} {
+		clientTLS := &tls.Config{RootCAs: tlsConfig.ClientCAs}
+		if name != "" {
+			cert, err := tls.LoadX509KeyPair(ca.file(name+".pem"), ca.file(name+".key"))
+			testutil.Ok(t, err)
+			clientTLS.Certificates = []tls.Certificate{cert}
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		if name != "miner" {
+			resp, err := client.Post("https://"+ln.Addr().String()+db.RPCPath, "application/json", nil)
+			testutil.Ok(t, err)
+			resp.Body.Close()
+			testutil.Equals(t, status, resp.StatusCode)
+		}
+		for _, path := range []string{"/dispute/candidates", "/events"} {
+			resp, err := client.Get("https://" + ln.Addr().String() + path)
+			testutil.Ok(t, err)
+			resp.Body.Close()
+			testutil.Equals(t, status, resp.StatusCode, "path:%v client:%v", path, name)
+		}
+	}
+}
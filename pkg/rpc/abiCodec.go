@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	balancer "github.com/tellor-io/telliot/pkg/contracts/balancer"
+	"github.com/tellor-io/telliot/pkg/contracts/chainlink"
 	"github.com/tellor-io/telliot/pkg/contracts/master"
 	"github.com/tellor-io/telliot/pkg/contracts/proxy"
 	uniswap "github.com/tellor-io/telliot/pkg/contracts/uniswap"
@@ -37,8 +38,10 @@ func BuildCodec(logger log.Logger) (*ABICodec, error) {
 		proxy.TellorGettersABI,
 		balancer.BPoolABI,
 		balancer.BTokenABI,
+		chainlink.AggregatorV3InterfaceABI,
 		uniswap.IERC20ABI,
 		uniswap.IUniswapV2PairABI,
+		uniswap.IUniswapV3PoolABI,
 	}
 
 	parsed := make([]interface{}, 0)
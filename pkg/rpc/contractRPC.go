@@ -10,13 +10,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/alerting"
 	tellorCommon "github.com/tellor-io/telliot/pkg/common"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
@@ -25,6 +25,9 @@ import (
 	"github.com/tellor-io/telliot/pkg/db"
 )
 
+// gasPriceMaxAge is how old the gas price in the DB can be before using the client suggested gas price.
+const gasPriceMaxAge = 10 * time.Minute
+
 // contractWrapper is internal wrapper of contract instance for calling common contract functions.
 type contractWrapper struct {
 	options     *bind.TransactOpts
@@ -46,6 +49,51 @@ func (c contractWrapper) DidMine(challenge [32]byte) (bool, error) {
 	return c.TellorGetters.DidMine(nil, challenge, c.fromAddress)
 }
 
+// PrepareEthTransaction returns the transaction options to send a transaction from the account
+// after checking that it has enough ETH to pay for it.
+func PrepareEthTransaction(
+	ctx context.Context,
+	client contracts.ETHClient,
+	account *Account,
+) (*bind.TransactOpts, error) {
+
+	nonce, err := client.PendingNonceAt(ctx, account.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting pending nonce")
+	}
+
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting gas price")
+	}
+
+	ethBalance, err := client.BalanceAt(ctx, account.Address, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting balance")
+	}
+
+	cost := new(big.Int)
+	cost.Mul(gasPrice, big.NewInt(700000))
+	if ethBalance.Cmp(cost) < 0 {
+		return nil, errors.Errorf("insufficient ethereum to send a transaction: %v < %v", ethBalance, cost)
+	}
+
+	netID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting network id")
+	}
+
+	auth, err := bind.NewKeyedTransactorWithChainID(account.PrivateKey, netID)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating transactor")
+	}
+	auth.Nonce = big.NewInt(int64(nonce))
+	auth.Value = big.NewInt(0)      // in wei
+	auth.GasLimit = uint64(3000000) // in units
+	auth.GasPrice = gasPrice
+	return auth, nil
+}
+
 func SubmitContractTxn(
 	ctx context.Context,
 	logger log.Logger,
@@ -63,15 +111,15 @@ func SubmitContractTxn(
 		return nil, errors.Wrap(err, "getting nonce for miner address")
 	}
 	IntNonce := int64(nonce)
-	keys := []string{
-		db.GasKey,
-	}
-	m, err := proxy.BatchGet(keys)
+	gasPrice, updated, err := db.NewStore(proxy).GetGasPrice()
 	if err != nil {
 		return nil, errors.Wrap(err, "getting data from the db")
 	}
-	gasPrice := getInt(m[db.GasKey])
-	if gasPrice.Cmp(big.NewInt(0)) == 0 {
+	if !updated.IsZero() && time.Since(updated) > gasPriceMaxAge {
+		level.Warn(logger).Log("msg", "stale gas price in the DB", "updated", updated)
+		gasPrice = nil
+	}
+	if gasPrice == nil || gasPrice.Sign() == 0 {
 		level.Warn(logger).Log("msg", "Missing gas price from DB, falling back to client suggested gas price")
 		gasPrice, err = client.SuggestGasPrice(ctx)
 		if err != nil {
@@ -95,7 +143,17 @@ func SubmitContractTxn(
 		cost := big.NewInt(1)
 		cost = cost.Mul(gasPrice, big.NewInt(200000))
 		if balance.Cmp(cost) < 0 {
-			// FIXME: notify someone that we're out of funds!
+			alerting.Notify(&alerting.Alert{
+				Rule:     alerting.RuleLowETHBalance,
+				Key:      "insufficient_funds",
+				Severity: alerting.SeverityCritical,
+				Summary:  "insufficient funds to send " + ctxName + " transaction",
+				Details: map[string]string{
+					"account": account.Address.Hex(),
+					"balance": balance.String(),
+					"cost":    cost.String(),
+				},
+			})
 			finalError = errors.Errorf("insufficient funds to send transaction: %v < %v", balance, cost)
 			continue
 		}
@@ -163,18 +221,6 @@ func SubmitContractTxn(
 	return nil, errors.Wrapf(finalError, "submit txn after 5 attempts ctx:%v", ctxName)
 }
 
-func getInt(data []byte) *big.Int {
-	if len(data) == 0 {
-		return nil
-	}
-
-	val, err := hexutil.DecodeBig(string(data))
-	if err != nil {
-		return nil
-	}
-	return val
-}
-
 func Keccak256(input []byte) [32]byte {
 	hash := crypto.Keccak256(input)
 	var hashed [32]byte
@@ -42,7 +42,14 @@ const (
 	// Balancertoken funcs.
 	symbolFN = "0x95d89b41"
 	// Uniswap pair funcs.
-	getReservesFN = "0x0902f1ac"
+	getReservesFN          = "0x0902f1ac"
+	price0CumulativeLastFN = "0x5909c0d5"
+	price1CumulativeLastFN = "0x5a3d5493"
+	// Uniswap V3 pool funcs.
+	slot0FN   = "0x3850c7bd"
+	observeFN = "0x883bdbfd"
+	// Chainlink aggregator funcs.
+	latestRoundDataFN = "0xfeaf968c"
 	// Uniswap erc20 token funcs.
 	token0FN = "0x0dfe1681"
 	token1FN = "0xd21220a7"
@@ -95,6 +102,16 @@ type MockOptions struct {
 	UniReserves            *CurrentReserves
 	UniToken0              common.Address
 	UniToken1              common.Address
+	// UniPrice0CumulativeLast and UniPrice1CumulativeLast are the V2 pair price accumulators.
+	UniPrice0CumulativeLast *big.Int
+	UniPrice1CumulativeLast *big.Int
+
+	// Uniswap V3 related.
+	UniV3SqrtPriceX96    *big.Int
+	UniV3TickCumulatives []*big.Int
+
+	// Chainlink related.
+	ChainlinkAnswer *big.Int
 
 	// Decimals values for Uniswap, Balancer based on contract addresses.
 	Decimals map[string]int
@@ -121,10 +138,19 @@ type mockClient struct {
 	bPoolSpotPrice       *big.Int
 
 	// Uniswap related.
-	uniPairContractAddress common.Address
-	uniReserves            *CurrentReserves
-	uniToken0              common.Address
-	uniToken1              common.Address
+	uniPairContractAddress  common.Address
+	uniReserves             *CurrentReserves
+	uniToken0               common.Address
+	uniToken1               common.Address
+	uniPrice0CumulativeLast *big.Int
+	uniPrice1CumulativeLast *big.Int
+
+	// Uniswap V3 related.
+	uniV3SqrtPriceX96    *big.Int
+	uniV3TickCumulatives []*big.Int
+
+	// Chainlink related.
+	chainlinkAnswer *big.Int
 
 	// Decimals values for Uniswap, Balancer based on contract addresses.
 	decimals map[string]int
@@ -159,26 +185,31 @@ func NewMockClientWithValues(opts *MockOptions) contracts.ETHClient {
 	logger := logging.NewLogger()
 	level.Info(logger).Log("msg", "check mining status", "status", opts.MiningStatus)
 	return &mockClient{
-		balance:                opts.ETHBalance,
-		miningStatus:           opts.MiningStatus,
-		nonce:                  opts.Nonce,
-		gasPrice:               opts.GasPrice,
-		tokenBalance:           opts.TokenBalance,
-		top50Requests:          opts.Top50Requests,
-		currentChallenge:       opts.CurrentChallenge,
-		disputeStatus:          opts.DisputeStatus,
-		mockQueryMeta:          opts.QueryMetadata,
-		bPoolContractAddress:   opts.BPoolContractAddress,
-		bPoolCurrentTokens:     opts.BPoolCurrentTokens,
-		bPoolSpotPrice:         opts.BPoolSpotPrice,
-		tokenSymbols:           opts.TokenSymbols,
-		uniPairContractAddress: opts.UniPairContractAddress,
-		uniReserves:            opts.UniReserves,
-		uniToken0:              opts.UniToken0,
-		uniToken1:              opts.UniToken1,
-		decimals:               opts.Decimals,
-		abiCodec:               codec,
-		logger:                 log.With(logger, "component", ComponentName),
+		balance:                 opts.ETHBalance,
+		miningStatus:            opts.MiningStatus,
+		nonce:                   opts.Nonce,
+		gasPrice:                opts.GasPrice,
+		tokenBalance:            opts.TokenBalance,
+		top50Requests:           opts.Top50Requests,
+		currentChallenge:        opts.CurrentChallenge,
+		disputeStatus:           opts.DisputeStatus,
+		mockQueryMeta:           opts.QueryMetadata,
+		bPoolContractAddress:    opts.BPoolContractAddress,
+		bPoolCurrentTokens:      opts.BPoolCurrentTokens,
+		bPoolSpotPrice:          opts.BPoolSpotPrice,
+		tokenSymbols:            opts.TokenSymbols,
+		uniPairContractAddress:  opts.UniPairContractAddress,
+		uniReserves:             opts.UniReserves,
+		uniToken0:               opts.UniToken0,
+		uniToken1:               opts.UniToken1,
+		uniPrice0CumulativeLast: opts.UniPrice0CumulativeLast,
+		uniPrice1CumulativeLast: opts.UniPrice1CumulativeLast,
+		uniV3SqrtPriceX96:       opts.UniV3SqrtPriceX96,
+		uniV3TickCumulatives:    opts.UniV3TickCumulatives,
+		chainlinkAnswer:         opts.ChainlinkAnswer,
+		decimals:                opts.Decimals,
+		abiCodec:                codec,
+		logger:                  log.With(logger, "component", ComponentName),
 	}
 }
 
@@ -424,6 +455,33 @@ func (c *mockClient) CallContract(ctx context.Context, call ethereum.CallMsg, bl
 		{
 			return meth.Outputs.Pack(c.uniToken1)
 		}
+	case price0CumulativeLastFN:
+		{
+			return meth.Outputs.Pack(c.uniPrice0CumulativeLast)
+		}
+	case price1CumulativeLastFN:
+		{
+			return meth.Outputs.Pack(c.uniPrice1CumulativeLast)
+		}
+	// Uniswap V3 related.
+	case slot0FN:
+		{
+			return meth.Outputs.Pack(c.uniV3SqrtPriceX96, big.NewInt(0), uint16(0), uint16(0), uint16(0), uint8(0), true)
+		}
+	case observeFN:
+		{
+			secondsPerLiquidity := make([]*big.Int, len(c.uniV3TickCumulatives))
+			for i := range secondsPerLiquidity {
+				secondsPerLiquidity[i] = big.NewInt(0)
+			}
+			return meth.Outputs.Pack(c.uniV3TickCumulatives, secondsPerLiquidity)
+		}
+	// Chainlink related.
+	case latestRoundDataFN:
+		{
+			now := big.NewInt(time.Now().Unix())
+			return meth.Outputs.Pack(big.NewInt(1), c.chainlinkAnswer, now, now, big.NewInt(1))
+		}
 	// Handle "decimals" func for different contracts.
 	case decimalsFN:
 		outValue := c.decimals[call.To.Hex()]
@@ -490,6 +548,19 @@ func (c *mockClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery)
 	}
 	logs = append(logs, log)
 
+	// The new value that includes the submission.
+	ev = tokenAbi.Events["NewValue"]
+	newValue, err := ev.Inputs.NonIndexed().Pack(event.RequestId, big.NewInt(time.Now().Unix()), event.Value, big.NewInt(0))
+	if err != nil {
+		return logs, err
+	}
+	logs = append(logs, types.Log{
+		Address:     common.Address{0},
+		Topics:      []common.Hash{ev.ID, common.BigToHash(common.Big1)},
+		Data:        newValue,
+		BlockNumber: 10,
+	})
+
 	return logs, nil
 }
 func (c *mockClient) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
@@ -38,7 +38,7 @@ func (s *Ampl) Granularity() int64 {
 func AmpleChained(chainedPair string) IndexProcessor {
 	return func(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, error) {
 
-		eod := clck.Now().UTC()
+		eod := at.UTC()
 		d := 24 * time.Hour
 		eod = eod.Truncate(d)
 		eod = eod.Add(2 * time.Hour)
@@ -57,7 +57,7 @@ func AmpleChained(chainedPair string) IndexProcessor {
 
 		for i := 0; i < 144; i++ {
 			thisTime := eod.Add(time.Duration(-i) * interval)
-			chainedPrice, confidence, err := MedianAt(indexes[chainedPair], thisTime)
+			chainedPrice, confidence, err := MedianAt(indexesForSymbol(chainedPair), thisTime)
 			if err != nil {
 				return apiOracle.PriceInfo{}, 0, nil
 			}
@@ -22,13 +22,14 @@ import (
 )
 
 func TestAmpl(t *testing.T) {
-	util.CreateTestClient(&client, mockAPI)
 	cfg := config.OpenTestConfig(t)
 	DB, cleanup := db.OpenTestDB(t)
 	testClient := rpc.NewMockClient()
 	defer t.Cleanup(cleanup)
 
 	logger := logging.NewLogger()
+	fetcher := NewFetcher(logger, cfg)
+	util.CreateTestClient(fetcher.client, mockAPI)
 
 	proxy, err := db.OpenLocal(logger, cfg, DB)
 	testutil.Ok(t, err)
@@ -36,7 +37,7 @@ func TestAmpl(t *testing.T) {
 	mock := clock.NewMock()
 	clck = mock
 	mock.Set(time.Now())
-	if _, err := BuildIndexTrackers(logger, cfg, proxy, testClient); err != nil {
+	if _, err := BuildIndexTrackers(logger, cfg, proxy, testClient, fetcher); err != nil {
 		testutil.Ok(t, err)
 	}
 	amplTrackers := indexes["AMPL/USD"]
@@ -61,7 +62,6 @@ func TestAmpl(t *testing.T) {
 	}
 
 	// reset mocks
-	client = http.Client{}
 	clck = clock.New()
 }
 
@@ -6,11 +6,13 @@ package tracker
 import (
 	"context"
 	"fmt"
+	"math/big"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/alerting"
+	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/rpc"
@@ -19,19 +21,21 @@ import (
 const BalanceTrackerName = "BalanceTracker"
 
 type BalanceTracker struct {
-	db      db.DataServerProxy
+	store   *db.Store
 	client  contracts.ETHClient
 	account *rpc.Account
 	logger  log.Logger
+	config  *config.Config
 }
 
 func (b *BalanceTracker) String() string {
 	return BalanceTrackerName
 }
 
-func NewBalanceTracker(logger log.Logger, db db.DataServerProxy, client contracts.ETHClient, account *rpc.Account) *BalanceTracker {
+func NewBalanceTracker(logger log.Logger, config *config.Config, proxy db.DataServerProxy, client contracts.ETHClient, account *rpc.Account) *BalanceTracker {
 	return &BalanceTracker{
-		db:      db,
+		config:  config,
+		store:   db.NewStore(proxy),
 		client:  client,
 		account: account,
 		logger:  log.With(logger, "component", ComponentName),
@@ -49,6 +53,20 @@ func (b *BalanceTracker) Exec(ctx context.Context) error {
 	}
 	level.Info(b.logger).Log("msg", "got balance", "balance", fmt.Sprintf("%.2e", float64(balance.Int64())))
 
-	enc := hexutil.EncodeBig(balance)
-	return b.db.Put(db.BalanceKey, []byte(enc))
+	if min := b.config.Alerting.Rules.MinETHBalance; min > 0 && weiToFloat(balance) < min {
+		alerting.Notify(&alerting.Alert{
+			Rule:     alerting.RuleLowETHBalance,
+			Key:      _fromAddress.Hex(),
+			Severity: alerting.SeverityWarning,
+			Summary:  fmt.Sprintf("ETH balance %.4f is below %v", weiToFloat(balance), min),
+			Details:  map[string]string{"account": _fromAddress.Hex()},
+		})
+	}
+
+	return b.store.SetBalance(balance)
+}
+
+// weiToFloat converts an amount with 18 decimals to a float.
+func weiToFloat(v *big.Int) float64 {
+	return bigToFloat(v) / 1e18
 }
@@ -10,7 +10,6 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/logging"
@@ -27,7 +26,7 @@ func TestStringId(t *testing.T) {
 	proxy, err := db.OpenLocal(logging.NewLogger(), cfg, DB)
 	testutil.Ok(t, err)
 	logger := logging.NewLogger()
-	tracker := NewBalanceTracker(logger, proxy, client, nil)
+	tracker := NewBalanceTracker(logger, cfg, proxy, client, nil)
 	res := tracker.String()
 
 	testutil.Equals(t, res, BalanceTrackerName, "didn't return expected string", BalanceTrackerName)
@@ -58,7 +57,7 @@ func TestNegativeBalance(t *testing.T) {
 	logger := logging.NewLogger()
 	account, err := rpc.NewAccount(cfg)
 	testutil.Ok(t, err)
-	tracker := NewBalanceTracker(logger, proxy, client, &account)
+	tracker := NewBalanceTracker(logger, cfg, proxy, client, &account)
 	err = tracker.Exec(context.Background())
 	testutil.NotOk(t, err, "should have error")
 }
@@ -76,14 +75,12 @@ func dbBalanceTest(startBal *big.Int, t *testing.T) {
 	logger := logging.NewLogger()
 	account, err := rpc.NewAccount(cfg)
 	testutil.Ok(t, err)
-	tracker := NewBalanceTracker(logger, proxy, client, &account)
+	tracker := NewBalanceTracker(logger, cfg, proxy, client, &account)
 	err = tracker.Exec(context.Background())
 	testutil.Ok(t, err)
-	v, err := DB.Get(db.BalanceKey)
+	b, _, err := db.NewStore(proxy).GetBalance()
 	testutil.Ok(t, err)
-	b, err := hexutil.DecodeBig(string(v))
-	testutil.Ok(t, err)
-	t.Logf("Balance stored: %v\n", string(v))
+	t.Logf("Balance stored: %v\n", b)
 	if b.Cmp(startBal) != 0 {
 		testutil.Ok(t, errors.Errorf("Balance from client did not match what should have been stored in DB. %s != %s", b, startBal))
 	}
@@ -0,0 +1,81 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/contracts/chainlink"
+)
+
+// Chainlink implements DataSource interface.
+// It reads the latest answer of a Chainlink price feed aggregator.
+type Chainlink struct {
+	address string
+	client  contracts.ETHClient
+
+	mtx      sync.Mutex
+	decimals *uint8
+}
+
+func (c *Chainlink) String() string {
+	return "Chainlink"
+}
+
+// NewChainlink creates new Chainlink for provided aggregator address.
+func NewChainlink(address string, client contracts.ETHClient) *Chainlink {
+	return &Chainlink{
+		address: address,
+		client:  client,
+	}
+}
+
+// Get returns the latest aggregator answer.
+func (c *Chainlink) Get() ([]byte, error) {
+	aggregator, err := chainlink.NewAggregatorV3InterfaceCaller(common.HexToAddress(c.address), c.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting aggregator contract")
+	}
+	decimals, err := c.getDecimals(aggregator)
+	if err != nil {
+		return nil, err
+	}
+
+	round, err := aggregator.LatestRoundData(&bind.CallOpts{})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting latest round data")
+	}
+	if round.Answer.Sign() <= 0 {
+		return nil, errors.Errorf("invalid aggregator answer:%v", round.Answer)
+	}
+	if round.AnsweredInRound.Cmp(round.RoundId) < 0 {
+		return nil, errors.Errorf("stale aggregator answer from round:%v, latest round:%v", round.AnsweredInRound, round.RoundId)
+	}
+
+	price, _ := new(big.Float).Quo(new(big.Float).SetInt(round.Answer), new(big.Float).SetFloat64(math.Pow10(int(decimals)))).Float64()
+	return json.Marshal([]float64{price})
+}
+
+// getDecimals returns the aggregator decimals.
+// These never change so are queried only once.
+func (c *Chainlink) getDecimals(aggregator *chainlink.AggregatorV3InterfaceCaller) (uint8, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.decimals != nil {
+		return *c.decimals, nil
+	}
+	decimals, err := aggregator.Decimals(&bind.CallOpts{})
+	if err != nil {
+		return 0, errors.Wrap(err, "getting aggregator decimals")
+	}
+	c.decimals = &decimals
+	return decimals, nil
+}
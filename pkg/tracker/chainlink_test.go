@@ -0,0 +1,35 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	eth_common "github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestChainlinkPrice(t *testing.T) {
+	aggregator := eth_common.HexToAddress("0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419")
+	answer, _ := new(big.Int).SetString("184512000000", 10)
+	opts := &rpc.MockOptions{
+		ChainlinkAnswer: answer,
+		Decimals: map[string]int{
+			aggregator.Hex(): 8,
+		},
+	}
+	client := rpc.NewMockClientWithValues(opts)
+
+	tracker := NewChainlink(aggregator.Hex(), client)
+	priceJSON, err := tracker.Get()
+	testutil.Ok(t, err)
+
+	var priceInfo []float64
+	err = json.Unmarshal(priceJSON, &priceInfo)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []float64{1845.12}, priceInfo)
+}
@@ -0,0 +1,225 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts"
+)
+
+// ContractCall implements DataSource interface.
+// It calls any view function of a contract and returns the decoded return values
+// as a JSON array so that the index param can select the value with a JSONPath expression.
+// All numeric return values are divided by 10^decimals.
+type ContractCall struct {
+	address  common.Address
+	method   abi.Method
+	args     []interface{}
+	decimals uint8
+	client   contracts.ETHClient
+}
+
+func (c *ContractCall) String() string {
+	return "ContractCall"
+}
+
+// NewContractCall creates new ContractCall for the provided contract address.
+// The signature includes the input and output types, for example
+// "balanceOf(address)(uint256)" and args are the string encoded input values.
+func NewContractCall(signature string, args []string, decimals uint8, address string, client contracts.ETHClient) (*ContractCall, error) {
+	method, err := parseSignature(signature)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing contract call signature:%v", signature)
+	}
+	if len(args) != len(method.Inputs) {
+		return nil, errors.Errorf("contract call %v expects %d args, got:%d", method.Sig, len(method.Inputs), len(args))
+	}
+	callArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		callArgs[i], err = parseArg(method.Inputs[i].Type, arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing contract call arg:%v", arg)
+		}
+	}
+	return &ContractCall{
+		address:  common.HexToAddress(address),
+		method:   method,
+		args:     callArgs,
+		decimals: decimals,
+		client:   client,
+	}, nil
+}
+
+// Get calls the contract and returns all return values.
+func (c *ContractCall) Get() ([]byte, error) {
+	input, err := c.method.Inputs.Pack(c.args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "packing contract call args")
+	}
+	output, err := c.client.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &c.address,
+		Data: append(c.method.ID, input...),
+	}, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "calling %v", c.method.Sig)
+	}
+	values, err := c.method.Outputs.UnpackValues(output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unpacking %v return values", c.method.Sig)
+	}
+
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = c.jsonValue(value)
+	}
+	return json.Marshal(result)
+}
+
+// jsonValue converts a decoded return value to a JSON friendly value.
+func (c *ContractCall) jsonValue(value interface{}) interface{} {
+	var num *big.Int
+	switch v := value.(type) {
+	case *big.Int:
+		num = v
+	case uint8:
+		num = new(big.Int).SetUint64(uint64(v))
+	case uint16:
+		num = new(big.Int).SetUint64(uint64(v))
+	case uint32:
+		num = new(big.Int).SetUint64(uint64(v))
+	case uint64:
+		num = new(big.Int).SetUint64(v)
+	case int8:
+		num = big.NewInt(int64(v))
+	case int16:
+		num = big.NewInt(int64(v))
+	case int32:
+		num = big.NewInt(int64(v))
+	case int64:
+		num = big.NewInt(v)
+	case []*big.Int:
+		list := make([]interface{}, len(v))
+		for i := range v {
+			list[i] = c.jsonValue(v[i])
+		}
+		return list
+	case common.Address:
+		return v.Hex()
+	case [32]byte:
+		return common.Hash(v).Hex()
+	case bool, string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(num), new(big.Float).SetFloat64(math.Pow10(int(c.decimals)))).Float64()
+	return f
+}
+
+// parseSignature parses a function signature in the "name(inputs)(outputs)" format.
+func parseSignature(signature string) (abi.Method, error) {
+	signature = strings.ReplaceAll(signature, " ", "")
+	start := strings.Index(signature, "(")
+	if start <= 0 {
+		return abi.Method{}, errors.New("missing function name")
+	}
+	name := signature[:start]
+	end := strings.Index(signature, ")")
+	if end < start {
+		return abi.Method{}, errors.New("missing input types")
+	}
+	inputs, err := parseTypes(signature[start+1 : end])
+	if err != nil {
+		return abi.Method{}, err
+	}
+	rest := signature[end+1:]
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return abi.Method{}, errors.New("missing output types")
+	}
+	outputs, err := parseTypes(rest[1 : len(rest)-1])
+	if err != nil {
+		return abi.Method{}, err
+	}
+	if len(outputs) == 0 {
+		return abi.Method{}, errors.New("at least one output type is required")
+	}
+	return abi.NewMethod(name, name, abi.Function, "view", true, false, inputs, outputs), nil
+}
+
+func parseTypes(types string) (abi.Arguments, error) {
+	var args abi.Arguments
+	if types == "" {
+		return args, nil
+	}
+	for _, t := range strings.Split(types, ",") {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing type:%v", t)
+		}
+		args = append(args, abi.Argument{Type: typ})
+	}
+	return args, nil
+}
+
+// parseArg converts a string arg into the go type expected by the abi packer.
+func parseArg(typ abi.Type, arg string) (interface{}, error) {
+	switch typ.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(arg) {
+			return nil, errors.New("invalid address")
+		}
+		return common.HexToAddress(arg), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(arg)
+	case abi.StringTy:
+		return arg, nil
+	case abi.FixedBytesTy:
+		if typ.Size != 32 {
+			return nil, errors.Errorf("unsupported arg type:%v", typ)
+		}
+		return common.HexToHash(arg), nil
+	case abi.UintTy, abi.IntTy:
+		num, ok := new(big.Int).SetString(arg, 0)
+		if !ok {
+			return nil, errors.New("invalid number")
+		}
+		if typ.T == abi.UintTy {
+			switch typ.Size {
+			case 8:
+				return uint8(num.Uint64()), nil
+			case 16:
+				return uint16(num.Uint64()), nil
+			case 32:
+				return uint32(num.Uint64()), nil
+			case 64:
+				return num.Uint64(), nil
+			}
+		} else {
+			switch typ.Size {
+			case 8:
+				return int8(num.Int64()), nil
+			case 16:
+				return int16(num.Int64()), nil
+			case 32:
+				return int32(num.Int64()), nil
+			case 64:
+				return num.Int64(), nil
+			}
+		}
+		return num, nil
+	default:
+		return nil, errors.Errorf("unsupported arg type:%v", typ)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"math/big"
+	"testing"
+
+	eth_common "github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestContractCall(t *testing.T) {
+	pairContract := eth_common.HexToAddress("0xc5be99a02c6857f9eac67bbce58df5572498f40c")
+	reserve0, _ := big.NewInt(0).SetString("11073781494155978314322", 10)
+	reserve1, _ := big.NewInt(0).SetString("14899909395042275", 10)
+	opts := &rpc.MockOptions{
+		UniReserves: &rpc.CurrentReserves{
+			Reserve0:           reserve0,
+			Reserve1:           reserve1,
+			BlockTimestampLast: 200,
+		},
+	}
+	client := rpc.NewMockClientWithValues(opts)
+
+	source, err := NewContractCall("getReserves()(uint112,uint112,uint32)", nil, 18, pairContract.Hex(), client)
+	testutil.Ok(t, err)
+	payload, err := source.Get()
+	testutil.Ok(t, err)
+
+	tracker := &IndexTracker{Param: "$[0]"}
+	vals, err := tracker.ParsePayload(payload)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []float64{11073.781494155979}, vals)
+}
+
+func TestContractCallSignature(t *testing.T) {
+	client := rpc.NewMockClient()
+	address := "0xc5be99a02c6857f9eac67bbce58df5572498f40c"
+
+	_, err := NewContractCall("balanceOf(address)(uint256)", []string{"0x0ba45a8b5d5575935b8158a88c631e9f9c95a2e5"}, 18, address, client)
+	testutil.Ok(t, err)
+
+	for _, tc := range []struct {
+		signature string
+		args      []string
+	}{
+		{"balanceOf(address)", []string{"0x0ba45a8b5d5575935b8158a88c631e9f9c95a2e5"}},
+		{"balanceOf(address)(uint256)", nil},
+		{"balanceOf(address)(uint256)", []string{"not an address"}},
+		{"(address)(uint256)", []string{"0x0ba45a8b5d5575935b8158a88c631e9f9c95a2e5"}},
+		{"balanceOf(foo)(uint256)", []string{"1"}},
+	} {
+		_, err := NewContractCall(tc.signature, tc.args, 18, address, client)
+		testutil.NotOk(t, err, tc.signature)
+	}
+}
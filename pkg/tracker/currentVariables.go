@@ -6,30 +6,38 @@ package tracker
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/alerting"
+	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
 type CurrentVariablesTracker struct {
-	db       db.DataServerProxy
+	store    *db.Store
 	contract *contracts.Tellor
 	account  *rpc.Account
 	logger   log.Logger
+	config   *config.Config
+	// challenge is the last seen challenge and challengeTime when it was first seen.
+	challenge     [32]byte
+	challengeTime time.Time
 }
 
 func (b *CurrentVariablesTracker) String() string {
 	return "CurrentVariablesTracker"
 }
 
-func NewCurrentVariablesTracker(logger log.Logger, db db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *CurrentVariablesTracker {
+func NewCurrentVariablesTracker(logger log.Logger, config *config.Config, proxy db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *CurrentVariablesTracker {
 	return &CurrentVariablesTracker{
-		db:       db,
+		config:   config,
+		store:    db.NewStore(proxy),
 		contract: contract,
 		account:  account,
 		logger:   log.With(logger, "component", ComponentName),
@@ -52,41 +60,45 @@ func (b *CurrentVariablesTracker) Exec(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "status retrieval")
 	}
-	bitSetVar := []byte{0}
-	if myStatus {
-		bitSetVar = []byte{1}
-	}
-
 	timeOfLastNewValue, err := b.contract.Getter.GetUintVar(nil, rpc.Keccak256([]byte("timeOfLastNewValue")))
 	if err != nil {
 		return errors.Wrap(err, "time of last new value retrieval")
 	}
-	err = b.db.Put(db.LastNewValueKey, []byte(hexutil.EncodeBig(timeOfLastNewValue)))
+	err = b.store.SetLastNewValue(time.Unix(timeOfLastNewValue.Int64(), 0))
 	if err != nil {
-		return errors.Wrap(err, "ast new value put")
+		return errors.Wrap(err, "last new value put")
 	}
-	err = b.db.Put(db.CurrentChallengeKey, returnNewVariables.Challenge[:])
+	b.checkStale(returnNewVariables.Challenge)
+	err = b.store.SetChallenge(&db.Challenge{
+		Challenge:  returnNewVariables.Challenge[:],
+		RequestIDs: returnNewVariables.RequestIds,
+		Difficulty: returnNewVariables.Difficutly,
+		TotalTip:   returnNewVariables.Tip,
+	})
 	if err != nil {
 		return errors.Wrap(err, "current variables put")
 	}
 
-	for i := 0; i < 5; i++ {
-		conc := fmt.Sprintf("%s%d", "current_requestId", i)
-		err = b.db.Put(conc, []byte(hexutil.EncodeBig(returnNewVariables.RequestIds[i])))
-		if err != nil {
-			return errors.Wrap(err, "request Ids put")
-		}
-	}
+	return b.store.SetMiningStatus(myStatus)
+}
 
-	err = b.db.Put(db.DifficultyKey, []byte(hexutil.EncodeBig(returnNewVariables.Difficutly)))
-	if err != nil {
-		return errors.Wrap(err, "difficulty put")
+// checkStale alerts when the challenge hasn't changed for longer than the configured period.
+func (b *CurrentVariablesTracker) checkStale(challenge [32]byte) {
+	now := time.Now()
+	if challenge != b.challenge || b.challengeTime.IsZero() {
+		b.challenge = challenge
+		b.challengeTime = now
+		return
 	}
-
-	err = b.db.Put(db.TotalTipKey, []byte(hexutil.EncodeBig(returnNewVariables.Tip)))
-	if err != nil {
-		return errors.Wrap(err, "total tip put")
+	stale := b.config.Alerting.Rules.StaleChallenge.Duration
+	if stale <= 0 || now.Sub(b.challengeTime) < stale {
+		return
 	}
-
-	return b.db.Put(db.MiningStatusKey, bitSetVar)
+	alerting.Notify(&alerting.Alert{
+		Rule:     alerting.RuleStaleChallenge,
+		Key:      hexutil.Encode(challenge[:]),
+		Severity: alerting.SeverityWarning,
+		Summary:  fmt.Sprintf("the challenge hasn't changed for %v", now.Sub(b.challengeTime).Round(time.Second)),
+		Details:  map[string]string{"challenge": hexutil.Encode(challenge[:])},
+	})
 }
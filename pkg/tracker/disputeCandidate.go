@@ -0,0 +1,179 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/db"
+)
+
+// maxDisputeCandidates is how many of the most recent dispute candidates are kept.
+const maxDisputeCandidates = 100
+
+// Reasons of a dispute candidate.
+const (
+	// ReasonOutOfRange is a value outside of the range of the local value history.
+	ReasonOutOfRange = "out of range"
+	// ReasonOutlier is a value far from the values of the other miners.
+	ReasonOutlier = "outlier"
+	// ReasonMedianDeviation is a value far from the final mined value, the median of the five miners.
+	ReasonMedianDeviation = "median deviation"
+)
+
+// DisputeCandidate is a submitted value outside of the expected range
+// or far from the values of the other miners.
+type DisputeCandidate struct {
+	RequestID uint64   `json:"requestId"`
+	Reasons   []string `json:"reasons"`
+	Miner     string   `json:"miner"`
+	Value     string   `json:"value"`
+	// Slot is the index of the miner in the mined value.
+	Slot      int       `json:"slot"`
+	Timestamp uint64    `json:"timestamp"`
+	Block     uint64    `json:"block"`
+	TxHash    string    `json:"txHash"`
+	BlockTime time.Time `json:"blockTime"`
+	// Median is the mined value and PeerMedian the median of the values of the other miners.
+	Median     string  `json:"median"`
+	PeerMedian float64 `json:"peerMedian"`
+	// Datapoints are the values of the PSR around the block time.
+	Datapoints []DisputeDatapoint `json:"datapoints"`
+	Low        float64            `json:"low"`
+	High       float64            `json:"high"`
+	// Sources are the index APIs with recorded values around the block time.
+	Sources []string `json:"sources"`
+}
+
+// DisputeDatapoint is a value of a PSR at a time.
+type DisputeDatapoint struct {
+	Value float64   `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+// Markdown renders the candidate for posting to the governance forums.
+func (c *DisputeCandidate) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Suspected incorrect value for request ID %d\n\n", c.RequestID)
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Miner | `%s` |\n", c.Miner)
+	fmt.Fprintf(&b, "| Reasons | %s |\n", strings.Join(c.Reasons, ", "))
+	fmt.Fprintf(&b, "| Value | %s |\n", c.Value)
+	fmt.Fprintf(&b, "| Slot | %d |\n", c.Slot)
+	fmt.Fprintf(&b, "| Mined value | %s |\n", c.Median)
+	fmt.Fprintf(&b, "| Median of the other miners | %.0f |\n", c.PeerMedian)
+	if len(c.Datapoints) > 0 {
+		fmt.Fprintf(&b, "| Expected range | %.0f to %.0f |\n", c.Low, c.High)
+	}
+	fmt.Fprintf(&b, "| Timestamp | %d |\n", c.Timestamp)
+	fmt.Fprintf(&b, "| Block | %d |\n", c.Block)
+	fmt.Fprintf(&b, "| Block time | %s |\n", c.BlockTime.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "| Transaction | `%s` |\n\n", c.TxHash)
+	if len(c.Datapoints) > 0 {
+		fmt.Fprintf(&b, "#### Nearest values\n\n| Value | Time | Offset |\n|---|---|---|\n")
+	}
+	for _, dp := range c.Datapoints {
+		delta := c.BlockTime.Sub(dp.Time)
+		offset := delta.String() + " before"
+		if delta < 0 {
+			offset = (-delta).String() + " after"
+		}
+		fmt.Fprintf(&b, "| %.0f | %s | %s |\n", dp.Value, dp.Time.UTC().Format(time.RFC3339), offset)
+	}
+	if len(c.Sources) > 0 {
+		fmt.Fprintf(&b, "\n#### Sources\n\n")
+		for _, source := range c.Sources {
+			fmt.Fprintf(&b, "* %s\n", source)
+		}
+	}
+	return b.String()
+}
+
+// newDisputeCandidate creates a candidate from the check of a submitted value.
+// The result is nil when there is no local value history for the time of the value.
+func newDisputeCandidate(requestID uint64, result *ValueCheckResult, at time.Time, delta time.Duration) *DisputeCandidate {
+	c := &DisputeCandidate{
+		RequestID: requestID,
+		BlockTime: at,
+	}
+	if result == nil {
+		return c
+	}
+	c.Low = result.Low
+	c.High = result.High
+	c.Sources = contributingSources(requestID, at, delta)
+	for i, dp := range result.Datapoints {
+		c.Datapoints = append(c.Datapoints, DisputeDatapoint{Value: dp, Time: result.Times[i]})
+	}
+	return c
+}
+
+// contributingSources returns the names of the index APIs of a PSR
+// with recorded values within delta of at.
+func contributingSources(requestID uint64, at time.Time, delta time.Duration) []string {
+	psr, ok := PSRs[int(requestID)]
+	if !ok {
+		return nil
+	}
+	var sources []string
+	for symbol := range psr.Require(at) {
+		for _, api := range indexesForSymbol(symbol) {
+			if len(apiOracle.GetRequestValuesForTime(api.Identifier, at.Add(delta), 2*delta)) > 0 {
+				sources = append(sources, symbol+" "+api.Name)
+			}
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// disputeCandidatesMtx guards the read-modify-write of the stored candidates.
+var disputeCandidatesMtx sync.Mutex
+
+// GetDisputeCandidates returns the stored dispute candidates, the most recent first.
+func GetDisputeCandidates(DB db.DataServerProxy) ([]*DisputeCandidate, error) {
+	var candidates []*DisputeCandidate
+	if _, err := db.NewStore(DB).GetJSON(db.DisputeCandidatesKey, &candidates); err != nil {
+		return nil, errors.Wrap(err, "get dispute candidates")
+	}
+	return candidates, nil
+}
+
+// saveDisputeCandidates adds the candidates to the stored ones and keeps the most recent.
+func saveDisputeCandidates(DB db.DataServerProxy, added []*DisputeCandidate) error {
+	disputeCandidatesMtx.Lock()
+	defer disputeCandidatesMtx.Unlock()
+
+	candidates, err := GetDisputeCandidates(DB)
+	if err != nil {
+		return err
+	}
+	// The blocks are checked again when a later step of the checker fails
+	// so a submission replaces its earlier candidate.
+	key := func(c *DisputeCandidate) string { return fmt.Sprintf("%s-%d", c.TxHash, c.RequestID) }
+	replaced := make(map[string]bool, len(added))
+	for _, c := range added {
+		replaced[key(c)] = true
+	}
+	kept := append([]*DisputeCandidate{}, added...)
+	for _, c := range candidates {
+		if !replaced[key(c)] {
+			kept = append(kept, c)
+		}
+	}
+	candidates = kept
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].BlockTime.After(candidates[j].BlockTime)
+	})
+	if len(candidates) > maxDisputeCandidates {
+		candidates = candidates[:maxDisputeCandidates]
+	}
+	return errors.Wrap(db.NewStore(DB).PutJSON(db.DisputeCandidatesKey, candidates), "save dispute candidates")
+}
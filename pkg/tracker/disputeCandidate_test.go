@@ -0,0 +1,81 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestDisputeCandidates(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logging.NewLogger(), cfg, DB)
+	testutil.Ok(t, err)
+
+	candidates, err := GetDisputeCandidates(proxy)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(candidates))
+
+	start := time.Now().Truncate(time.Second)
+	var added []*DisputeCandidate
+	for i := 0; i < maxDisputeCandidates+10; i++ {
+		added = append(added, &DisputeCandidate{RequestID: uint64(i), BlockTime: start.Add(time.Duration(i) * time.Minute)})
+	}
+	testutil.Ok(t, saveDisputeCandidates(proxy, added[:10]))
+	testutil.Ok(t, saveDisputeCandidates(proxy, added[10:]))
+
+	// Only the most recent are kept, the most recent first.
+	candidates, err = GetDisputeCandidates(proxy)
+	testutil.Ok(t, err)
+	testutil.Equals(t, maxDisputeCandidates, len(candidates))
+	testutil.Equals(t, uint64(maxDisputeCandidates+9), candidates[0].RequestID)
+	testutil.Equals(t, uint64(10), candidates[len(candidates)-1].RequestID)
+	testutil.Assert(t, candidates[0].BlockTime.Equal(added[len(added)-1].BlockTime), "the block time should be kept")
+
+	// Checking the same submissions again doesn't add duplicates.
+	testutil.Ok(t, saveDisputeCandidates(proxy, added[len(added)-2:]))
+	candidates, err = GetDisputeCandidates(proxy)
+	testutil.Ok(t, err)
+	testutil.Equals(t, maxDisputeCandidates, len(candidates))
+	testutil.Equals(t, uint64(maxDisputeCandidates+8), candidates[1].RequestID)
+	testutil.Equals(t, uint64(10), candidates[len(candidates)-1].RequestID)
+}
+
+func TestDisputeCandidateMarkdown(t *testing.T) {
+	blockTime := time.Date(2021, 1, 18, 12, 0, 0, 0, time.UTC)
+	c := &DisputeCandidate{
+		RequestID: 1,
+		Miner:     "0x0000000000000000000000000000000000000001",
+		Value:     "2000000000",
+		Block:     100,
+		TxHash:    "0x01",
+		BlockTime: blockTime,
+		Datapoints: []DisputeDatapoint{
+			{Value: 1000000000, Time: blockTime.Add(-time.Minute)},
+			{Value: 1010000000, Time: blockTime.Add(time.Minute)},
+		},
+		Low:     990000000,
+		High:    1020100000,
+		Sources: []string{"ETH/USD api.pro.coinbase.com"},
+	}
+	md := c.Markdown()
+	for _, expected := range []string{
+		"request ID 1",
+		"| Value | 2000000000 |",
+		"| Expected range | 990000000 to 1020100000 |",
+		"| 1000000000 | 2021-01-18T11:59:00Z | 1m0s before |",
+		"| 1010000000 | 2021-01-18T12:01:00Z | 1m0s after |",
+		"* ETH/USD api.pro.coinbase.com",
+	} {
+		testutil.Assert(t, strings.Contains(md, expected), "markdown should contain:%v\n%v", expected, md)
+	}
+}
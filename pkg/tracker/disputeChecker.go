@@ -6,7 +6,6 @@ package tracker
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"math"
 	"math/big"
 	"strings"
@@ -16,20 +15,27 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/alerting"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/contracts/master"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
 type disputeChecker struct {
 	config           *config.Config
 	client           contracts.ETHClient
 	contract         *contracts.Tellor
+	db               db.DataServerProxy
 	lastCheckedBlock uint64
 	logger           log.Logger
+	// policy takes the automated dispute actions, nil when they are disabled.
+	policy *DisputePolicy
 }
 
 func (c *disputeChecker) String() string {
@@ -40,8 +46,11 @@ func (c *disputeChecker) String() string {
 type ValueCheckResult struct {
 	High, Low   float64
 	WithinRange bool
-	Datapoints  []float64
-	Times       []time.Time
+	// Deviation is the relative difference between the value
+	// and the nearest datapoint or 0 when it is between the datapoints.
+	Deviation  float64
+	Datapoints []float64
+	Times      []time.Time
 }
 
 // CheckValueAtTime queries for the details regarding the disputed value.
@@ -56,7 +65,7 @@ func CheckValueAtTime(cfg *config.Config, reqID uint64, val *big.Int, at time.Ti
 		if err != nil {
 			return nil, err
 		}
-		if confidence > 0.8 {
+		if confidence >= cfg.Disputes.MinConfidence {
 			datapoints = append(datapoints, fval)
 			times = append(times, t)
 		}
@@ -77,19 +86,28 @@ func CheckValueAtTime(cfg *config.Config, reqID uint64, val *big.Int, at time.Ti
 			min = dp
 		}
 	}
-	min *= 1 - cfg.DisputeThreshold
-	max *= 1 + cfg.DisputeThreshold
 
 	bigF := new(big.Float)
 	bigF.SetInt(val)
 	floatVal, _ := bigF.Float64()
 
+	var deviation float64
+	if floatVal < min {
+		deviation = (min - floatVal) / min
+	} else if floatVal > max {
+		deviation = (floatVal - max) / max
+	}
+
+	min *= 1 - cfg.DisputeThreshold
+	max *= 1 + cfg.DisputeThreshold
+
 	withinRange := (floatVal > min) && (floatVal < max)
 
 	return &ValueCheckResult{
 		Low:         min,
 		High:        max,
 		WithinRange: withinRange,
+		Deviation:   deviation,
 		Datapoints:  datapoints,
 		Times:       times,
 	}, nil
@@ -98,36 +116,197 @@ func CheckValueAtTime(cfg *config.Config, reqID uint64, val *big.Int, at time.Ti
 func NewDisputeChecker(
 	logger log.Logger,
 	config *config.Config,
+	db db.DataServerProxy,
 	client contracts.ETHClient,
 	contract *contracts.Tellor,
+	account *rpc.Account,
 	lastCheckedBlock uint64,
 ) *disputeChecker {
+	var policy *DisputePolicy
+	if config.Disputes.AutoVote || config.Disputes.AutoDispute {
+		policy = NewDisputePolicy(logger, config, client, contract, account, nil)
+	}
 	return &disputeChecker{
 		client:           client,
 		contract:         contract,
 		config:           config,
+		db:               db,
 		lastCheckedBlock: lastCheckedBlock,
 		logger:           log.With(logger, "component", ComponentName),
+		policy:           policy,
 	}
 }
 
-func (c *disputeChecker) Exec(ctx context.Context) error {
+// suspectedValue is a submitted value outside of the expected range.
+type suspectedValue struct {
+	requestID *big.Int
+	timestamp *big.Int
+	value     *big.Int
+	miner     common.Address
+	result    *ValueCheckResult
+}
+
+// disputeSlot returns the index of the miner of the suspected value in the mined value,
+// which identifies the submission in a dispute.
+// It returns false when the miner isn't one of the miners of the value.
+func (c *disputeChecker) disputeSlot(s *suspectedValue) (int, bool, error) {
+	miners, err := c.contract.Getter.GetMinersByRequestIdAndTimestamp(nil, s.requestID, s.timestamp)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "get miners of mined value")
+	}
+	for i, miner := range miners {
+		if miner == s.miner {
+			return i, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// voteOnDisputes votes on the disputes opened in the logs according to the value history.
+func (c *disputeChecker) voteOnDisputes(ctx context.Context, logs []types.Log) error {
+	disputeAbi, err := abi.JSON(strings.NewReader(master.TellorDisputeABI))
+	if err != nil {
+		return errors.Wrap(err, "parse abi")
+	}
+	bar := bind.NewBoundContract(c.contract.Address, disputeAbi, nil, nil, nil)
+	newDisputeID := disputeAbi.Events["NewDispute"].ID
+	for _, l := range logs {
+		if l.Topics[0] != newDisputeID {
+			continue
+		}
+		dispute := master.TellorDisputeNewDispute{}
+		if err := bar.UnpackLog(&dispute, "NewDispute", l); err != nil {
+			return errors.Wrap(err, "unpack dispute event from logs")
+		}
+		_, executed, _, _, _, _, _, uintVars, _, err := c.contract.Getter.GetAllDisputeVars(nil, dispute.DisputeId)
+		if err != nil {
+			return errors.Wrap(err, "get dispute details")
+		}
+		if executed {
+			continue
+		}
+		disputedValue := uintVars[2]
+		result, err := CheckValueAtTime(c.config, dispute.RequestId.Uint64(), disputedValue, time.Unix(dispute.Timestamp.Int64(), 0))
+		if err != nil {
+			// Not voted like without local data.
+			level.Warn(c.logger).Log("msg", "check disputed value with the local value history", "disputeID", dispute.DisputeId, "err", err)
+			result = nil
+		}
+		if err := c.policy.VoteOnDispute(ctx, dispute.DisputeId, dispute.RequestId.Uint64(), disputedValue, result); err != nil {
+			level.Error(c.logger).Log("msg", "vote on dispute", "disputeID", dispute.DisputeId, "err", err)
+		}
+	}
+	return nil
+}
+
+const (
+	// blockDelay is how many blocks the checker stays behind the head
+	// so that the local value history includes the time of the checked values.
+	blockDelay = 100
+	// submissionLookback is how many blocks before the checked blocks the nonce submissions
+	// are looked up so that the submissions of the new values at the start of the range are included.
+	submissionLookback = 100
+	// checkBatchBlocks is how many blocks are checked at once.
+	checkBatchBlocks = 5000
+)
 
+// checkpoint returns the last checked block from the DB, or the block before the configured start block.
+// Without both it returns the given head so that the checker starts from there.
+func (c *disputeChecker) checkpoint(head uint64) (uint64, error) {
+	block, _, err := db.NewStore(c.db).GetDisputeCheckerBlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "get dispute checker checkpoint")
+	}
+	if block > 0 {
+		return block, nil
+	}
+	if start := c.config.Disputes.CheckerStartBlock; start > 0 && start <= head {
+		return start - 1, nil
+	}
+	return head, nil
+}
+
+func (c *disputeChecker) Exec(ctx context.Context) error {
 	header, err := c.client.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return errors.Wrap(err, "get latest eth block header")
 	}
+	if header.Number.Uint64() < blockDelay {
+		return nil
+	}
+	checkUntil := header.Number.Uint64() - blockDelay
+
 	if c.lastCheckedBlock == 0 {
-		c.lastCheckedBlock = header.Number.Uint64()
+		c.lastCheckedBlock, err = c.checkpoint(checkUntil)
+		if err != nil {
+			return err
+		}
+		if err := c.saveCheckpoint(); err != nil {
+			return err
+		}
 	}
 
-	toCheck := header.Number.Uint64()
+	for c.lastCheckedBlock < checkUntil {
+		to := c.lastCheckedBlock + checkBatchBlocks
+		if to > checkUntil {
+			to = checkUntil
+		}
+		if err := c.checkBlocks(ctx, c.lastCheckedBlock+1, to); err != nil {
+			return err
+		}
+		c.lastCheckedBlock = to
+		if err := c.saveCheckpoint(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	const blockDelay = 100
-	if toCheck-c.lastCheckedBlock < blockDelay {
-		return nil
+func (c *disputeChecker) saveCheckpoint() error {
+	err := db.NewStore(c.db).SetDisputeCheckerBlock(c.lastCheckedBlock)
+	return errors.Wrap(err, "save dispute checker checkpoint")
+}
+
+// maxFiledDisputes is how many of the most recent disputes begun by the checker are kept.
+const maxFiledDisputes = 100
+
+// filedDispute identifies a dispute begun by the checker.
+type filedDispute struct {
+	RequestID uint64 `json:"requestId"`
+	Timestamp uint64 `json:"timestamp"`
+	Slot      int    `json:"slot"`
+}
+
+// isDisputeFiled returns whether the checker already began the dispute.
+func isDisputeFiled(DB db.DataServerProxy, dispute filedDispute) (bool, error) {
+	var filed []filedDispute
+	if _, err := db.NewStore(DB).GetJSON(db.DisputesFiledKey, &filed); err != nil {
+		return false, errors.Wrap(err, "get filed disputes")
 	}
+	for _, f := range filed {
+		if f == dispute {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// saveFiledDispute records a dispute begun by the checker.
+func saveFiledDispute(DB db.DataServerProxy, dispute filedDispute) error {
+	var filed []filedDispute
+	if _, err := db.NewStore(DB).GetJSON(db.DisputesFiledKey, &filed); err != nil {
+		return errors.Wrap(err, "get filed disputes")
+	}
+	filed = append(filed, dispute)
+	if len(filed) > maxFiledDisputes {
+		filed = filed[len(filed)-maxFiledDisputes:]
+	}
+	return errors.Wrap(db.NewStore(DB).PutJSON(db.DisputesFiledKey, filed), "save filed disputes")
+}
 
+// checkBlocks checks the values mined between the blocks, inclusive.
+// Every miner's value is compared with the local value history and with the values of the other miners.
+func (c *disputeChecker) checkBlocks(ctx context.Context, from, to uint64) error {
 	tokenAbi, err := abi.JSON(strings.NewReader(master.TellorLibraryABI))
 	if err != nil {
 		return errors.Wrap(err, "parse abi")
@@ -136,67 +315,211 @@ func (c *disputeChecker) Exec(ctx context.Context) error {
 	//just use nil for most of the variables, only using this object to call UnpackLog which only uses the abi
 	bar := bind.NewBoundContract(c.contract.Address, tokenAbi, nil, nil, nil)
 
-	checkUntil := toCheck - blockDelay
 	nonceSubmitID := tokenAbi.Events["NonceSubmitted"].ID
+	newValueID := tokenAbi.Events["NewValue"].ID
+	topics := []common.Hash{nonceSubmitID, newValueID}
+	if c.policy != nil && c.config.Disputes.AutoVote {
+		disputeAbi, err := abi.JSON(strings.NewReader(master.TellorDisputeABI))
+		if err != nil {
+			return errors.Wrap(err, "parse abi")
+		}
+		topics = append(topics, disputeAbi.Events["NewDispute"].ID)
+	}
+	lookbackFrom := uint64(0)
+	if from > submissionLookback {
+		lookbackFrom = from - submissionLookback
+	}
 	query := ethereum.FilterQuery{
-		FromBlock: big.NewInt(int64(c.lastCheckedBlock)),
-		ToBlock:   big.NewInt(int64(checkUntil)),
+		FromBlock: new(big.Int).SetUint64(lookbackFrom),
+		ToBlock:   new(big.Int).SetUint64(to),
 		Addresses: []common.Address{c.contract.Address},
-		Topics:    [][]common.Hash{{nonceSubmitID}},
+		Topics:    [][]common.Hash{topics},
 	}
 	logs, err := c.client.FilterLogs(ctx, query)
 	if err != nil {
 		return errors.Wrap(err, "filter eth logs")
 	}
-	blockTimes := make(map[uint64]time.Time)
+
+	var newValues []*master.TellorLibraryNewValue
+	var nonces []*master.TellorLibraryNonceSubmitted
+	var disputeLogs []types.Log
 	for _, l := range logs {
-		nonceSubmit := master.TellorLibraryNonceSubmitted{}
-		err := bar.UnpackLog(&nonceSubmit, "NonceSubmitted", l)
-		if err != nil {
-			return errors.Wrap(err, "unpack into object")
+		switch l.Topics[0] {
+		case newValueID:
+			// New values before the range were checked already.
+			if l.BlockNumber < from {
+				continue
+			}
+			newValue := &master.TellorLibraryNewValue{}
+			if err := bar.UnpackLog(newValue, "NewValue", l); err != nil {
+				return errors.Wrap(err, "unpack into object")
+			}
+			newValue.Raw = l
+			newValues = append(newValues, newValue)
+		case nonceSubmitID:
+			nonce := &master.TellorLibraryNonceSubmitted{}
+			if err := bar.UnpackLog(nonce, "NonceSubmitted", l); err != nil {
+				return errors.Wrap(err, "unpack into object")
+			}
+			nonce.Raw = l
+			nonces = append(nonces, nonce)
+		default:
+			if l.BlockNumber >= from {
+				disputeLogs = append(disputeLogs, l)
+			}
+		}
+	}
+
+	blockTimes := make(map[uint64]time.Time)
+	var suspected []*suspectedValue
+	var candidates []*DisputeCandidate
+	for _, mined := range groupSubmissions(newValues, nonces) {
+		if len(mined.submissions) < 5 {
+			level.Warn(c.logger).Log("msg", "missing submissions of a mined value", "reqID", mined.requestID, "timestamp", mined.timestamp, "found", len(mined.submissions))
 		}
-		blockTime, ok := blockTimes[l.BlockNumber]
-		if !ok {
-			header, err := c.client.HeaderByNumber(ctx, big.NewInt(int64(l.BlockNumber)))
+		for _, sub := range mined.submissions {
+			blockTime, ok := blockTimes[sub.block]
+			if !ok {
+				header, err := c.client.HeaderByNumber(ctx, new(big.Int).SetUint64(sub.block))
+				if err != nil {
+					return errors.Wrap(err, "get nonce block header")
+				}
+				blockTime = time.Unix(int64(header.Time), 0)
+				blockTimes[sub.block] = blockTime
+			}
+			candidate, result, err := c.checkSubmission(mined, sub, blockTime)
 			if err != nil {
-				return errors.Wrap(err, "get nonce block header")
+				return err
+			}
+			if candidate == nil {
+				level.Debug(c.logger).Log("msg", "value appears to be within expected range", "reqID", mined.requestID, "value", sub.value, "slot", sub.slot, "blockTime", blockTime.String())
+				continue
+			}
+			candidates = append(candidates, candidate)
+			level.Error(c.logger).Log(
+				"msg", "suspected incorrect value",
+				"reasons", strings.Join(candidate.Reasons, ","),
+				"reqID", mined.requestID,
+				"value", candidate.Value,
+				"median", candidate.Median,
+				"peerMedian", fmt.Sprintf("%.0f", candidate.PeerMedian),
+				"low", fmt.Sprintf("%.0f", candidate.Low),
+				"high", fmt.Sprintf("%.0f", candidate.High),
+				"miner", candidate.Miner,
+				"slot", candidate.Slot,
+				"tx", candidate.TxHash,
+				"blockTime", blockTime,
+			)
+			// Only values checked against the local value history are disputed automatically.
+			if result != nil && !result.WithinRange {
+				suspected = append(suspected, &suspectedValue{
+					requestID: mined.requestID,
+					timestamp: mined.timestamp,
+					value:     sub.value,
+					miner:     sub.miner,
+					result:    result,
+				})
 			}
-			blockTime = time.Unix(int64(header.Time), 0)
-			blockTimes[l.BlockNumber] = blockTime
 		}
-		for i, reqID := range nonceSubmit.RequestId {
-			result, err := CheckValueAtTime(c.config, nonceSubmit.RequestId[i].Uint64(), nonceSubmit.Value[i], blockTime)
+	}
+	if len(candidates) > 0 {
+		if err := saveDisputeCandidates(c.db, candidates); err != nil {
+			return err
+		}
+		if c.config.Alerting.Rules.DisputeCandidates {
+			for _, candidate := range candidates {
+				alerting.Notify(&alerting.Alert{
+					Rule:     alerting.RuleDisputeCandidate,
+					Key:      fmt.Sprintf("%s-%d", candidate.TxHash, candidate.RequestID),
+					Severity: alerting.SeverityWarning,
+					Summary:  fmt.Sprintf("possible dispute for request %d: %s", candidate.RequestID, strings.Join(candidate.Reasons, ", ")),
+					Details: map[string]string{
+						"miner":  candidate.Miner,
+						"value":  candidate.Value,
+						"txHash": candidate.TxHash,
+					},
+				})
+			}
+		}
+	}
+
+	if c.policy != nil && c.config.Disputes.AutoDispute {
+		for _, s := range suspected {
+			minerIndex, ok, err := c.disputeSlot(s)
 			if err != nil {
 				return err
-			} else if result == nil {
-				level.Warn(c.logger).Log("msg", "no value data", "reqid", reqID, "blockTime", blockTime)
+			}
+			if !ok {
+				level.Warn(c.logger).Log("msg", "miner not found in the mined value", "reqID", s.requestID, "miner", s.miner.Hex())
 				continue
 			}
-
-			if !result.WithinRange {
-				s := fmt.Sprintf("suspected incorrect value for requestID %d at %s:\n , nearest values:\n", reqID, blockTime)
-				for i, pt := range result.Datapoints {
-					s += fmt.Sprintf("\t%.0f, ", pt)
-					delta := blockTime.Sub(result.Times[i])
-					if delta > 0 {
-						s += fmt.Sprintf("%s before\n", delta.String())
-					} else {
-						s += fmt.Sprintf("%s after\n", (-delta).String())
-					}
-				}
-				s += fmt.Sprintf("value submitted by miner with address %s", nonceSubmit.Miner)
-				level.Error(c.logger).Log("msg", s)
-				filename := fmt.Sprintf("possible-dispute-%s.txt", blockTime)
-				err := ioutil.WriteFile(filename, []byte(s), 0655)
-				if err != nil {
-					level.Error(c.logger).Log("msg", "saving dispute data", "filename", filename, "err", err)
+			// The blocks are checked again when a later step fails so the disputes begun before are skipped.
+			filed := filedDispute{RequestID: s.requestID.Uint64(), Timestamp: s.timestamp.Uint64(), Slot: minerIndex}
+			isFiled, err := isDisputeFiled(c.db, filed)
+			if err != nil {
+				return err
+			}
+			if isFiled {
+				continue
+			}
+			sent, err := c.policy.BeginDispute(ctx, s.requestID.Uint64(), s.timestamp, minerIndex, s.miner, s.value, s.result)
+			if err != nil {
+				level.Error(c.logger).Log("msg", "begin dispute", "reqID", s.requestID, "miner", s.miner.Hex(), "err", err)
+			}
+			if sent {
+				if err := saveFiledDispute(c.db, filed); err != nil {
+					return err
 				}
-			} else {
-				level.Info(c.logger).Log("msg", "value appears to be within expected range", "reqID", reqID, "value", nonceSubmit.Value, "blockTime", blockTime.String())
 			}
-
 		}
 	}
-	c.lastCheckedBlock = checkUntil
+	if c.policy != nil && c.config.Disputes.AutoVote {
+		if err := c.voteOnDisputes(ctx, disputeLogs); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// checkSubmission compares a submission with the local value history, with the values of the other miners
+// and with the final mined value.
+// It returns a nil candidate when the value looks correct and
+// the result of the value history check which is nil without local data.
+func (c *disputeChecker) checkSubmission(mined *minedValue, sub *minerSubmission, blockTime time.Time) (*DisputeCandidate, *ValueCheckResult, error) {
+	var reasons []string
+	// A value that can't be checked, for example an expired manual entry, is checked only against the other miners
+	// so that the checker doesn't retry the same blocks forever.
+	result, err := CheckValueAtTime(c.config, mined.requestID.Uint64(), sub.value, blockTime)
+	if err != nil {
+		level.Warn(c.logger).Log("msg", "check value with the local value history", "reqid", mined.requestID, "blockTime", blockTime, "err", err)
+		result = nil
+	}
+	if result == nil {
+		level.Warn(c.logger).Log("msg", "no value data", "reqid", mined.requestID, "blockTime", blockTime)
+	} else if !result.WithinRange {
+		reasons = append(reasons, ReasonOutOfRange)
+	}
+
+	peerMedian, ok := mined.peerMedian(sub.slot)
+	if ok && relativeDeviation(bigToFloat(sub.value), peerMedian) > c.config.Disputes.OutlierThreshold {
+		reasons = append(reasons, ReasonOutlier)
+	}
+	if mined.median != nil && mined.medianDeviation(sub) > c.config.Disputes.OutlierThreshold {
+		reasons = append(reasons, ReasonMedianDeviation)
+	}
+	if len(reasons) == 0 {
+		return nil, result, nil
+	}
+
+	candidate := newDisputeCandidate(mined.requestID.Uint64(), result, blockTime, c.config.DisputeTimeDelta.Duration)
+	candidate.Reasons = reasons
+	candidate.Miner = sub.miner.Hex()
+	candidate.Value = sub.value.String()
+	candidate.Slot = sub.slot
+	candidate.Timestamp = mined.timestamp.Uint64()
+	candidate.Block = sub.block
+	candidate.TxHash = sub.txHash.Hex()
+	candidate.Median = mined.median.String()
+	candidate.PeerMedian = peerMedian
+	return candidate, result, nil
+}
@@ -5,11 +5,13 @@ package tracker
 
 import (
 	"context"
-	"os"
-	"path/filepath"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
@@ -27,7 +29,7 @@ func TestDisputeCheckerInRange(t *testing.T) {
 	proxy, err := db.OpenLocal(logging.NewLogger(), cfg, DB)
 	testutil.Ok(t, err)
 
-	if _, err := BuildIndexTrackers(logger, cfg, proxy, client); err != nil {
+	if _, err := BuildIndexTrackers(logger, cfg, proxy, client, NewFetcher(logger, cfg)); err != nil {
 		testutil.Ok(t, err)
 	}
 	contract, err := contracts.NewTellor(client)
@@ -37,8 +39,8 @@ func TestDisputeCheckerInRange(t *testing.T) {
 	execEthUsdPsrs(ctx, t, ethUSDPairs)
 	time.Sleep(2 * time.Second)
 	execEthUsdPsrs(ctx, t, ethUSDPairs)
-	disputeChecker := &disputeChecker{lastCheckedBlock: 500, config: cfg, logger: logger, client: client, contract: &contract}
-	testutil.Ok(t, disputeChecker.Exec(ctx))
+	disputeChecker := &disputeChecker{config: cfg, db: proxy, logger: logger, client: client, contract: &contract}
+	testutil.Ok(t, disputeChecker.checkBlocks(ctx, 1, 500))
 }
 
 func TestDisputeCheckerOutOfRange(t *testing.T) {
@@ -51,8 +53,8 @@ func TestDisputeCheckerOutOfRange(t *testing.T) {
 	defer t.Cleanup(cleanup)
 	proxy, err := db.OpenLocal(logging.NewLogger(), cfg, DB)
 	testutil.Ok(t, err)
-	disputeChecker := NewDisputeChecker(logger, cfg, client, &contract, 500)
-	if _, err := BuildIndexTrackers(logger, cfg, proxy, client); err != nil {
+	disputeChecker := NewDisputeChecker(logger, cfg, proxy, client, &contract, nil, 500)
+	if _, err := BuildIndexTrackers(logger, cfg, proxy, client, NewFetcher(logger, cfg)); err != nil {
 		testutil.Ok(t, err)
 	}
 	ethUSDPairs := indexes["ETH/USD"]
@@ -60,17 +62,106 @@ func TestDisputeCheckerOutOfRange(t *testing.T) {
 	execEthUsdPsrs(ctx, t, ethUSDPairs)
 	time.Sleep(2 * time.Second)
 	execEthUsdPsrs(ctx, t, ethUSDPairs)
-	testutil.Ok(t, disputeChecker.Exec(ctx))
+	testutil.Ok(t, disputeChecker.checkBlocks(ctx, 1, 500))
 
-	files, err := filepath.Glob("possible-dispute-*.txt")
-	if err != nil {
-		panic(err)
+	candidates, err := GetDisputeCandidates(proxy)
+	testutil.Ok(t, err)
+	testutil.Assert(t, len(candidates) >= 1, "expected a dispute candidate")
+	testutil.Assert(t, len(candidates[0].Datapoints) > 0, "expected the datapoints of the dispute candidate")
+	testutil.Equals(t, []string{ReasonOutOfRange}, candidates[0].Reasons)
+}
+
+func TestCheckSubmissionMedianDeviation(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	// The indexes without values so the value history check is skipped.
+	testutil.Ok(t, LoadReplayIndexes(cfg))
+	disputeChecker := &disputeChecker{config: cfg, logger: logging.NewLogger()}
+	// The final value doesn't match the submissions that are close to each other.
+	mined := &minedValue{requestID: big.NewInt(1), timestamp: big.NewInt(1600000000), median: big.NewInt(120)}
+	for i, v := range []int64{99, 100, 100, 101, 102} {
+		mined.submissions = append(mined.submissions, &minerSubmission{value: big.NewInt(v), slot: i})
+	}
+
+	candidate, _, err := disputeChecker.checkSubmission(mined, mined.submissions[1], time.Unix(1600000000, 0))
+	testutil.Ok(t, err)
+	testutil.Assert(t, candidate != nil, "expected a dispute candidate")
+	testutil.Equals(t, []string{ReasonMedianDeviation}, candidate.Reasons)
+	testutil.Equals(t, "120", candidate.Median)
+
+	mined.median = big.NewInt(100)
+	candidate, _, err = disputeChecker.checkSubmission(mined, mined.submissions[1], time.Unix(1600000000, 0))
+	testutil.Ok(t, err)
+	testutil.Assert(t, candidate == nil, "expected no dispute candidate")
+}
+
+func TestCheckSubmissionExpiredManualEntry(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	testutil.Ok(t, LoadReplayIndexes(cfg))
+	disputeChecker := &disputeChecker{config: cfg, logger: logging.NewLogger()}
+	// The manual value expired before the submission so the local value history check fails.
+	at := time.Now()
+	for _, index := range indexesForSymbol("USPCE") {
+		apiOracle.SetRequestValue(index.Identifier, at.Add(-time.Minute), apiOracle.PriceInfo{Price: 111.85, Volume: float64(at.Add(-time.Hour).Unix())})
 	}
-	testutil.Assert(t, len(files) >= 1, "expected a possible-dispute file")
+	_, _, err := PSRValueForTime(41, at)
+	testutil.NotOk(t, err)
 
-	for _, f := range files {
-		testutil.Ok(t, os.Remove(f))
+	mined := &minedValue{requestID: big.NewInt(41), timestamp: big.NewInt(at.Unix()), median: big.NewInt(111850)}
+	for i, v := range []int64{111850, 111850, 111850, 111850, 200000} {
+		mined.submissions = append(mined.submissions, &minerSubmission{value: big.NewInt(v), slot: i})
 	}
+	// The submissions are still compared with the other miners and the final value.
+	candidate, result, err := disputeChecker.checkSubmission(mined, mined.submissions[4], at)
+	testutil.Ok(t, err)
+	testutil.Assert(t, result == nil, "expected no result of the value history check")
+	testutil.Assert(t, candidate != nil, "expected a dispute candidate")
+	testutil.Equals(t, []string{ReasonOutlier, ReasonMedianDeviation}, candidate.Reasons)
+}
+
+// headClient is a client with a configurable head that records the log queries.
+type headClient struct {
+	contracts.ETHClient
+	head    uint64
+	queries [][2]uint64
+}
+
+func (c *headClient) HeaderByNumber(ctx context.Context, num *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(c.head)}, nil
+}
+
+func (c *headClient) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.queries = append(c.queries, [2]uint64{query.FromBlock.Uint64(), query.ToBlock.Uint64()})
+	return nil, nil
+}
+
+func TestDisputeCheckerCheckpoint(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logger, cfg, DB)
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	// Without a checkpoint or a start block it starts from the head.
+	client := &headClient{head: 1000}
+	testutil.Ok(t, NewDisputeChecker(logger, cfg, proxy, client, &contracts.Tellor{}, nil, 0).Exec(ctx))
+	testutil.Equals(t, 0, len(client.queries))
+
+	// A restart continues from the checkpoint.
+	client.head = 1200
+	testutil.Ok(t, NewDisputeChecker(logger, cfg, proxy, client, &contracts.Tellor{}, nil, 0).Exec(ctx))
+	testutil.Equals(t, [][2]uint64{{901 - submissionLookback, 1100}}, client.queries)
+
+	// Without a checkpoint it starts from the configured start block.
+	DB2, cleanup2 := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup2)
+	proxy2, err := db.OpenLocal(logger, cfg, DB2)
+	testutil.Ok(t, err)
+	cfg.Disputes.CheckerStartBlock = 500
+	client = &headClient{head: 1200}
+	testutil.Ok(t, NewDisputeChecker(logger, cfg, proxy2, client, &contracts.Tellor{}, nil, 0).Exec(ctx))
+	testutil.Equals(t, [][2]uint64{{500 - submissionLookback, 1100}}, client.queries)
 }
 
 func execEthUsdPsrs(ctx context.Context, t *testing.T, psrs []*IndexTracker) {
@@ -81,3 +172,31 @@ func execEthUsdPsrs(ctx context.Context, t *testing.T, psrs []*IndexTracker) {
 
 	}
 }
+
+func TestFiledDisputes(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logging.NewLogger(), cfg, DB)
+	testutil.Ok(t, err)
+
+	dispute := filedDispute{RequestID: 1, Timestamp: 1600000000, Slot: 2}
+	filed, err := isDisputeFiled(proxy, dispute)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !filed, "expected the dispute not to be filed")
+	testutil.Ok(t, saveFiledDispute(proxy, dispute))
+	filed, err = isDisputeFiled(proxy, dispute)
+	testutil.Ok(t, err)
+	testutil.Assert(t, filed, "expected the dispute to be filed")
+	filed, err = isDisputeFiled(proxy, filedDispute{RequestID: 1, Timestamp: 1600000000, Slot: 3})
+	testutil.Ok(t, err)
+	testutil.Assert(t, !filed, "expected the dispute of another slot not to be filed")
+
+	// Only the most recent are kept.
+	for i := 0; i < maxFiledDisputes; i++ {
+		testutil.Ok(t, saveFiledDispute(proxy, filedDispute{RequestID: 2, Timestamp: uint64(i)}))
+	}
+	filed, err = isDisputeFiled(proxy, dispute)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !filed, "expected the oldest dispute to be dropped")
+}
@@ -0,0 +1,265 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/util"
+)
+
+// Statuses of the audited dispute actions.
+const (
+	actionSkipped  = "skipped"
+	actionDryRun   = "dry-run"
+	actionDeclined = "declined"
+	actionSent     = "sent"
+	actionFailed   = "failed"
+)
+
+// DisputeFee returns the TRB amount required to begin a dispute.
+func DisputeFee(contract *contracts.Tellor) (*big.Int, error) {
+	fee, err := contract.Getter.GetUintVar(nil, rpc.Keccak256([]byte("disputeFee")))
+	if err != nil {
+		return nil, errors.Wrap(err, "get dispute fee")
+	}
+	return fee, nil
+}
+
+// ConfirmFunc asks whether to take the described action.
+type ConfirmFunc func(action string) bool
+
+// DisputePolicy decides which votes and disputes to send according to the checked values.
+// Every action respects the dry run setting, is confirmed when a ConfirmFunc is set
+// and is recorded in the audit log.
+type DisputePolicy struct {
+	logger   log.Logger
+	cfg      *config.Config
+	client   contracts.ETHClient
+	contract *contracts.Tellor
+	account  *rpc.Account
+	confirm  ConfirmFunc
+	auditMtx sync.Mutex
+}
+
+// NewDisputePolicy creates a dispute policy.
+// Without a ConfirmFunc the actions are taken unless the dry run setting is on.
+func NewDisputePolicy(
+	logger log.Logger,
+	cfg *config.Config,
+	client contracts.ETHClient,
+	contract *contracts.Tellor,
+	account *rpc.Account,
+	confirm ConfirmFunc,
+) *DisputePolicy {
+	return &DisputePolicy{
+		logger:   log.With(logger, "component", ComponentName),
+		cfg:      cfg,
+		client:   client,
+		contract: contract,
+		account:  account,
+		confirm:  confirm,
+	}
+}
+
+// auditEntry is a line of the audit log.
+type auditEntry struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	RequestID  uint64    `json:"requestId"`
+	DisputeID  string    `json:"disputeId,omitempty"`
+	Timestamp  string    `json:"timestamp,omitempty"`
+	Miner      string    `json:"miner,omitempty"`
+	MinerIndex int       `json:"minerIndex,omitempty"`
+	Support    *bool     `json:"support,omitempty"`
+	Value      string    `json:"value"`
+	Low        float64   `json:"low"`
+	High       float64   `json:"high"`
+	Deviation  float64   `json:"deviation"`
+	Datapoints int       `json:"datapoints"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+	Tx         string    `json:"tx,omitempty"`
+}
+
+func newAuditEntry(action string, requestID uint64, value *big.Int, result *ValueCheckResult) *auditEntry {
+	return &auditEntry{
+		Time:       time.Now(),
+		Action:     action,
+		RequestID:  requestID,
+		Value:      value.String(),
+		Low:        result.Low,
+		High:       result.High,
+		Deviation:  result.Deviation,
+		Datapoints: len(result.Datapoints),
+	}
+}
+
+// voteDecision returns whether there is enough data to vote on a disputed value
+// and whether the vote supports the dispute.
+func (p *DisputePolicy) voteDecision(result *ValueCheckResult) (vote bool, support bool) {
+	if result == nil || len(result.Datapoints) < p.cfg.Disputes.MinDatapoints {
+		return false, false
+	}
+	return true, !result.WithinRange
+}
+
+// disputeDecision returns whether a submitted value is far enough off to dispute it.
+func (p *DisputePolicy) disputeDecision(result *ValueCheckResult) bool {
+	if result == nil || len(result.Datapoints) < p.cfg.Disputes.MinDatapoints {
+		return false
+	}
+	return result.Deviation > p.cfg.Disputes.DisputeMultiple*p.cfg.DisputeThreshold
+}
+
+// VoteOnDispute votes on an open dispute according to the check of the disputed value.
+func (p *DisputePolicy) VoteOnDispute(ctx context.Context, disputeID *big.Int, requestID uint64, value *big.Int, result *ValueCheckResult) error {
+	vote, support := p.voteDecision(result)
+	if !vote {
+		level.Info(p.logger).Log("msg", "not enough data to vote on dispute", "disputeID", disputeID, "requestID", requestID)
+		return nil
+	}
+	voted, err := p.contract.Getter.DidVote(nil, disputeID, p.account.Address)
+	if err != nil {
+		return errors.Wrap(err, "check if already voted")
+	}
+	if voted {
+		return nil
+	}
+
+	entry := newAuditEntry("vote", requestID, value, result)
+	entry.DisputeID = disputeID.String()
+	entry.Support = &support
+	return p.act(ctx, entry, fmt.Sprintf("vote %v on dispute %v of request id %v", support, disputeID, requestID),
+		func(auth *bind.TransactOpts) (*types.Transaction, error) {
+			return p.contract.Caller.Vote(auth, disputeID, support)
+		})
+}
+
+// disputeHash returns the hash by which the contract identifies the disputes of a submitted value.
+func disputeHash(miner common.Address, requestID uint64, timestamp *big.Int) [32]byte {
+	return crypto.Keccak256Hash(
+		miner.Bytes(),
+		common.LeftPadBytes(new(big.Int).SetUint64(requestID).Bytes(), 32),
+		common.LeftPadBytes(timestamp.Bytes(), 32),
+	)
+}
+
+// BeginDispute disputes a submitted value when it is off by more than the dispute multiple
+// of the dispute threshold, it isn't disputed yet and the TRB balance covers the dispute fee.
+// It returns whether the dispute transaction was sent.
+func (p *DisputePolicy) BeginDispute(ctx context.Context, requestID uint64, timestamp *big.Int, minerIndex int, miner common.Address, value *big.Int, result *ValueCheckResult) (bool, error) {
+	if !p.disputeDecision(result) {
+		return false, nil
+	}
+
+	entry := newAuditEntry("dispute", requestID, value, result)
+	entry.Timestamp = timestamp.String()
+	entry.Miner = miner.Hex()
+	entry.MinerIndex = minerIndex
+
+	// Disputing a disputed value again opens a new round with a higher fee.
+	disputeID, err := p.contract.Getter.GetDisputeIdByDisputeHash(nil, disputeHash(miner, requestID, timestamp))
+	if err != nil {
+		return false, errors.Wrap(err, "get dispute id of the value")
+	}
+	if disputeID.Sign() > 0 {
+		level.Info(p.logger).Log("msg", "value already disputed", "requestID", requestID, "miner", entry.Miner, "disputeID", disputeID)
+		return false, nil
+	}
+
+	balance, err := p.contract.Getter.BalanceOf(nil, p.account.Address)
+	if err != nil {
+		return false, errors.Wrap(err, "fetch balance")
+	}
+	fee, err := DisputeFee(p.contract)
+	if err != nil {
+		return false, err
+	}
+	if balance.Cmp(fee) < 0 {
+		entry.Status = actionSkipped
+		entry.Reason = fmt.Sprintf("insufficient balance TRB actual: %v, TRB required: %v", util.FormatERC20Balance(balance), util.FormatERC20Balance(fee))
+		level.Warn(p.logger).Log("msg", "can't afford to dispute value", "requestID", requestID, "miner", entry.Miner, "reason", entry.Reason)
+		p.audit(entry)
+		return false, nil
+	}
+
+	err = p.act(ctx, entry, fmt.Sprintf("dispute value %v of request id %v at %v from miner %v", value, requestID, timestamp, entry.Miner),
+		func(auth *bind.TransactOpts) (*types.Transaction, error) {
+			return p.contract.Caller.BeginDispute(auth, new(big.Int).SetUint64(requestID), timestamp, big.NewInt(int64(minerIndex)))
+		})
+	return entry.Status == actionSent, err
+}
+
+// act sends the transaction of an action unless it is a dry run or isn't confirmed
+// and records the outcome in the audit log.
+func (p *DisputePolicy) act(ctx context.Context, entry *auditEntry, description string, send func(*bind.TransactOpts) (*types.Transaction, error)) error {
+	var err error
+	switch {
+	case p.cfg.Disputes.DryRun:
+		entry.Status = actionDryRun
+		level.Info(p.logger).Log("msg", "dry run, would "+description)
+	case p.confirm != nil && !p.confirm(description):
+		entry.Status = actionDeclined
+		level.Info(p.logger).Log("msg", "declined to "+description)
+	default:
+		var auth *bind.TransactOpts
+		auth, err = rpc.PrepareEthTransaction(ctx, p.client, p.account)
+		var tx *types.Transaction
+		if err == nil {
+			tx, err = send(auth)
+		}
+		if err != nil {
+			entry.Status = actionFailed
+			entry.Reason = err.Error()
+			err = errors.Wrapf(err, "%s", description)
+		} else {
+			entry.Status = actionSent
+			entry.Tx = tx.Hash().Hex()
+			level.Info(p.logger).Log("msg", "sent transaction to "+description, "tx", entry.Tx)
+		}
+	}
+	p.audit(entry)
+	return err
+}
+
+// audit appends the entry to the audit log.
+func (p *DisputePolicy) audit(entry *auditEntry) {
+	if p.cfg.Disputes.AuditLog == "" {
+		return
+	}
+	p.auditMtx.Lock()
+	defer p.auditMtx.Unlock()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		level.Error(p.logger).Log("msg", "encode dispute audit entry", "err", err)
+		return
+	}
+	f, err := os.OpenFile(p.cfg.Disputes.AuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		level.Error(p.logger).Log("msg", "open dispute audit log", "err", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		level.Error(p.logger).Log("msg", "write dispute audit log", "err", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestDisputePolicyDecisions(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	cfg.DisputeThreshold = 0.01
+	cfg.Disputes.DisputeMultiple = 5
+	cfg.Disputes.MinDatapoints = 3
+	policy := NewDisputePolicy(logging.NewLogger(), &cfg, nil, nil, nil, nil)
+
+	enoughData := []float64{1, 1, 1}
+	vote, support := policy.voteDecision(&ValueCheckResult{WithinRange: false, Datapoints: enoughData})
+	testutil.Assert(t, vote && support, "should support the dispute of a value out of range")
+	vote, support = policy.voteDecision(&ValueCheckResult{WithinRange: true, Datapoints: enoughData})
+	testutil.Assert(t, vote && !support, "should oppose the dispute of a value within range")
+	vote, _ = policy.voteDecision(&ValueCheckResult{Datapoints: enoughData[:2]})
+	testutil.Assert(t, !vote, "shouldn't vote without enough datapoints")
+	vote, _ = policy.voteDecision(nil)
+	testutil.Assert(t, !vote, "shouldn't vote without data")
+
+	testutil.Assert(t, policy.disputeDecision(&ValueCheckResult{Deviation: 0.06, Datapoints: enoughData}), "should dispute a value off by more than the multiple")
+	testutil.Assert(t, !policy.disputeDecision(&ValueCheckResult{Deviation: 0.04, Datapoints: enoughData}), "shouldn't dispute a value off by less than the multiple")
+	testutil.Assert(t, !policy.disputeDecision(&ValueCheckResult{Deviation: 0.5, Datapoints: enoughData[:1]}), "shouldn't dispute without enough datapoints")
+}
+
+func TestDisputePolicyAct(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	cfg.Disputes.AuditLog = filepath.Join(t.TempDir(), "audit.log")
+	ctx := context.Background()
+
+	send := func(*bind.TransactOpts) (*types.Transaction, error) {
+		t.Fatal("no transaction should be sent")
+		return nil, nil
+	}
+	entry := func() *auditEntry {
+		return newAuditEntry("vote", 1, big.NewInt(100), &ValueCheckResult{Datapoints: []float64{1, 2, 3}})
+	}
+
+	// Dry run.
+	cfg.Disputes.DryRun = true
+	policy := NewDisputePolicy(logging.NewLogger(), &cfg, nil, nil, nil, nil)
+	testutil.Ok(t, policy.act(ctx, entry(), "vote", send))
+
+	// Declined confirmation.
+	cfg.Disputes.DryRun = false
+	var asked string
+	policy = NewDisputePolicy(logging.NewLogger(), &cfg, nil, nil, nil, func(action string) bool {
+		asked = action
+		return false
+	})
+	testutil.Ok(t, policy.act(ctx, entry(), "vote true on dispute 1", send))
+	testutil.Equals(t, "vote true on dispute 1", asked)
+
+	f, err := os.Open(cfg.Disputes.AuditLog)
+	testutil.Ok(t, err)
+	defer f.Close()
+	var statuses []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e auditEntry
+		testutil.Ok(t, json.Unmarshal(scanner.Bytes(), &e))
+		testutil.Equals(t, "vote", e.Action)
+		testutil.Equals(t, "100", e.Value)
+		testutil.Equals(t, 3, e.Datapoints)
+		statuses = append(statuses, e.Status)
+	}
+	testutil.Ok(t, scanner.Err())
+	testutil.Equals(t, []string{actionDryRun, actionDeclined}, statuses)
+}
@@ -5,15 +5,13 @@ package tracker
 
 import (
 	"context"
-	"fmt"
-
-	"strings"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/alerting"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
@@ -23,21 +21,23 @@ import (
 const DisputeTrackerName = "DisputeTracker2"
 
 type DisputeTracker struct {
-	db       db.DataServerProxy
+	store    *db.Store
 	contract *contracts.Tellor
 	account  *rpc.Account
 	logger   log.Logger
 	config   *config.Config
+	// status is the last known stake status of the account.
+	status *big.Int
 }
 
 func (b *DisputeTracker) String() string {
 	return DisputeTrackerName
 }
 
-func NewDisputeTracker(logger log.Logger, config *config.Config, db db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *DisputeTracker {
+func NewDisputeTracker(logger log.Logger, config *config.Config, proxy db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *DisputeTracker {
 	return &DisputeTracker{
 		config:   config,
-		db:       db,
+		store:    db.NewStore(proxy),
 		contract: contract,
 		account:  account,
 		logger:   log.With(logger, "component", ComponentName),
@@ -51,9 +51,9 @@ func (b *DisputeTracker) Exec(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "getting staker info")
 	}
-	enc := hexutil.EncodeBig(status)
-	level.Info(b.logger).Log("msg", "staker status", "status", enc)
-	err = b.db.Put(db.DisputeStatusKey, []byte(enc))
+	level.Info(b.logger).Log("msg", "staker status", "status", status)
+	b.alertStatus(status)
+	err = b.store.SetDisputeStatus(status)
 	if err != nil {
 		return errors.Wrap(err, "storing dispute")
 	}
@@ -71,11 +71,36 @@ func (b *DisputeTracker) Exec(ctx context.Context) error {
 			level.Error(b.logger).Log("msg", "getting staker dispute status for miner", "address", addr, "err", err)
 		}
 		level.Info(b.logger).Log("msg", "whitelisted miner", "address", addr, "status", status)
-		dbKey := fmt.Sprintf("%s-%s", strings.ToLower(address.Hex()), db.DisputeStatusKey)
-		err = b.db.Put(dbKey, []byte(hexutil.EncodeBig(status)))
+		err = b.store.SetMinerDisputeStatus(address, status)
 		if err != nil {
 			level.Error(b.logger).Log("msg", "storing staker dispute status", "err", err)
 		}
 	}
 	return nil
 }
+
+// alertStatus alerts when the stake status changes or when the account is not staked at start.
+func (b *DisputeTracker) alertStatus(status *big.Int) {
+	prev := b.status
+	b.status = status
+	if !b.config.Alerting.Rules.StakeStatus {
+		return
+	}
+	if prev == nil && status.Cmp(big.NewInt(1)) == 0 || prev != nil && prev.Cmp(status) == 0 {
+		return
+	}
+	details := map[string]string{
+		"account": b.account.Address.Hex(),
+		"status":  status.String(),
+	}
+	if prev != nil {
+		details["previous"] = prev.String()
+	}
+	alerting.Notify(&alerting.Alert{
+		Rule:     alerting.RuleStakeStatus,
+		Key:      status.String(),
+		Severity: alerting.SeverityCritical,
+		Summary:  "stake status changed to " + status.String(),
+		Details:  details,
+	})
+}
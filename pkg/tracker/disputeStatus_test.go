@@ -10,7 +10,6 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
@@ -49,11 +48,9 @@ func TestDisputeStatus(t *testing.T) {
 	testutil.Ok(t, err)
 	tracker := NewDisputeTracker(logger, cfg, proxy, &contract, &account)
 	testutil.Ok(t, tracker.Exec(context.Background()))
-	v, err := proxy.Get(db.DisputeStatusKey)
+	b, _, err := db.NewStore(proxy).GetDisputeStatus()
 	testutil.Ok(t, err)
-	b, err := hexutil.DecodeBig(string(v))
-	testutil.Ok(t, err)
-	t.Logf("Dispute Status stored: %v\n", string(v))
+	t.Logf("Dispute Status stored: %v\n", b)
 	testutil.Equals(t, b.Cmp(big.NewInt(1)), 0, "dispute status from client did not match what should have been stored in DB. %s != %s", b, "one")
 	proxy.Close()
 }
@@ -76,11 +73,9 @@ func TestDisputeStatusNegativeBalance(t *testing.T) {
 	testutil.Ok(t, err)
 	tracker := NewDisputeTracker(logger, cfg, proxy, &contract, &account)
 	testutil.Ok(t, tracker.Exec(context.Background()))
-	v, err := proxy.Get(db.DisputeStatusKey)
-	testutil.Ok(t, err)
-	b, err := hexutil.DecodeBig(string(v))
+	b, _, err := db.NewStore(proxy).GetDisputeStatus()
 	testutil.Ok(t, err)
-	t.Logf("Dispute Status stored: %v\n", string(v))
+	t.Logf("Dispute Status stored: %v\n", b)
 	if b.Cmp(big.NewInt(1)) != 0 {
 		testutil.Ok(t, errors.Errorf("Dispute Status from client did not match what should have been stored in DB. %s != %s", b, "one"))
 	}
@@ -13,7 +13,7 @@ import (
 )
 
 // CreateTracker a tracker instance by its well-known name.
-func createTracker(logger log.Logger, name string, config *config.Config, db db.DataServerProxy, client contracts.ETHClient, contract *contracts.Tellor, account *rpc.Account) ([]Tracker, error) {
+func createTracker(logger log.Logger, name string, config *config.Config, db db.DataServerProxy, client contracts.ETHClient, contract *contracts.Tellor, account *rpc.Account, fetcher *Fetcher) ([]Tracker, error) {
 	switch name {
 	case "timeOut":
 		{
@@ -21,7 +21,7 @@ func createTracker(logger log.Logger, name string, config *config.Config, db db.
 		}
 	case "balance":
 		{
-			return []Tracker{NewBalanceTracker(logger, db, client, account)}, nil
+			return []Tracker{NewBalanceTracker(logger, config, db, client, account)}, nil
 		}
 	case "disputeStatus":
 		{
@@ -29,22 +29,22 @@ func createTracker(logger log.Logger, name string, config *config.Config, db db.
 		}
 	case "gas":
 		{
-			return []Tracker{NewGasTracker(logger, db, client)}, nil
+			return []Tracker{NewGasTracker(logger, db, client, fetcher)}, nil
 		}
 	case "currentVariables":
 		{
-			return []Tracker{NewCurrentVariablesTracker(logger, db, contract, account)}, nil
+			return []Tracker{NewCurrentVariablesTracker(logger, config, db, contract, account)}, nil
 		}
 	case "tributeBalance":
 		{
-			return []Tracker{NewTributeTracker(logger, db, contract, account)}, nil
+			return []Tracker{NewTributeTracker(logger, config, db, contract, account)}, nil
 		}
 	case "indexers":
 		{
-			return BuildIndexTrackers(logger, config, db, client)
+			return BuildIndexTrackers(logger, config, db, client, fetcher)
 		}
 	case "disputeChecker":
-		return []Tracker{NewDisputeChecker(logger, config, client, contract, 0)}, nil
+		return []Tracker{NewDisputeChecker(logger, config, db, client, contract, account, 0)}, nil
 	default:
 		return nil, errors.Errorf("no tracker with the name %s", name)
 	}
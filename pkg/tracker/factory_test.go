@@ -25,43 +25,43 @@ func TestCreateTracker(t *testing.T) {
 	proxy, err := db.OpenLocal(logger, cfg, DB)
 	testutil.Ok(t, err)
 
-	balanceTracker, _ := createTracker(logger, "balance", cfg, proxy, client, nil, nil)
+	balanceTracker, _ := createTracker(logger, "balance", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	if balanceTracker[0].String() != BalanceTrackerName {
 		testutil.Ok(t, errors.Errorf("Expected BalanceTracker but got %s", balanceTracker[0].String()))
 	}
 
-	currentVariablesTracker, _ := createTracker(logger, "currentVariables", cfg, proxy, client, nil, nil)
+	currentVariablesTracker, _ := createTracker(logger, "currentVariables", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	if currentVariablesTracker[0].String() != "CurrentVariablesTracker" {
 		testutil.Ok(t, errors.Errorf("Expected CurrentVariablesTracker but got %s", currentVariablesTracker[0].String()))
 	}
 
-	disputeStatusTracker, _ := createTracker(logger, "disputeStatus", cfg, proxy, client, nil, nil)
+	disputeStatusTracker, _ := createTracker(logger, "disputeStatus", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	if disputeStatusTracker[0].String() != DisputeTrackerName {
 		testutil.Ok(t, errors.Errorf("Expected DisputeTracker but got %s", disputeStatusTracker[0].String()))
 	}
 
-	gasTracker, _ := createTracker(logger, "gas", cfg, proxy, client, nil, nil)
+	gasTracker, _ := createTracker(logger, "gas", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	if gasTracker[0].String() != "GasTracker" {
 		testutil.Ok(t, errors.Errorf("Expected GasTracker but got %s", gasTracker[0].String()))
 	}
 
-	tributeBalanceTracker, _ := createTracker(logger, "tributeBalance", cfg, proxy, client, nil, nil)
+	tributeBalanceTracker, _ := createTracker(logger, "tributeBalance", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	if tributeBalanceTracker[0].String() != "TributeTracker" {
 		testutil.Ok(t, errors.Errorf("Expected TributeTracker but got %s", tributeBalanceTracker[0].String()))
 	}
 
-	indexersTracker, err := createTracker(logger, "indexers", cfg, proxy, client, nil, nil)
+	indexersTracker, err := createTracker(logger, "indexers", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	testutil.Ok(t, err, "build IndexTracker")
 	if len(indexersTracker) == 0 {
 		testutil.Ok(t, errors.Errorf("build all IndexTrackers: only tracking %d indexes", len(indexersTracker)))
 	}
 
-	disputeChecker, _ := createTracker(logger, "disputeChecker", cfg, proxy, client, nil, nil)
+	disputeChecker, _ := createTracker(logger, "disputeChecker", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	if disputeChecker[0].String() != "DisputeChecker" {
 		testutil.Ok(t, errors.Errorf("Expected DisputeChecker but got %s", disputeChecker[0].String()))
 	}
 
-	_, err = createTracker(logger, "badTracker", cfg, proxy, client, nil, nil)
+	_, err = createTracker(logger, "badTracker", cfg, proxy, client, nil, nil, NewFetcher(logger, cfg))
 	testutil.Assert(t, err != nil, "expected error but instead received tracker")
 
 }
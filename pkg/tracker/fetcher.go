@@ -0,0 +1,346 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"golang.org/x/time/rate"
+)
+
+// fetchMinBackoff is the delay before the first retry of a failed request.
+const fetchMinBackoff = 500 * time.Millisecond
+
+var errFetchPermanent = errors.New("request failed with a non retryable status code")
+
+// FetchRequest holds info for a request.
+type FetchRequest struct {
+	// method defaults to GET.
+	method   string
+	queryURL string
+	headers  map[string]string
+	body     []byte
+	timeout  time.Duration
+	// secrets are values that are redacted from all log lines and errors.
+	secrets []string
+}
+
+// key identifies identical requests for deduplication and caching.
+func (r *FetchRequest) key() string {
+	var b strings.Builder
+	b.WriteString(r.methodOrDefault())
+	b.WriteString(" ")
+	b.WriteString(r.queryURL)
+	names := make([]string, 0, len(r.headers))
+	for name := range r.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n" + name + ": " + r.headers[name])
+	}
+	b.WriteString("\n\n")
+	b.Write(r.body)
+	return b.String()
+}
+
+func (r *FetchRequest) methodOrDefault() string {
+	if r.method == "" {
+		return http.MethodGet
+	}
+	return r.method
+}
+
+// redact replaces all secrets of the request in s.
+func (r *FetchRequest) redact(s string) string {
+	for _, secret := range r.secrets {
+		if secret == "" {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, "<redacted>")
+		// Errors from the HTTP client include the URL in its escaped form.
+		s = strings.ReplaceAll(s, url.QueryEscape(secret), "<redacted>")
+	}
+	return s
+}
+
+// redactErr returns err with all secrets of the request redacted from its message.
+func (r *FetchRequest) redactErr(err error) error {
+	msg := err.Error()
+	if redacted := r.redact(msg); redacted != msg {
+		return errors.New(redacted)
+	}
+	return err
+}
+
+// Fetcher is the HTTP fetch scheduler shared by all trackers.
+// It limits the request rate per host with a token bucket, retries failed requests
+// with exponential backoff and jitter, respects the Retry-After header, and
+// deduplicates and briefly caches responses for identical requests.
+type Fetcher struct {
+	logger     log.Logger
+	client     *http.Client
+	rateLimit  float64
+	rateBurst  int
+	hostLimits map[string]float64
+	cacheTTL   time.Duration
+	maxBackoff time.Duration
+
+	mtx      sync.Mutex
+	hosts    map[string]*fetchHost
+	inflight map[string]*fetchCall
+	cache    map[string]*fetchResponse
+}
+
+// fetchHost holds the throttling state of a single host.
+type fetchHost struct {
+	limiter *rate.Limiter
+	// blockedUntil is set from the Retry-After header of a throttled response.
+	blockedUntil time.Time
+}
+
+// fetchCall is a request in flight that identical requests wait for.
+type fetchCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+type fetchResponse struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewFetcher creates a fetch scheduler configured from the fetch settings in the config.
+func NewFetcher(logger log.Logger, cfg *config.Config) *Fetcher {
+	return &Fetcher{
+		logger:     log.With(logger, "component", ComponentName),
+		client:     &http.Client{},
+		rateLimit:  cfg.FetchRateLimit,
+		rateBurst:  cfg.FetchRateBurst,
+		hostLimits: cfg.FetchHostRateLimits,
+		cacheTTL:   cfg.FetchCacheTTL.Duration,
+		maxBackoff: cfg.FetchMaxBackoff.Duration,
+		hosts:      make(map[string]*fetchHost),
+		inflight:   make(map[string]*fetchCall),
+		cache:      make(map[string]*fetchResponse),
+	}
+}
+
+// Fetch returns the response payload for the request.
+// Identical requests that are in flight or were completed within
+// the cache TTL share the same response.
+func (f *Fetcher) Fetch(ctx context.Context, req *FetchRequest) ([]byte, error) {
+	key := req.key()
+
+	f.mtx.Lock()
+	if resp, ok := f.cache[key]; ok && time.Now().Before(resp.expires) {
+		f.mtx.Unlock()
+		return resp.data, nil
+	}
+	if call, ok := f.inflight[key]; ok {
+		f.mtx.Unlock()
+		select {
+		case <-call.done:
+			return call.data, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &fetchCall{done: make(chan struct{})}
+	f.inflight[key] = call
+	f.mtx.Unlock()
+
+	call.data, call.err = f.fetchWithRetries(ctx, req)
+
+	f.mtx.Lock()
+	delete(f.inflight, key)
+	now := time.Now()
+	for k, resp := range f.cache {
+		if now.After(resp.expires) {
+			delete(f.cache, k)
+		}
+	}
+	if call.err == nil && f.cacheTTL > 0 {
+		f.cache[key] = &fetchResponse{data: call.data, expires: now.Add(f.cacheTTL)}
+	}
+	f.mtx.Unlock()
+	close(call.done)
+
+	return call.data, call.err
+}
+
+func (f *Fetcher) fetchWithRetries(ctx context.Context, req *FetchRequest) ([]byte, error) {
+	u, err := url.Parse(req.queryURL)
+	if err != nil {
+		return nil, req.redactErr(errors.Wrap(err, "parse query URL"))
+	}
+	host := f.host(u.Host)
+
+	ctx, cancel := context.WithTimeout(ctx, req.timeout)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		if err := f.wait(ctx, host); err != nil {
+			return nil, errors.Wrap(err, "waiting for the host rate limit")
+		}
+		data, retryAfter, err := f.fetch(ctx, req)
+		if err == nil {
+			return data, nil
+		}
+		permanent := errors.Cause(err) == errFetchPermanent
+		err = req.redactErr(err)
+		if permanent {
+			return nil, err
+		}
+		level.Warn(f.logger).Log(
+			"msg", "fetching data",
+			"from", req.redact(req.queryURL),
+			"attempt", attempt,
+			"err", err,
+		)
+
+		delay := f.backoff(attempt)
+		if retryAfter > 0 {
+			f.mtx.Lock()
+			host.blockedUntil = time.Now().Add(retryAfter)
+			f.mtx.Unlock()
+			if retryAfter > delay {
+				delay = retryAfter
+			}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrap(err, "retry timeout expired, last error is wrapped")
+		case <-timer.C:
+		}
+	}
+}
+
+// fetch makes a single request attempt.
+// It returns the delay requested by the server when throttled.
+func (f *Fetcher) fetch(ctx context.Context, req *FetchRequest) ([]byte, time.Duration, error) {
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.methodOrDefault(), req.queryURL, body)
+	if err != nil {
+		return nil, 0, errors.Wrap(errFetchPermanent, err.Error())
+	}
+	for name, value := range req.headers {
+		httpReq.Header.Set(name, value)
+	}
+	if req.body != nil && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	r, err := f.client.Do(httpReq)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "read response body")
+	}
+
+	if r.StatusCode >= 200 && r.StatusCode <= 299 {
+		return data, 0, nil
+	}
+	err = errors.Errorf("response status code:%v, payload:%s", r.StatusCode, data)
+	switch r.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return nil, parseRetryAfter(r.Header.Get("Retry-After")), err
+	case http.StatusRequestTimeout:
+		return nil, 0, err
+	}
+	// Other client errors won't succeed on retry.
+	if r.StatusCode >= 400 && r.StatusCode <= 499 {
+		return nil, 0, errors.Wrap(errFetchPermanent, err.Error())
+	}
+	return nil, 0, err
+}
+
+// host returns the throttling state for a host and creates it on first use.
+func (f *Fetcher) host(name string) *fetchHost {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	h, ok := f.hosts[name]
+	if !ok {
+		limit := rate.Inf
+		if l, ok := f.hostLimits[name]; ok {
+			limit = rate.Limit(l)
+		} else if f.rateLimit > 0 {
+			limit = rate.Limit(f.rateLimit)
+		}
+		burst := f.rateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		h = &fetchHost{limiter: rate.NewLimiter(limit, burst)}
+		f.hosts[name] = h
+	}
+	return h
+}
+
+// wait blocks until the host accepts a new request.
+func (f *Fetcher) wait(ctx context.Context, host *fetchHost) error {
+	f.mtx.Lock()
+	blocked := time.Until(host.blockedUntil)
+	f.mtx.Unlock()
+	if blocked > 0 {
+		timer := time.NewTimer(blocked)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return host.limiter.Wait(ctx)
+}
+
+// backoff returns the exponential retry delay for the attempt with half of it randomized.
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	delay := f.maxBackoff
+	if attempt < 30 && fetchMinBackoff<<uint(attempt) < delay {
+		delay = fetchMinBackoff << uint(attempt)
+	}
+	if delay <= 0 {
+		delay = fetchMinBackoff
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// parseRetryAfter parses the Retry-After header which holds
+// either the delay in seconds or a HTTP date.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestFetchRetry(t *testing.T) {
+	req := &FetchRequest{queryURL: "https://api.binance.com/api/v1/klines?symbol=ETHBTC&interval=1d&limit=1", timeout: time.Duration(5 * time.Second)}
+
+	res, err := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t)).Fetch(context.Background(), req)
+	testutil.Ok(t, err)
+	t.Logf("Result from query: %s\n", string(res))
+
+}
+
+func TestFetchWithErrors(t *testing.T) {
+	req := &FetchRequest{queryURL: "https://badendpoint.com/api/v1/klines?symbol=ETHBTC&interval=1d&limit=1", timeout: time.Duration(2000 * time.Millisecond)}
+	_, err := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t)).Fetch(context.Background(), req)
+	if err == nil {
+		testutil.Ok(t, errors.New("Bad endpoint test should have errored"))
+
+	}
+}
+
+func TestFetchBodyError(t *testing.T) {
+	req := &FetchRequest{queryURL: "https://api.binance.com/api/v1/klines?symbol=BADPAIR&interval=1d&limit=1", timeout: time.Duration(1 * time.Second)}
+
+	_, err := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t)).Fetch(context.Background(), req)
+	if err == nil {
+		testutil.Ok(t, errors.New("Bad endpoint test should have errored"))
+	}
+}
+
+func TestFetchRetryAfter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"price":1}`))
+	}))
+	defer srv.Close()
+
+	fetcher := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t))
+	start := time.Now()
+	res, err := fetcher.Fetch(context.Background(), &FetchRequest{queryURL: srv.URL, timeout: 5 * time.Second})
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"price":1}`, string(res))
+	testutil.Equals(t, int32(2), atomic.LoadInt32(&calls))
+	testutil.Assert(t, time.Since(start) >= time.Second, "the retry should wait for the Retry-After delay")
+}
+
+func TestFetchPermanentError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	fetcher := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t))
+	_, err := fetcher.Fetch(context.Background(), &FetchRequest{queryURL: srv.URL, timeout: 5 * time.Second})
+	testutil.NotOk(t, err)
+	testutil.Equals(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFetchDeduplication(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"price":1}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.OpenTestConfig(t)
+	fetcher := NewFetcher(logging.NewLogger(), cfg)
+
+	// Concurrent identical requests share a single call.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := fetcher.Fetch(context.Background(), &FetchRequest{queryURL: srv.URL, timeout: 5 * time.Second})
+			testutil.Ok(t, err)
+		}()
+	}
+	wg.Wait()
+	testutil.Equals(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Following requests are served from the cache.
+	_, err := fetcher.Fetch(context.Background(), &FetchRequest{queryURL: srv.URL, timeout: 5 * time.Second})
+	testutil.Ok(t, err)
+	testutil.Equals(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Different requests are not.
+	_, err = fetcher.Fetch(context.Background(), &FetchRequest{queryURL: srv.URL + "?other", timeout: 5 * time.Second})
+	testutil.Ok(t, err)
+	testutil.Equals(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetchRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"price":1}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.OpenTestConfig(t)
+	fetcher := NewFetcher(logging.NewLogger(), cfg)
+	fetcher.rateLimit = 10
+	fetcher.rateBurst = 1
+	fetcher.cacheTTL = 0
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := fetcher.Fetch(context.Background(), &FetchRequest{queryURL: srv.URL, timeout: 5 * time.Second})
+		testutil.Ok(t, err)
+	}
+	// The first request uses the burst and each following one waits 100ms.
+	testutil.Assert(t, time.Since(start) >= 300*time.Millisecond, "requests should be rate limited")
+}
+
+func TestFetchHeadersAndBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		testutil.Equals(t, http.MethodPost, r.Method)
+		testutil.Equals(t, "key", r.Header.Get("X-Api-Key"))
+		testutil.Equals(t, "application/json", r.Header.Get("Content-Type"))
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	fetcher := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t))
+	req := &FetchRequest{
+		method:   http.MethodPost,
+		queryURL: srv.URL,
+		headers:  map[string]string{"X-Api-Key": "key"},
+		body:     []byte(`{"query":"{ pair }"}`),
+		timeout:  5 * time.Second,
+	}
+	res, err := fetcher.Fetch(context.Background(), req)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"query":"{ pair }"}`, string(res))
+}
+
+func TestFetchRedactsSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("invalid key " + r.URL.Query().Get("key")))
+	}))
+	defer srv.Close()
+
+	fetcher := NewFetcher(logging.NewLogger(), config.OpenTestConfig(t))
+	req := &FetchRequest{
+		queryURL: srv.URL + "?key=s3cr3t",
+		timeout:  5 * time.Second,
+		secrets:  []string{"s3cr3t"},
+	}
+	_, err := fetcher.Fetch(context.Background(), req)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, !strings.Contains(err.Error(), "s3cr3t"), "error contains the secret: %v", err)
+	testutil.Equals(t, srv.URL+"?key=<redacted>", req.redact(req.queryURL))
+}
@@ -9,7 +9,6 @@ import (
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -21,9 +20,10 @@ import (
 // GasTracker is the struct that maintains the latest gasprices.
 // note the prices are actually stored in the DB.
 type GasTracker struct {
-	db     db.DataServerProxy
-	client contracts.ETHClient
-	logger log.Logger
+	store   *db.Store
+	client  contracts.ETHClient
+	fetcher *Fetcher
+	logger  log.Logger
 }
 
 // GasPriceModel is what ETHGasStation returns from queries. Not all fields are filled in.
@@ -37,11 +37,12 @@ func (b *GasTracker) String() string {
 	return "GasTracker"
 }
 
-func NewGasTracker(logger log.Logger, db db.DataServerProxy, client contracts.ETHClient) *GasTracker {
+func NewGasTracker(logger log.Logger, proxy db.DataServerProxy, client contracts.ETHClient, fetcher *Fetcher) *GasTracker {
 	return &GasTracker{
-		db:     db,
-		client: client,
-		logger: log.With(logger, "component", ComponentName),
+		store:   db.NewStore(proxy),
+		client:  client,
+		fetcher: fetcher,
+		logger:  log.With(logger, "component", ComponentName),
 	}
 
 }
@@ -57,7 +58,7 @@ func (b *GasTracker) Exec(ctx context.Context) error {
 	if big.NewInt(1).Cmp(netID) == 0 {
 		url := "https://ethgasstation.info/json/ethgasAPI.json"
 		req := &FetchRequest{queryURL: url, timeout: time.Duration(15 * time.Second)}
-		payload, err := fetchWithRetries(b.logger, req)
+		payload, err := b.fetcher.Fetch(ctx, req)
 		if err != nil {
 			gasPrice, err = b.client.SuggestGasPrice(ctx)
 			if err != nil {
@@ -85,6 +86,5 @@ func (b *GasTracker) Exec(ctx context.Context) error {
 		}
 	}
 
-	enc := hexutil.EncodeBig(gasPrice)
-	return b.db.Put(db.GasKey, []byte(enc))
+	return b.store.SetGasPrice(gasPrice)
 }
@@ -25,13 +25,14 @@ func TestETHGasStation(t *testing.T) {
 	defer t.Cleanup(cleanup)
 	proxy, err := db.OpenLocal(logger, cfg, DB)
 	testutil.Ok(t, err)
-	tracker := NewGasTracker(logger, proxy, client)
+	tracker := NewGasTracker(logger, proxy, client, NewFetcher(logger, cfg))
 	err = tracker.Exec(context.Background())
 	testutil.Ok(t, err)
-	v, err := proxy.Get(db.GasKey)
+	v, updated, err := db.NewStore(proxy).GetGasPrice()
 	testutil.Ok(t, err)
+	testutil.Assert(t, !updated.IsZero(), "expected the time the gas price was stored")
 
-	t.Logf("Gas Price stored: %v\n", string(v))
+	t.Logf("Gas Price stored: %v\n", v)
 
 }
 
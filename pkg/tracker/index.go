@@ -5,15 +5,20 @@ package tracker
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -34,32 +39,72 @@ func init() {
 	clck = clock.New()
 }
 
-var indexes map[string][]*IndexTracker
+const indexFile = "indexes.json"
+
+var (
+	indexesMtx sync.RWMutex
+	// indexes are the trackers for every symbol.
+	indexes map[string][]*IndexTracker
+	// indexTrackers are the trackers by their API so that a reload can keep unchanged APIs.
+	indexTrackers map[string]*IndexTracker
+)
 
 // GetIndexes returns indexes for outside package usage.
 func GetIndexes() map[string][]*IndexTracker {
+	indexesMtx.RLock()
+	defer indexesMtx.RUnlock()
 	return indexes
 }
 
-// parseIndexFile parses indexes.json file and returns a *IndexTracker,
-// for every URL in index file, also a map[string][]string that describes which APIs
-// influence which symbols.
-func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy, client contracts.ETHClient) (trackersPerURL map[string]*IndexTracker, symbolsForAPI map[string][]string, err error) {
+// indexesForSymbol returns the current trackers of a symbol.
+func indexesForSymbol(symbol string) []*IndexTracker {
+	indexesMtx.RLock()
+	defer indexesMtx.RUnlock()
+	return indexes[symbol]
+}
 
-	// Load index file.
-	indexFilePath := filepath.Join(cfg.ConfigFolder, "indexes.json")
+// readIndexFile reads the index objects of every symbol from the indexes.json file.
+func readIndexFile(cfg *config.Config) (map[string][]IndexObject, error) {
+	indexFilePath := filepath.Join(cfg.ConfigFolder, indexFile)
 	byteValue, err := ioutil.ReadFile(indexFilePath)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "read index file @ %s", indexFilePath)
+		return nil, errors.Wrapf(err, "read index file @ %s", indexFilePath)
 	}
-	// Parse to json.
 	baseIndexes := make(map[string][]IndexObject)
-	err = json.Unmarshal(byteValue, &baseIndexes)
+	if err := json.Unmarshal(byteValue, &baseIndexes); err != nil {
+		return nil, errors.Wrap(err, "parse index file")
+	}
+	return baseIndexes, nil
+}
+
+// IndexSources returns the identifiers of the APIs of every symbol in the indexes.json file.
+// These are the sources under which the apiOracle records their values.
+func IndexSources(cfg *config.Config) (map[string][]string, error) {
+	baseIndexes, err := readIndexFile(cfg)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "parse index file")
+		return nil, err
+	}
+	sources := make(map[string][]string, len(baseIndexes))
+	for symbol, apis := range baseIndexes {
+		for _, api := range apis {
+			sources[symbol] = append(sources[symbol], api.key())
+		}
+	}
+	return sources, nil
+}
+
+// parseIndexFile parses indexes.json file and returns a *IndexTracker,
+// for every URL in index file, also a map[string][]string that describes which APIs
+// influence which symbols and the trackers for every symbol.
+// The sources of unchanged APIs in previous are reused.
+func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy, client contracts.ETHClient, fetcher *Fetcher, previous map[string]*IndexTracker) (trackersPerURL map[string]*IndexTracker, symbolsForAPI map[string][]string, indexesPerSymbol map[string][]*IndexTracker, err error) {
+
+	baseIndexes, err := readIndexFile(cfg)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	// Keep track of tracker per symbol.
-	indexes = make(map[string][]*IndexTracker)
+	indexesPerSymbol = make(map[string][]*IndexTracker)
 	// Build a tracker for each unique URL.
 	trackersPerURL = make(map[string]*IndexTracker)
 	// Keep track of which APIs influence which symbols so we know what to update later.
@@ -67,18 +112,31 @@ func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy
 
 	for symbol, apis := range baseIndexes {
 		for _, api := range apis {
+			key := api.key()
+			def := api
 			// Tracker for this API already added?
-			_, ok := trackersPerURL[api.URL]
+			_, ok := trackersPerURL[key]
+			// Keep the source of an unchanged API as it can hold state like the TWAP snapshots.
+			if prev, exists := previous[key]; !ok && exists && reflect.DeepEqual(prev.def, def) {
+				trackersPerURL[key] = &IndexTracker{
+					Name:       prev.Name,
+					Identifier: key,
+					Source:     prev.Source,
+					DB:         DB,
+					Interval:   prev.Interval,
+					Param:      prev.Param,
+					Type:       prev.Type,
+					def:        def,
+				}
+				ok = true
+			}
 			if !ok {
-				// Expand any env variables with their values from the .env file.
+				// Env variables are expanded with their values from the .env file.
 				_, err := godotenv.Read(cfg.EnvFile)
 				// Ignore file doesn't exist errors.
 				if err != nil && !os.IsNotExist(err) {
-					return nil, nil, errors.Wrap(err, "reading .env file")
+					return nil, nil, nil, errors.Wrap(err, "reading .env file")
 				}
-				api.URL = os.Expand(api.URL, func(key string) string {
-					return os.Getenv(key)
-				})
 
 				var name string
 				var source DataSource
@@ -91,10 +149,15 @@ func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy
 				switch api.Type {
 				case httpIndexType:
 					{
-						source = &JSONapi{&FetchRequest{queryURL: api.URL, timeout: cfg.FetchTimeout.Duration}, logger}
-						u, err := url.Parse(api.URL)
+						req, err := newFetchRequest(api, cfg.FetchTimeout.Duration)
+						if err != nil {
+							return nil, nil, nil, errors.Wrapf(err, "invalid API request: %s", api.URL)
+						}
+						source = &JSONapi{req, fetcher}
+						// The parse error includes the expanded URL with the secrets so it isn't wrapped.
+						u, err := url.Parse(req.queryURL)
 						if err != nil {
-							return nil, nil, errors.Wrapf(err, "invalid API URL: %s", api.URL)
+							return nil, nil, nil, errors.Errorf("invalid API URL: %s", api.URL)
 						}
 						name = u.Host
 					}
@@ -108,29 +171,53 @@ func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy
 						// Getting current network id from geth node.
 						networkID, err := client.NetworkID(context.Background())
 						if err != nil {
-							return nil, nil, err
+							return nil, nil, nil, err
 						}
 						// Validate and pick an ethereum address for current network id.
 						address, err := util.GetAddressForNetwork(api.URL, networkID.Int64())
 						if err != nil {
-							return nil, nil, errors.Wrap(err, "getting address for network id")
+							return nil, nil, nil, errors.Wrap(err, "getting address for network id")
 						}
-						if api.Parser == uniswapIndexParser {
+						// The param of the TWAP parsers is the averaging period.
+						var period time.Duration
+						if api.Param != "" && (api.Parser == uniswapTWAPIndexParser || api.Parser == uniswapV3IndexParser) {
+							period, err = time.ParseDuration(api.Param)
+							if err != nil {
+								return nil, nil, nil, errors.Wrapf(err, "invalid TWAP period for on-chain index tracker: %s", api.Param)
+							}
+						}
+						switch api.Parser {
+						case uniswapIndexParser:
 							source = NewUniswap(symbol, address, client)
-
-						} else if api.Parser == balancerIndexParser {
+						case uniswapTWAPIndexParser:
+							source = NewUniswapTWAP(symbol, address, period, client)
+						case uniswapV3IndexParser:
+							source = NewUniswapV3(symbol, address, period, client)
+						case balancerIndexParser:
 							source = NewBalancer(symbol, address, client)
-						} else {
-							return nil, nil, errors.Wrapf(err, "unknown source for on-chain index tracker")
+						case chainlinkIndexParser:
+							source = NewChainlink(address, client)
+						case contractCallIndexParser:
+							source, err = NewContractCall(api.Signature, api.Args, api.Decimals, address, client)
+							if err != nil {
+								return nil, nil, nil, errors.Wrapf(err, "creating contract call for index tracker: %s", api.URL)
+							}
+						default:
+							return nil, nil, nil, errors.Errorf("unknown source for on-chain index tracker: %s", api.Parser)
+						}
+						// On-chain sources consume the param themselves and return plain values.
+						// Only the contract call returns all values and uses the param to pick one.
+						if api.Parser != contractCallIndexParser {
+							api.Param = ""
 						}
 						name = fmt.Sprintf("%s(%s)", api.Type, api.URL)
 					}
 				default:
-					return nil, nil, errors.New("unknown index type for index object")
+					return nil, nil, nil, errors.New("unknown index type for index object")
 				}
 
 				if api.Interval.Duration > 0 && (api.Interval.Duration < cfg.TrackerSleepCycle.Duration) {
-					return nil, nil, errors.New("api interval can't be smaller than the global tracker cycle")
+					return nil, nil, nil, errors.New("api interval can't be smaller than the global tracker cycle")
 				}
 
 				// Default value for the parser.
@@ -139,26 +226,27 @@ func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy
 				}
 				current := &IndexTracker{
 					Name:       name,
-					Identifier: api.URL,
+					Identifier: key,
 					Source:     source,
 					DB:         DB,
 					Interval:   api.Interval.Duration,
 					Param:      api.Param,
 					Type:       api.Type,
+					def:        def,
 				}
 
-				trackersPerURL[api.URL] = current
+				trackersPerURL[key] = current
 			}
 			// Now we definitely have one.
-			thisOne := trackersPerURL[api.URL]
+			thisOne := trackersPerURL[key]
 
 			// Insert add it and it's more specific variant to the symbol -> api map.
-			indexes[symbol] = append(indexes[symbol], thisOne)
+			indexesPerSymbol[symbol] = append(indexesPerSymbol[symbol], thisOne)
 			specificName := fmt.Sprintf("%s~%s", symbol, thisOne.Name)
-			indexes[specificName] = append(indexes[specificName], thisOne)
+			indexesPerSymbol[specificName] = append(indexesPerSymbol[specificName], thisOne)
 
 			// Save this for later so we can build the api->symbol map.
-			symbolsForAPI[api.URL] = append(symbolsForAPI[api.URL], symbol, specificName)
+			symbolsForAPI[key] = append(symbolsForAPI[key], symbol, specificName)
 		}
 	}
 	return
@@ -166,15 +254,28 @@ func parseIndexFile(logger log.Logger, cfg *config.Config, DB db.DataServerProxy
 }
 
 // BuildIndexTrackers creates and initializes a new tracker instance.
-func BuildIndexTrackers(logger log.Logger, cfg *config.Config, db db.DataServerProxy, client contracts.ETHClient) ([]Tracker, error) {
+func BuildIndexTrackers(logger log.Logger, cfg *config.Config, db db.DataServerProxy, client contracts.ETHClient, fetcher *Fetcher) ([]Tracker, error) {
 	err := apiOracle.EnsureValueOracle(logger, cfg)
 	if err != nil {
 		return nil, err
 	}
+	return loadIndexTrackers(logger, cfg, db, client, fetcher, false)
+}
+
+// loadIndexTrackers builds the trackers from the index file and replaces the current ones.
+// The current trackers are kept when the new index file is invalid.
+// With keepUnchanged the new trackers reuse the sources of the current trackers with the same index entry.
+func loadIndexTrackers(logger log.Logger, cfg *config.Config, db db.DataServerProxy, client contracts.ETHClient, fetcher *Fetcher, keepUnchanged bool) ([]Tracker, error) {
+	var previous map[string]*IndexTracker
+	if keepUnchanged {
+		indexesMtx.RLock()
+		previous = indexTrackers
+		indexesMtx.RUnlock()
+	}
 
 	// Load trackers from the index file,
 	// and build a tracker for each unique URL, symbol
-	indexers, symbolsForAPI, err := parseIndexFile(logger, cfg, db, client)
+	indexers, symbolsForAPI, indexesPerSymbol, err := parseIndexFile(logger, cfg, db, client, fetcher, previous)
 	if err != nil {
 		return nil, err
 	}
@@ -195,12 +296,17 @@ func BuildIndexTrackers(logger log.Logger, cfg *config.Config, db db.DataServerP
 		trackers[idx] = indexers[api]
 	}
 
-	// Start the PSR system that will feed from these indexes.
-	err = InitPSRs()
+	// Check that the PSR system can feed from these indexes.
+	err = validatePSRs(indexesPerSymbol)
 	if err != nil {
 		return nil, errors.Wrap(err, "initialize PSRs")
 	}
 
+	indexesMtx.Lock()
+	indexes = indexesPerSymbol
+	indexTrackers = indexers
+	indexesMtx.Unlock()
+
 	return trackers, nil
 }
 
@@ -217,9 +323,13 @@ const (
 type IndexParser string
 
 const (
-	jsonPathIndexParser IndexParser = "jsonPath"
-	uniswapIndexParser  IndexParser = "Uniswap"
-	balancerIndexParser IndexParser = "Balancer"
+	jsonPathIndexParser     IndexParser = "jsonPath"
+	uniswapIndexParser      IndexParser = "Uniswap"
+	uniswapTWAPIndexParser  IndexParser = "UniswapTWAP"
+	uniswapV3IndexParser    IndexParser = "UniswapV3"
+	balancerIndexParser     IndexParser = "Balancer"
+	chainlinkIndexParser    IndexParser = "Chainlink"
+	contractCallIndexParser IndexParser = "ContractCall"
 )
 
 // IndexObject will be used in parsing index file.
@@ -229,6 +339,108 @@ type IndexObject struct {
 	Parser   IndexParser     `json:"parser"`
 	Param    string          `json:"param"`
 	Interval config.Duration `json:"interval"`
+	// Method, Headers, Body and Auth are used by the http type.
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+	Auth    *IndexAuth        `json:"auth"`
+	// Signature, Args and Decimals are used by the ContractCall parser.
+	Signature string   `json:"signature"`
+	Args      []string `json:"args"`
+	Decimals  uint8    `json:"decimals"`
+}
+
+// key identifies the API of the index object.
+// Different queries and contract calls to the same URL are different APIs,
+// as are the on-chain indexes of a contract with different parsers, params or decimals.
+func (i IndexObject) key() string {
+	key := i.URL
+	if len(i.Body) > 0 {
+		key += " " + string(i.Body)
+	}
+	if i.Type == ethereumIndexType {
+		key += " " + string(i.Parser)
+		if i.Param != "" {
+			key += "(" + i.Param + ")"
+		}
+	}
+	if i.Signature != "" {
+		key += " " + i.Signature + "[" + strings.Join(i.Args, ",") + "]"
+	}
+	if i.Decimals > 0 {
+		key += fmt.Sprintf(" decimals:%d", i.Decimals)
+	}
+	return key
+}
+
+// IndexAuth is the authentication of an http index.
+// Type is either basic with a Username and Password or bearer with a Token.
+type IndexAuth struct {
+	Type     string `json:"type"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// envRefRegexp matches the ${VAR} env variable references
+// in the headers, body and auth of an http index.
+// The $VAR form isn't expanded there as it clashes with GraphQL variables.
+var envRefRegexp = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// newFetchRequest builds the request of an http index
+// and collects all env variable values and credentials as secrets to redact in the logs.
+func newFetchRequest(api IndexObject, timeout time.Duration) (*FetchRequest, error) {
+	req := &FetchRequest{
+		method:  strings.ToUpper(api.Method),
+		headers: make(map[string]string),
+		timeout: timeout,
+	}
+	req.queryURL = os.Expand(api.URL, func(key string) string {
+		val := os.Getenv(key)
+		req.secrets = append(req.secrets, val)
+		return val
+	})
+	expand := func(s string) string {
+		return envRefRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+			val := os.Getenv(envRefRegexp.FindStringSubmatch(ref)[1])
+			req.secrets = append(req.secrets, val)
+			return val
+		})
+	}
+	for name, value := range api.Headers {
+		req.headers[name] = expand(value)
+	}
+
+	// The body is either a JSON string that is sent as is or
+	// a JSON object, handy for GraphQL queries, that is sent encoded.
+	if len(api.Body) > 0 {
+		body := string(api.Body)
+		var str string
+		if err := json.Unmarshal(api.Body, &str); err == nil {
+			body = str
+		}
+		req.body = []byte(expand(body))
+		if req.method == "" {
+			req.method = http.MethodPost
+		}
+	}
+
+	if api.Auth != nil {
+		switch strings.ToLower(api.Auth.Type) {
+		case "basic":
+			username, password := expand(api.Auth.Username), expand(api.Auth.Password)
+			credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+			req.headers["Authorization"] = "Basic " + credentials
+			req.secrets = append(req.secrets, password, credentials)
+		case "bearer":
+			token := expand(api.Auth.Token)
+			req.headers["Authorization"] = "Bearer " + token
+			req.secrets = append(req.secrets, token)
+		default:
+			return nil, errors.Errorf("unknown auth type: %s", api.Auth.Type)
+		}
+	}
+	return req, nil
 }
 
 type IndexTracker struct {
@@ -241,6 +453,11 @@ type IndexTracker struct {
 	Param            string
 	Type             IndexType
 	lastRunTimestamp time.Time
+	// def is the index file entry of the tracker.
+	def IndexObject
+
+	healthMtx sync.Mutex
+	health    SourceHealth
 }
 
 type DataSource interface {
@@ -249,11 +466,11 @@ type DataSource interface {
 
 type JSONapi struct {
 	Request *FetchRequest
-	logger  log.Logger
+	fetcher *Fetcher
 }
 
 func (j *JSONapi) Get() ([]byte, error) {
-	return fetchWithRetries(j.logger, j.Request)
+	return j.fetcher.Fetch(context.Background(), j.Request)
 }
 
 type JSONfile struct {
@@ -271,12 +488,8 @@ func (i *IndexTracker) Exec(ctx context.Context) error {
 	}
 	i.lastRunTimestamp = now
 
-	payload, err := i.Source.Get()
-	if err != nil {
-		return err
-	}
-
-	vals, err := i.ParsePayload(payload)
+	vals, err := i.fetchValues()
+	i.recordRun(now, vals, err)
 	if err != nil {
 		return err
 	}
@@ -292,6 +505,14 @@ func (i *IndexTracker) Exec(ctx context.Context) error {
 	return UpdatePSRs(ctx, i.DB, i.Symbols)
 }
 
+func (i *IndexTracker) fetchValues() ([]float64, error) {
+	payload, err := i.Source.Get()
+	if err != nil {
+		return nil, err
+	}
+	return i.ParsePayload(payload)
+}
+
 func (i *IndexTracker) String() string {
 	return fmt.Sprintf("%s on %s", strings.Join(i.Symbols, ","), i.Name)
 }
@@ -7,9 +7,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/db"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/rpc"
 	"github.com/tellor-io/telliot/pkg/testutil"
 )
 
@@ -40,3 +46,169 @@ func TestIndexTracker(t *testing.T) {
 		}
 	}
 }
+
+func TestNewFetchRequest(t *testing.T) {
+	os.Setenv("TEST_API_KEY", "s3cr3t")
+	os.Setenv("TEST_API_USER", "user")
+	defer os.Unsetenv("TEST_API_KEY")
+	defer os.Unsetenv("TEST_API_USER")
+
+	// URL and header env variables.
+	req, err := newFetchRequest(IndexObject{
+		URL:     "https://api.example.com/price?key=${TEST_API_KEY}",
+		Headers: map[string]string{"X-Api-Key": "${TEST_API_KEY}"},
+	}, time.Second)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "https://api.example.com/price?key=s3cr3t", req.queryURL)
+	testutil.Equals(t, "s3cr3t", req.headers["X-Api-Key"])
+	testutil.Equals(t, "https://api.example.com/price?key=<redacted>", req.redact(req.queryURL))
+
+	// GraphQL body keeps its variables and defaults to POST.
+	req, err = newFetchRequest(IndexObject{
+		URL:  "https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v2",
+		Body: json.RawMessage(`{"query":"query($id: ID!) { pair(id: $id) { token0Price } }","variables":{"id":"0x01"}}`),
+	}, time.Second)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "POST", req.method)
+	testutil.Equals(t, `{"query":"query($id: ID!) { pair(id: $id) { token0Price } }","variables":{"id":"0x01"}}`, string(req.body))
+
+	// Basic and bearer auth.
+	req, err = newFetchRequest(IndexObject{
+		URL:  "https://api.example.com/price",
+		Auth: &IndexAuth{Type: "basic", Username: "${TEST_API_USER}", Password: "${TEST_API_KEY}"},
+	}, time.Second)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "Basic dXNlcjpzM2NyM3Q=", req.headers["Authorization"])
+	testutil.Equals(t, "Basic <redacted>", req.redact(req.headers["Authorization"]))
+
+	req, err = newFetchRequest(IndexObject{
+		URL:  "https://api.example.com/price",
+		Auth: &IndexAuth{Type: "bearer", Token: "${TEST_API_KEY}"},
+	}, time.Second)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "Bearer s3cr3t", req.headers["Authorization"])
+
+	_, err = newFetchRequest(IndexObject{
+		URL:  "https://api.example.com/price",
+		Auth: &IndexAuth{Type: "digest"},
+	}, time.Second)
+	testutil.NotOk(t, err)
+}
+
+func TestIndexReload(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	defer t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logger, &cfg, DB)
+	testutil.Ok(t, err)
+	client := rpc.NewMockClient()
+	fetcher := NewFetcher(logger, &cfg)
+
+	rawJSON, err := ioutil.ReadFile(filepath.Join(cfg.ConfigFolder, indexFile))
+	testutil.Ok(t, err)
+	cfg.ConfigFolder = t.TempDir()
+	indexPath := filepath.Join(cfg.ConfigFolder, indexFile)
+	testutil.Ok(t, ioutil.WriteFile(indexPath, rawJSON, 0600))
+
+	trackers, err := BuildIndexTrackers(logger, &cfg, proxy, client, fetcher)
+	testutil.Ok(t, err)
+	ethUSD := indexesForSymbol("ETH/USD")
+
+	var apis map[string][]map[string]interface{}
+	testutil.Ok(t, json.Unmarshal(rawJSON, &apis))
+	writeIndexes := func(apis map[string][]map[string]interface{}) {
+		b, err := json.Marshal(apis)
+		testutil.Ok(t, err)
+		testutil.Ok(t, ioutil.WriteFile(indexPath, b, 0600))
+	}
+
+	// Invalid edits are rejected and the current trackers are kept.
+	testutil.Ok(t, ioutil.WriteFile(indexPath, rawJSON[:len(rawJSON)/2], 0600))
+	_, err = loadIndexTrackers(logger, &cfg, proxy, client, fetcher, true)
+	testutil.NotOk(t, err)
+
+	ethUSDAPIs := apis["ETH/USD"]
+	delete(apis, "ETH/USD")
+	writeIndexes(apis)
+	_, err = loadIndexTrackers(logger, &cfg, proxy, client, fetcher, true)
+	testutil.NotOk(t, err, "removing a symbol required by a PSR should fail")
+	testutil.Equals(t, ethUSD, indexesForSymbol("ETH/USD"))
+
+	// A valid edit replaces the trackers and keeps the sources of the unchanged ones.
+	apis["ETH/USD"] = ethUSDAPIs
+	apis["BTC/USD"] = append(apis["BTC/USD"], map[string]interface{}{
+		"URL":   "https://api.example.com/btc",
+		"param": "$.price",
+	})
+	writeIndexes(apis)
+	reloaded, err := loadIndexTrackers(logger, &cfg, proxy, client, fetcher, true)
+	testutil.Ok(t, err)
+	testutil.Equals(t, len(trackers)+1, len(reloaded))
+	for i, tracker := range indexesForSymbol("ETH/USD") {
+		testutil.Assert(t, tracker != ethUSD[i], "the tracker should be replaced")
+		testutil.Assert(t, tracker.Source == ethUSD[i].Source, "the source of an unchanged API should be kept")
+		testutil.Equals(t, ethUSD[i].Identifier, tracker.Identifier)
+	}
+}
+
+// parseTestIndexes parses the indexes of the config folder with the given APIs added for the TEST/USD symbol.
+func parseTestIndexes(t *testing.T, added []map[string]interface{}) []*IndexTracker {
+	cfg := *config.OpenTestConfig(t)
+	logger := logging.NewLogger()
+	DB, cleanup := db.OpenTestDB(t)
+	t.Cleanup(cleanup)
+	proxy, err := db.OpenLocal(logger, &cfg, DB)
+	testutil.Ok(t, err)
+
+	rawJSON, err := ioutil.ReadFile(filepath.Join(cfg.ConfigFolder, indexFile))
+	testutil.Ok(t, err)
+	var apis map[string][]map[string]interface{}
+	testutil.Ok(t, json.Unmarshal(rawJSON, &apis))
+	apis["TEST/USD"] = added
+	b, err := json.Marshal(apis)
+	testutil.Ok(t, err)
+	cfg.ConfigFolder = t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(cfg.ConfigFolder, indexFile), b, 0600))
+
+	_, _, indexesPerSymbol, err := parseIndexFile(logger, &cfg, proxy, rpc.NewMockClient(), NewFetcher(logger, &cfg), nil)
+	testutil.Ok(t, err)
+	trackers := indexesPerSymbol["TEST/USD"]
+	testutil.Equals(t, len(added), len(trackers))
+	identifiers := make(map[string]bool)
+	for _, tracker := range trackers {
+		identifiers[tracker.Identifier] = true
+	}
+	testutil.Equals(t, len(trackers), len(identifiers), "expected a tracker per API")
+	return trackers
+}
+
+func TestIndexKeys(t *testing.T) {
+	// The on-chain indexes of the same pool with different parsers and periods are different APIs.
+	pool := "Mainnet:0xc5be99a02c6857f9eac67bbce58df5572498f40c,Rinkeby:0x6dFd19363709f6245a7e7A74eA66e5c59dF9360F"
+	trackers := parseTestIndexes(t, []map[string]interface{}{
+		{"URL": pool, "type": "ethereum", "parser": "Uniswap"},
+		{"URL": pool, "type": "ethereum", "parser": "UniswapTWAP", "param": "30m"},
+		{"URL": pool, "type": "ethereum", "parser": "UniswapTWAP", "param": "1h"},
+		{"URL": pool, "type": "ethereum", "parser": "UniswapV3", "param": "30m"},
+	})
+	twap, ok := trackers[1].Source.(*UniswapTWAP)
+	testutil.Assert(t, ok, "expected a TWAP source")
+	testutil.Equals(t, 30*time.Minute, twap.period)
+	twap, ok = trackers[2].Source.(*UniswapTWAP)
+	testutil.Assert(t, ok, "expected a TWAP source")
+	testutil.Equals(t, time.Hour, twap.period)
+
+	// The contract calls of the same function that differ only in their decimals or param are different APIs.
+	trackers = parseTestIndexes(t, []map[string]interface{}{
+		{"URL": pool, "type": "ethereum", "parser": "Chainlink"},
+		{"URL": pool, "type": "ethereum", "parser": "ContractCall", "signature": "getReserves()(uint112,uint112,uint32)", "decimals": 18, "param": "$[0]"},
+		{"URL": pool, "type": "ethereum", "parser": "ContractCall", "signature": "getReserves()(uint112,uint112,uint32)", "decimals": 6, "param": "$[0]"},
+		{"URL": pool, "type": "ethereum", "parser": "ContractCall", "signature": "getReserves()(uint112,uint112,uint32)", "decimals": 18, "param": "$[1]"},
+	})
+	testutil.Equals(t, "$[0]", trackers[1].Param)
+	testutil.Equals(t, "$[1]", trackers[3].Param)
+	call, ok := trackers[2].Source.(*ContractCall)
+	testutil.Assert(t, ok, "expected a contract call source")
+	testutil.Equals(t, uint8(6), call.decimals)
+}
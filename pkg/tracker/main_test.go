@@ -21,6 +21,7 @@ var configJSON = `{
     "trackerCycle": 1,
     "trackers": {},
     "dbFile": "/tellorDB",
+    "History": {"File": ""},
 	"requestTips": 1,
 	"logger": {"db.Db":"DEBUG"},
     "configFolder": "` + filepath.Join("..", "..", "configs") + `",
@@ -0,0 +1,129 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+const manualDataFile = "manualData.json"
+
+// ManualValue is a manually entered value from the manualData.json file.
+type ManualValue struct {
+	Value float64
+	// Date is the unix timestamp until which the value is valid.
+	Date int64
+}
+
+var (
+	manualDataMtx sync.RWMutex
+	// manualData holds the last valid content of the manualData.json file
+	// by request ID or symbol.
+	manualData map[string]ManualValue
+	// manualDataPath and manualDataModTime are the file and its modification time
+	// when it was last loaded, the file is loaded again when it is modified.
+	manualDataPath    string
+	manualDataModTime time.Time
+)
+
+// parseManualData reads and validates a manualData.json file.
+func parseManualData(path string) (map[string]ManualValue, error) {
+	byteValue, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read manual data file @ %s", path)
+	}
+	var entries map[string]struct {
+		Value *float64 `json:"VALUE"`
+		Date  *int64   `json:"DATE"`
+	}
+	if err := json.Unmarshal(byteValue, &entries); err != nil {
+		return nil, errors.Wrap(err, "parse manual data file")
+	}
+	values := make(map[string]ManualValue, len(entries))
+	for key, entry := range entries {
+		if entry.Value == nil || entry.Date == nil {
+			return nil, errors.Errorf("manual data entry %s requires a VALUE and a DATE", key)
+		}
+		if *entry.Value < 0 {
+			return nil, errors.Errorf("manual data entry %s has a negative VALUE: %v", key, *entry.Value)
+		}
+		values[key] = ManualValue{Value: *entry.Value, Date: *entry.Date}
+	}
+	return values, nil
+}
+
+// LoadManualData validates the manualData.json file in the config folder and replaces
+// the current values with its content. An invalid file keeps the current values.
+func LoadManualData(cfg *config.Config) error {
+	return loadManualData(filepath.Join(cfg.ConfigFolder, manualDataFile))
+}
+
+func loadManualData(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrapf(err, "read manual data file @ %s", path)
+	}
+	values, err := parseManualData(path)
+
+	manualDataMtx.Lock()
+	defer manualDataMtx.Unlock()
+	// Also remembered for an invalid file so it isn't parsed again until it is modified.
+	manualDataPath = path
+	manualDataModTime = info.ModTime()
+	if err != nil {
+		return err
+	}
+	manualData = values
+	return nil
+}
+
+// GetManualValue returns the manually entered value of a request ID to submit at the given time.
+// The value is looked up by the request ID and then by the symbol of its PSR.
+// A value by the request ID is submitted as is and a value by the symbol
+// is converted with the PSR granularity. Expired values are rejected.
+// The manualData.json file is loaded again when it was modified since the last load.
+func GetManualValue(requestID uint64, at time.Time) (*big.Int, error) {
+	manualDataMtx.RLock()
+	path, modTime, loaded := manualDataPath, manualDataModTime, manualData != nil
+	manualDataMtx.RUnlock()
+	if path == "" {
+		path = filepath.Join(config.GetConfig().ConfigFolder, manualDataFile)
+	}
+	if info, err := os.Stat(path); !loaded || err == nil && !info.ModTime().Equal(modTime) {
+		// The last valid values are kept when the modified file is invalid.
+		if err := loadManualData(path); err != nil && !loaded {
+			return nil, err
+		}
+	}
+
+	manualDataMtx.RLock()
+	defer manualDataMtx.RUnlock()
+	val, ok := manualData[strconv.FormatUint(requestID, 10)]
+	value := val.Value
+	if !ok {
+		if psr, isSingle := PSRs[int(requestID)].(*SingleSymbol); isSingle {
+			if val, ok = manualData[psr.symbol]; ok {
+				value = psr.ValueAt(map[string]apiOracle.PriceInfo{psr.symbol: {Price: val.Value}}, at)
+			}
+		}
+	}
+	if !ok || value == 0 {
+		return nil, errors.Errorf("no manual value for request id:%v", requestID)
+	}
+	if val.Date < at.Unix() {
+		return nil, errors.Errorf("manual value for request id:%v expired at:%v", requestID, time.Unix(val.Date, 0).UTC())
+	}
+	return big.NewInt(int64(value)), nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestManualData(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	cfg.ConfigFolder = t.TempDir()
+	path := filepath.Join(cfg.ConfigFolder, manualDataFile)
+	defer func() {
+		manualDataMtx.Lock()
+		manualData = nil
+		manualDataPath = ""
+		manualDataMtx.Unlock()
+	}()
+
+	testutil.NotOk(t, LoadManualData(&cfg), "loading a missing file should fail")
+
+	now := time.Now()
+	valid := now.Add(time.Hour).Unix()
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"USPCE": {"VALUE": 111.85, "DATE": %[1]d}, "42": {"VALUE": 5, "DATE": %[1]d}}`, valid)), 0600))
+	testutil.Ok(t, LoadManualData(&cfg))
+	// By the symbol of the PSR with its granularity.
+	val, err := GetManualValue(41, now)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(111850), val)
+	// By the request ID as is.
+	val, err = GetManualValue(42, now)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(5), val)
+	_, err = GetManualValue(1, now)
+	testutil.NotOk(t, err)
+	// Expired values are rejected.
+	_, err = GetManualValue(41, now.Add(2*time.Hour))
+	testutil.NotOk(t, err)
+
+	// Invalid files are rejected and the current values are kept.
+	for _, invalid := range []string{
+		`{"USPCE": {"VALUE": 111.85`,
+		`{"USPCE": {"VALUE": 111.85}}`,
+		`{"USPCE": {"VALUE": "111.85", "DATE": 1614556800}}`,
+		`{"USPCE": {"VALUE": -1, "DATE": 1614556800}}`,
+	} {
+		testutil.Ok(t, ioutil.WriteFile(path, []byte(invalid), 0600))
+		testutil.NotOk(t, LoadManualData(&cfg), "invalid file:%s", invalid)
+	}
+	val, err = GetManualValue(41, now)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(111850), val)
+
+	// The modified file is loaded again by the miner without a reload of the data server.
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"USPCE": {"VALUE": 112, "DATE": %d}}`, valid)), 0600))
+	modTime := time.Now().Add(time.Minute)
+	testutil.Ok(t, os.Chtimes(path, modTime, modTime))
+	val, err = GetManualValue(41, now)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(112000), val)
+
+	// A modified invalid file keeps the last valid values.
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(`{"USPCE": {"VALUE": 113`), 0600))
+	modTime = modTime.Add(time.Minute)
+	testutil.Ok(t, os.Chtimes(path, modTime, modTime))
+	val, err = GetManualValue(41, now)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(112000), val)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/contracts/master"
+)
+
+// minerSubmission is the value submitted by one of the five miners of a mined value.
+type minerSubmission struct {
+	miner common.Address
+	value *big.Int
+	// slot is the index of the miner in the mined value where the submissions are sorted by value.
+	slot   int
+	block  uint64
+	txHash common.Hash
+}
+
+// minedValue is a request of a new value with the submissions of its miners.
+type minedValue struct {
+	requestID *big.Int
+	timestamp *big.Int
+	// median is the final value, the median of the submissions.
+	median      *big.Int
+	block       uint64
+	submissions []*minerSubmission
+}
+
+// groupSubmissions groups the nonce submissions by the requests of the new values that include them.
+// The submissions of a new value are the ones for the same challenge.
+func groupSubmissions(newValues []*master.TellorLibraryNewValue, nonces []*master.TellorLibraryNonceSubmitted) []*minedValue {
+	byChallenge := make(map[[32]byte][]*master.TellorLibraryNonceSubmitted)
+	for _, nonce := range nonces {
+		byChallenge[nonce.CurrentChallenge] = append(byChallenge[nonce.CurrentChallenge], nonce)
+	}
+
+	var mined []*minedValue
+	for _, newValue := range newValues {
+		for r, reqID := range newValue.RequestId {
+			if reqID == nil {
+				continue
+			}
+			m := &minedValue{
+				requestID: reqID,
+				timestamp: newValue.Time,
+				median:    newValue.Value[r],
+				block:     newValue.Raw.BlockNumber,
+			}
+			for _, nonce := range byChallenge[newValue.CurrentChallenge] {
+				if nonce.RequestId[r] == nil || nonce.RequestId[r].Cmp(reqID) != 0 {
+					continue
+				}
+				m.submissions = append(m.submissions, &minerSubmission{
+					miner:  nonce.Miner,
+					value:  nonce.Value[r],
+					block:  nonce.Raw.BlockNumber,
+					txHash: nonce.Raw.TxHash,
+				})
+			}
+			// Like the contract keep the submissions sorted by value.
+			sort.SliceStable(m.submissions, func(i, j int) bool {
+				return m.submissions[i].value.Cmp(m.submissions[j].value) < 0
+			})
+			for i, s := range m.submissions {
+				s.slot = i
+			}
+			mined = append(mined, m)
+		}
+	}
+	return mined
+}
+
+// peerMedian returns the median of the values submitted by the other miners.
+// It returns false when there are less than two other submissions.
+func (m *minedValue) peerMedian(slot int) (float64, bool) {
+	var others []float64
+	for _, s := range m.submissions {
+		if s.slot != slot {
+			others = append(others, bigToFloat(s.value))
+		}
+	}
+	if len(others) < 2 {
+		return 0, false
+	}
+	sort.Float64s(others)
+	mid := len(others) / 2
+	if len(others)%2 == 0 {
+		return (others[mid-1] + others[mid]) / 2, true
+	}
+	return others[mid], true
+}
+
+// medianDeviation returns the relative difference between the submission and the final value.
+func (m *minedValue) medianDeviation(sub *minerSubmission) float64 {
+	return relativeDeviation(bigToFloat(sub.value), bigToFloat(m.median))
+}
+
+// relativeDeviation returns the relative difference between the value and the reference.
+func relativeDeviation(value, reference float64) float64 {
+	if reference == 0 {
+		return 0
+	}
+	return math.Abs(value-reference) / reference
+}
+
+func bigToFloat(v *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(v).Float64()
+	return f
+}
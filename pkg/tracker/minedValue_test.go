@@ -0,0 +1,76 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tellor-io/telliot/pkg/contracts/master"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestGroupSubmissions(t *testing.T) {
+	requestIDs := [5]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}
+	challenge := [32]byte{1}
+	values := []int64{100, 101, 99, 150, 100}
+	var nonces []*master.TellorLibraryNonceSubmitted
+	for i, v := range values {
+		nonce := &master.TellorLibraryNonceSubmitted{
+			Miner:            common.Address{byte(i + 1)},
+			RequestId:        requestIDs,
+			CurrentChallenge: challenge,
+			Raw:              types.Log{BlockNumber: uint64(10 + i)},
+		}
+		for r := range nonce.Value {
+			nonce.Value[r] = big.NewInt(v * int64(r+1))
+		}
+		nonces = append(nonces, nonce)
+	}
+	// A submission for another challenge.
+	nonces = append(nonces, &master.TellorLibraryNonceSubmitted{
+		Miner:            common.Address{9},
+		RequestId:        requestIDs,
+		Value:            [5]*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1), big.NewInt(1), big.NewInt(1)},
+		CurrentChallenge: [32]byte{2},
+	})
+	newValue := &master.TellorLibraryNewValue{
+		RequestId:        requestIDs,
+		Time:             big.NewInt(1600000000),
+		CurrentChallenge: challenge,
+		Raw:              types.Log{BlockNumber: 14},
+	}
+	for r := range newValue.Value {
+		newValue.Value[r] = big.NewInt(100 * int64(r+1))
+	}
+
+	mined := groupSubmissions([]*master.TellorLibraryNewValue{newValue}, nonces)
+	testutil.Equals(t, 5, len(mined))
+	m := mined[1]
+	testutil.Equals(t, int64(2), m.requestID.Int64())
+	testutil.Equals(t, int64(200), m.median.Int64())
+	testutil.Equals(t, 5, len(m.submissions))
+
+	// The submissions are sorted by value like in the contract.
+	var slots []common.Address
+	for i, s := range m.submissions {
+		testutil.Equals(t, i, s.slot)
+		slots = append(slots, s.miner)
+	}
+	testutil.Equals(t, []common.Address{{3}, {1}, {5}, {2}, {4}}, slots)
+
+	// The outlier is far from the median of the other four miners.
+	peer, ok := m.peerMedian(4)
+	testutil.Assert(t, ok, "expected a median of the other miners")
+	testutil.Equals(t, 200.0, peer)
+	testutil.Assert(t, relativeDeviation(bigToFloat(m.submissions[4].value), peer) > 0.4, "expected an outlier")
+	peer, _ = m.peerMedian(0)
+	testutil.Assert(t, relativeDeviation(bigToFloat(m.submissions[0].value), peer) < 0.05, "expected no outlier")
+
+	// The outlier is also far from the final value.
+	testutil.Equals(t, 0.5, m.medianDeviation(m.submissions[4]))
+	testutil.Equals(t, 0.0, m.medianDeviation(m.submissions[2]))
+}
@@ -185,7 +185,7 @@ func ManualEntry(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float
 		return apiOracle.PriceInfo{}, 0, nil
 	}
 	for _, val := range vals {
-		if int64(val.Volume) < clck.Now().Unix() {
+		if int64(val.Volume) < at.Unix() {
 			return apiOracle.PriceInfo{}, 0, errors.New("manual data entry expired, please update")
 		}
 	}
@@ -202,10 +202,10 @@ func MaxPSRID() uint64 {
 	return uint64(maxID)
 }
 
+// MedianAtEOD returns the median at the last end of day (midnight UTC) before at.
 func MedianAtEOD(apis []*IndexTracker, at time.Time) (apiOracle.PriceInfo, float64, error) {
-	now := clck.Now().UTC()
 	d := 24 * time.Hour
-	eod := now.Truncate(d)
+	eod := at.UTC().Truncate(d)
 	return MedianAt(apis, eod)
 }
 
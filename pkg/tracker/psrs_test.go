@@ -22,7 +22,7 @@ func TestMeanAt(t *testing.T) {
 	testutil.Ok(t, err)
 	testClient := rpc.NewMockClient()
 	defer t.Cleanup(cleanup)
-	if _, err := BuildIndexTrackers(logger, cfg, proxy, testClient); err != nil {
+	if _, err := BuildIndexTrackers(logger, cfg, proxy, testClient, NewFetcher(logger, cfg)); err != nil {
 		testutil.Ok(t, err)
 	}
 	ethIndexes := indexes["ETH/USD"]
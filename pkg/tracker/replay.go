@@ -0,0 +1,86 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/config"
+)
+
+// PSRValue is the value of a PSR recomputed at a past time.
+type PSRValue struct {
+	RequestID  int       `json:"requestId"`
+	Time       time.Time `json:"time"`
+	Value      float64   `json:"value"`
+	Confidence float64   `json:"confidence"`
+	// Error is set when the value couldn't be computed, for example an expired manual value.
+	Error string `json:"error,omitempty"`
+}
+
+// LoadReplayIndexes sets the indexes from the index file without creating their sources.
+// This allows computing the PSR values from the recorded value history without running the trackers.
+func LoadReplayIndexes(cfg *config.Config) error {
+	baseIndexes, err := readIndexFile(cfg)
+	if err != nil {
+		return err
+	}
+	indexesPerSymbol := make(map[string][]*IndexTracker, len(baseIndexes))
+	for symbol, apis := range baseIndexes {
+		for _, api := range apis {
+			key := api.key()
+			indexesPerSymbol[symbol] = append(indexesPerSymbol[symbol], &IndexTracker{
+				Name:       key,
+				Identifier: key,
+				Param:      api.Param,
+				Type:       api.Type,
+				def:        api,
+			})
+		}
+	}
+	if err := validatePSRs(indexesPerSymbol); err != nil {
+		return err
+	}
+	indexesMtx.Lock()
+	indexes = indexesPerSymbol
+	indexTrackers = nil
+	indexesMtx.Unlock()
+	return nil
+}
+
+// ReplayPSRs recomputes the values of the PSRs every step from from until to.
+// All PSRs are replayed when no request IDs are given.
+func ReplayPSRs(requestIDs []int, from, to time.Time, step time.Duration) ([]PSRValue, error) {
+	if step <= 0 {
+		return nil, errors.Errorf("invalid replay step:%v", step)
+	}
+	if len(requestIDs) == 0 {
+		for requestID := range PSRs {
+			requestIDs = append(requestIDs, requestID)
+		}
+		sort.Ints(requestIDs)
+	}
+	for _, requestID := range requestIDs {
+		if _, ok := PSRs[requestID]; !ok {
+			return nil, errors.Errorf("no PSR for request id:%v", requestID)
+		}
+	}
+
+	var values []PSRValue
+	for at := from; !at.After(to); at = at.Add(step) {
+		for _, requestID := range requestIDs {
+			value := PSRValue{RequestID: requestID, Time: at}
+			val, confidence, err := PSRValueForTime(requestID, at)
+			if err != nil {
+				value.Error = err.Error()
+			} else {
+				value.Value, value.Confidence = val, confidence
+			}
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+	"github.com/tellor-io/telliot/pkg/config"
+	"github.com/tellor-io/telliot/pkg/logging"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestReplayPSRs(t *testing.T) {
+	cfg := config.OpenTestConfig(t)
+	// Start with an empty value history.
+	testutil.Ok(t, apiOracle.CloseValueOracle())
+	testutil.Ok(t, apiOracle.EnsureValueOracle(logging.NewLogger(), cfg))
+	testutil.Ok(t, LoadReplayIndexes(cfg))
+
+	// Two days of ETH/USD values, one each end of day.
+	d := 24 * time.Hour
+	twoDaysAgo := time.Now().UTC().Truncate(d).Add(-2 * d)
+	for i, price := range []float64{100, 200} {
+		eod := twoDaysAgo.Add(time.Duration(i) * d)
+		for _, api := range indexesForSymbol("ETH/USD") {
+			apiOracle.SetRequestValue(api.Identifier, eod.Add(-time.Minute), apiOracle.PriceInfo{Price: price})
+		}
+	}
+
+	// The value at the end of the day is taken from the day before at.
+	value, confidence, err := PSRValueForTime(9, twoDaysAgo.Add(12*time.Hour))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 100e6, value)
+	testutil.Equals(t, 1.0, confidence)
+	value, _, err = PSRValueForTime(9, twoDaysAgo.Add(36*time.Hour))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 200e6, value)
+
+	_, _, err = PSRValueForTime(1000, twoDaysAgo)
+	testutil.NotOk(t, err)
+
+	values, err := ReplayPSRs([]int{1, 9}, twoDaysAgo, twoDaysAgo.Add(d), 12*time.Hour)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 6, len(values))
+	for i, expected := range []float64{100e6, 100e6, 100e6, 100e6, 200e6, 200e6} {
+		testutil.Equals(t, expected, values[i].Value, "value:%v", i)
+	}
+	testutil.Equals(t, 1, values[4].RequestID)
+	testutil.Equals(t, 9, values[5].RequestID)
+	testutil.Assert(t, values[1].Time.Equal(twoDaysAgo), "replay should start at from")
+}
@@ -5,6 +5,8 @@ package tracker
 
 import (
 	"context"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -12,6 +14,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rjeczalik/notify"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
@@ -21,6 +24,9 @@ import (
 
 const ComponentName = "tracker"
 
+// reloadSettleTime is how long to wait after a config file change before reloading it.
+const reloadSettleTime = time.Second
+
 // Runner will execute all configured trackers.
 type Runner struct {
 	db           db.DataServerProxy
@@ -30,7 +36,12 @@ type Runner struct {
 	readyChannel chan bool
 	logger       log.Logger
 	config       *config.Config
+	fetcher      *Fetcher
 	trackerErr   *prometheus.CounterVec
+
+	mtx      sync.Mutex
+	trackers []Tracker
+	ticker   *time.Ticker
 }
 
 // NewRunner will create a new runner instance.
@@ -41,6 +52,7 @@ func NewRunner(logger log.Logger, config *config.Config, db db.DataServerProxy,
 	}
 	return &Runner{
 		config:       config,
+		fetcher:      NewFetcher(logger, config),
 		db:           db,
 		client:       client,
 		contract:     contract,
@@ -63,7 +75,7 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 	for name, activated := range trackerNames {
 		if activated {
 			level.Info(r.logger).Log("msg", "starting tracker", "name", name)
-			t, err := createTracker(r.logger, name, r.config, r.db, r.client, r.contract, r.account)
+			t, err := createTracker(r.logger, name, r.config, r.db, r.client, r.contract, r.account, r.fetcher)
 			if err != nil {
 				return errors.Wrapf(err, "creating tracker. Name: %s", name)
 			}
@@ -81,6 +93,18 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 
 	level.Info(r.logger).Log("msg", "starting trackers", "sleepCycle", r.config.TrackerSleepCycle)
 	ticker := time.NewTicker(r.config.TrackerSleepCycle.Duration / time.Duration(len(trackers)))
+	r.mtx.Lock()
+	r.trackers = trackers
+	r.ticker = ticker
+	r.mtx.Unlock()
+
+	done := make(chan struct{})
+	if r.config.Trackers["indexers"] {
+		if err := r.watchConfigFolder(done); err != nil {
+			ticker.Stop()
+			return errors.Wrap(err, "watching the config folder")
+		}
+	}
 
 	// after first run, let others know that tracker output data is ready for use.
 	doneFirstExec := make(chan bool, len(trackers))
@@ -101,10 +125,14 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 				{
 					level.Info(r.logger).Log("msg", "exiting run loop")
 					ticker.Stop()
+					close(done)
 					return
 				}
 			case <-ticker.C:
 				{
+					r.mtx.Lock()
+					trackers := r.trackers
+					r.mtx.Unlock()
 					go func(count int) {
 						idx := count % len(trackers)
 						err := trackers[idx].Exec(ctx)
@@ -113,7 +141,7 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 							level.Warn(r.logger).Log("msg", "tracker exec", "tracker", trackers[idx].String(), "err", err)
 						}
 						// Only the first trackers round execution.
-						if count < len(trackers) {
+						if count < cap(doneFirstExec) {
 							doneFirstExec <- true
 						}
 					}(i)
@@ -130,3 +158,67 @@ func (r *Runner) Start(ctx context.Context, exitCh chan int) error {
 func (r *Runner) Ready() chan bool {
 	return r.readyChannel
 }
+
+// watchConfigFolder reloads the index trackers and the manual data
+// when their files in the config folder change, until done is closed.
+func (r *Runner) watchConfigFolder(done chan struct{}) error {
+	events := make(chan notify.EventInfo, 10)
+	if err := notify.Watch(r.config.ConfigFolder, events, notify.Create, notify.Write, notify.Rename, notify.Remove); err != nil {
+		return err
+	}
+	go func() {
+		defer notify.Stop(events)
+		// Editors often write a file in several steps
+		// so wait for the changes to settle before reloading.
+		settle := time.NewTimer(0)
+		<-settle.C
+		changed := make(map[string]bool)
+		for {
+			select {
+			case <-done:
+				settle.Stop()
+				return
+			case event := <-events:
+				changed[filepath.Base(event.Path())] = true
+				settle.Reset(reloadSettleTime)
+			case <-settle.C:
+				if changed[indexFile] {
+					if err := r.reloadIndexTrackers(); err != nil {
+						level.Error(r.logger).Log("msg", "rejected the changed index file, keeping the current trackers", "err", err)
+					}
+				}
+				if changed[manualDataFile] {
+					if err := LoadManualData(r.config); err != nil {
+						level.Error(r.logger).Log("msg", "rejected the changed manual data file, keeping the current values", "err", err)
+					} else {
+						level.Info(r.logger).Log("msg", "reloaded the manual data file")
+					}
+				}
+				changed = make(map[string]bool)
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadIndexTrackers replaces the index trackers of the runner with the ones from the current index file.
+func (r *Runner) reloadIndexTrackers() error {
+	indexers, err := loadIndexTrackers(r.logger, r.config, r.db, r.client, r.fetcher, true)
+	if err != nil {
+		return err
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	var trackers []Tracker
+	for _, t := range r.trackers {
+		if _, ok := t.(*IndexTracker); !ok {
+			trackers = append(trackers, t)
+		}
+	}
+	trackers = append(trackers, indexers...)
+	r.trackers = trackers
+	r.ticker.Reset(r.config.TrackerSleepCycle.Duration / time.Duration(len(trackers)))
+	level.Info(r.logger).Log("msg", "reloaded the index file", "indexTrackers", len(indexers))
+	return nil
+}
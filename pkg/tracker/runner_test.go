@@ -5,7 +5,10 @@ package tracker
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
 	"math/big"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -69,3 +72,33 @@ func TestRunner(t *testing.T) {
 	close(exitCh)
 	time.Sleep(1 * time.Second)
 }
+
+func TestRunnerWatchConfigFolder(t *testing.T) {
+	cfg := *config.OpenTestConfig(t)
+	cfg.ConfigFolder = t.TempDir()
+	path := filepath.Join(cfg.ConfigFolder, manualDataFile)
+	valid := time.Now().Add(time.Hour).Unix()
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"USPCE": {"VALUE": 1, "DATE": %d}}`, valid)), 0600))
+	testutil.Ok(t, LoadManualData(&cfg))
+	defer func() {
+		manualDataMtx.Lock()
+		manualData = nil
+		manualDataPath = ""
+		manualDataMtx.Unlock()
+	}()
+
+	runner := &Runner{config: &cfg, logger: logging.NewLogger()}
+	done := make(chan struct{})
+	defer close(done)
+	testutil.Ok(t, runner.watchConfigFolder(done))
+
+	testutil.Ok(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(`{"USPCE": {"VALUE": 2, "DATE": %d}}`, valid)), 0600))
+	val := big.NewInt(0)
+	for i := 0; i < 50 && val.Int64() != 2000; i++ {
+		time.Sleep(100 * time.Millisecond)
+		var err error
+		val, err = GetManualValue(41, time.Now())
+		testutil.Ok(t, err)
+	}
+	testutil.Equals(t, big.NewInt(2000), val)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// StakeState is the staker status of the account in the Tellor contract.
+type StakeState int64
+
+// The staker statuses of the contract.
+const (
+	StakeNotStaked         StakeState = 0
+	StakeStaked            StakeState = 1
+	StakeLockedForWithdraw StakeState = 2
+	StakeInDispute         StakeState = 3
+	// StakeUnknown is the state before the status is read from the DB.
+	StakeUnknown StakeState = -1
+)
+
+func (s StakeState) String() string {
+	switch s {
+	case StakeNotStaked:
+		return "not staked"
+	case StakeStaked:
+		return "staked"
+	case StakeLockedForWithdraw:
+		return "locked for withdraw"
+	case StakeInDispute:
+		return "in dispute"
+	case StakeUnknown:
+		return "unknown"
+	default:
+		return "status " + strconv.FormatInt(int64(s), 10)
+	}
+}
+
+// CanMine reports whether solutions can be submitted in this state.
+func (s StakeState) CanMine() bool {
+	return s == StakeStaked
+}
+
+// MarshalJSON encodes the state by its name.
+func (s StakeState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
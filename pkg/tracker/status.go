@@ -0,0 +1,134 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/apiOracle"
+)
+
+// SourceHealth is the state of the last requests of an index tracker.
+type SourceHealth struct {
+	Source            string    `json:"source"`
+	Symbols           []string  `json:"symbols"`
+	Healthy           bool      `json:"healthy"`
+	LastRun           time.Time `json:"lastRun"`
+	LastSuccess       time.Time `json:"lastSuccess"`
+	LastValue         float64   `json:"lastValue"`
+	LastError         string    `json:"lastError,omitempty"`
+	LastErrorTime     time.Time `json:"lastErrorTime"`
+	ConsecutiveErrors int       `json:"consecutiveErrors"`
+}
+
+func (i *IndexTracker) recordRun(now time.Time, vals []float64, err error) {
+	i.healthMtx.Lock()
+	defer i.healthMtx.Unlock()
+	i.health.LastRun = now
+	if err != nil {
+		i.health.LastError = err.Error()
+		i.health.LastErrorTime = now
+		i.health.ConsecutiveErrors++
+		return
+	}
+	i.health.LastSuccess = now
+	i.health.LastValue = vals[0]
+	i.health.ConsecutiveErrors = 0
+}
+
+// Health returns the state of the last requests of the tracker.
+// A tracker is healthy when its last request succeeded.
+func (i *IndexTracker) Health() SourceHealth {
+	i.healthMtx.Lock()
+	defer i.healthMtx.Unlock()
+	h := i.health
+	h.Source = i.Identifier
+	h.Symbols = i.Symbols
+	h.Healthy = !h.LastSuccess.IsZero() && h.ConsecutiveErrors == 0
+	return h
+}
+
+// SourcesHealth returns the health of every index tracker sorted by source.
+func SourcesHealth() []SourceHealth {
+	seen := make(map[*IndexTracker]bool)
+	health := []SourceHealth{}
+	for _, trackers := range GetIndexes() {
+		for _, t := range trackers {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			health = append(health, t.Health())
+		}
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Source < health[j].Source
+	})
+	return health
+}
+
+// SourceValue is the latest value of an API contributing to a PSR.
+type SourceValue struct {
+	Source string    `json:"source"`
+	Symbol string    `json:"symbol"`
+	Value  float64   `json:"value"`
+	Volume float64   `json:"volume,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// PSRStatus is the value of a PSR with the values it is computed from.
+type PSRStatus struct {
+	RequestID  int     `json:"requestId"`
+	Value      float64 `json:"value"`
+	Confidence float64 `json:"confidence"`
+	// LastUpdate is the time of the newest value of the contributing sources.
+	LastUpdate time.Time     `json:"lastUpdate"`
+	Sources    []SourceValue `json:"sources"`
+}
+
+// GetPSRStatus computes the value of a PSR at the given time
+// and lists the latest values of the APIs it depends on.
+func GetPSRStatus(requestID int, at time.Time) (*PSRStatus, error) {
+	psr, ok := PSRs[requestID]
+	if !ok {
+		return nil, errors.Errorf("no PSR for request id:%v", requestID)
+	}
+	value, confidence, err := PSRValueForTime(requestID, at)
+	if err != nil {
+		return nil, err
+	}
+	status := &PSRStatus{
+		RequestID:  requestID,
+		Value:      value,
+		Confidence: confidence,
+		Sources:    []SourceValue{},
+	}
+	for symbol := range psr.Require(at) {
+		for _, api := range indexesForSymbol(symbol) {
+			before, _ := apiOracle.GetNearestTwoRequestValue(api.Identifier, at)
+			if before == nil {
+				continue
+			}
+			status.Sources = append(status.Sources, SourceValue{
+				Source: api.Identifier,
+				Symbol: symbol,
+				Value:  before.Price,
+				Volume: before.Volume,
+				Time:   before.Created,
+			})
+			if before.Created.After(status.LastUpdate) {
+				status.LastUpdate = before.Created
+			}
+		}
+	}
+	sort.Slice(status.Sources, func(i, j int) bool {
+		if status.Sources[i].Symbol != status.Sources[j].Symbol {
+			return status.Sources[i].Symbol < status.Sources[j].Symbol
+		}
+		return status.Sources[i].Source < status.Sources[j].Source
+	})
+	return status, nil
+}
@@ -0,0 +1,39 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestSourceHealth(t *testing.T) {
+	i := &IndexTracker{Identifier: "api", Symbols: []string{"ETH/USD"}}
+	testutil.Assert(t, !i.Health().Healthy, "expected a tracker without runs to be unhealthy")
+
+	start := time.Unix(1600000000, 0)
+	i.recordRun(start, []float64{100}, nil)
+	h := i.Health()
+	testutil.Assert(t, h.Healthy, "expected a healthy tracker")
+	testutil.Equals(t, "api", h.Source)
+	testutil.Equals(t, 100.0, h.LastValue)
+	testutil.Equals(t, start, h.LastSuccess)
+
+	i.recordRun(start.Add(time.Minute), nil, errors.New("timeout"))
+	i.recordRun(start.Add(2*time.Minute), nil, errors.New("timeout"))
+	h = i.Health()
+	testutil.Assert(t, !h.Healthy, "expected an unhealthy tracker after errors")
+	testutil.Equals(t, 2, h.ConsecutiveErrors)
+	testutil.Equals(t, "timeout", h.LastError)
+	testutil.Equals(t, start, h.LastSuccess)
+	testutil.Equals(t, 100.0, h.LastValue)
+
+	i.recordRun(start.Add(3*time.Minute), []float64{101}, nil)
+	h = i.Health()
+	testutil.Assert(t, h.Healthy, "expected the tracker to recover")
+	testutil.Equals(t, 0, h.ConsecutiveErrors)
+}
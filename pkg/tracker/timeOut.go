@@ -5,14 +5,11 @@ package tracker
 
 import (
 	"context"
-	"fmt"
-
 	"encoding/hex"
-	"strings"
+	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
@@ -24,7 +21,7 @@ import (
 
 type TimeOutTracker struct {
 	config   *config.Config
-	db       db.DataServerProxy
+	store    *db.Store
 	contract *contracts.Tellor
 	account  *rpc.Account
 	logger   log.Logger
@@ -34,10 +31,10 @@ func (b *TimeOutTracker) String() string {
 	return "TimeOutTracker"
 }
 
-func NewTimeOutTracker(logger log.Logger, config *config.Config, db db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *TimeOutTracker {
+func NewTimeOutTracker(logger log.Logger, config *config.Config, proxy db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *TimeOutTracker {
 	return &TimeOutTracker{
 		config:   config,
-		db:       db,
+		store:    db.NewStore(proxy),
 		contract: contract,
 		account:  account,
 		logger:   log.With(logger, "component", ComponentName),
@@ -58,8 +55,7 @@ func (b *TimeOutTracker) Exec(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrapf(err, "getting dispute status")
 	}
-	enc := hexutil.EncodeBig(status)
-	err = b.db.Put(db.TimeOutKey, []byte(enc))
+	err = b.store.SetTimeOut(lastMined(status))
 	if err != nil {
 		return errors.Wrapf(err, "storing dispute info")
 	}
@@ -83,12 +79,18 @@ func (b *TimeOutTracker) Exec(ctx context.Context) error {
 		if status.Int64() > 0 {
 			level.Info(b.logger).Log("msg", "whitelisted miner", "addr", addr, "lastTimeMined", time.Unix(status.Int64(), 0))
 		}
-		from := common.HexToAddress(addr)
-		dbKey := fmt.Sprintf("%s-%s", strings.ToLower(from.Hex()), db.TimeOutKey)
-		err = b.db.Put(dbKey, []byte(hexutil.EncodeBig(status)))
+		err = b.store.SetMinerTimeOut(common.HexToAddress(addr), lastMined(status))
 		if err != nil {
 			return errors.Wrapf(err, "storing last time mined")
 		}
 	}
 	return nil
 }
+
+// lastMined returns the time of the last submission from the contract, zero when it never submitted.
+func lastMined(status *big.Int) time.Time {
+	if status == nil || status.Sign() == 0 {
+		return time.Time{}
+	}
+	return time.Unix(status.Int64(), 0)
+}
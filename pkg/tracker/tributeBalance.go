@@ -5,31 +5,35 @@ package tracker
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/alerting"
+	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
 	"github.com/tellor-io/telliot/pkg/rpc"
 )
 
 type TributeTracker struct {
-	db       db.DataServerProxy
+	store    *db.Store
 	contract *contracts.Tellor
 	account  *rpc.Account
 	logger   log.Logger
+	config   *config.Config
 }
 
 func (b *TributeTracker) String() string {
 	return "TributeTracker"
 }
 
-func NewTributeTracker(logger log.Logger, db db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *TributeTracker {
+func NewTributeTracker(logger log.Logger, config *config.Config, proxy db.DataServerProxy, contract *contracts.Tellor, account *rpc.Account) *TributeTracker {
 	return &TributeTracker{
-		db:       db,
+		config:   config,
+		store:    db.NewStore(proxy),
 		contract: contract,
 		account:  account,
 		logger:   log.With(logger, "component", ComponentName),
@@ -50,6 +54,14 @@ func (b *TributeTracker) Exec(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "retrieving balance")
 	}
-	enc := hexutil.EncodeBig(balance)
-	return b.db.Put(db.TributeBalanceKey, []byte(enc))
+	if min := b.config.Alerting.Rules.MinTRBBalance; min > 0 && weiToFloat(balance) < min {
+		alerting.Notify(&alerting.Alert{
+			Rule:     alerting.RuleLowTRBBalance,
+			Key:      b.account.Address.Hex(),
+			Severity: alerting.SeverityWarning,
+			Summary:  fmt.Sprintf("TRB balance %.4f is below %v", weiToFloat(balance), min),
+			Details:  map[string]string{"account": b.account.Address.Hex()},
+		})
+	}
+	return b.store.SetTributeBalance(balance)
 }
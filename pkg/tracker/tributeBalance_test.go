@@ -10,7 +10,6 @@ import (
 
 	"github.com/pkg/errors"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/tellor-io/telliot/pkg/config"
 	"github.com/tellor-io/telliot/pkg/contracts"
 	"github.com/tellor-io/telliot/pkg/db"
@@ -36,12 +35,10 @@ func TestTributeBalance(t *testing.T) {
 	testutil.Ok(t, err)
 	account, err := rpc.NewAccount(cfg)
 	testutil.Ok(t, err)
-	tracker := NewTributeTracker(logger, proxy, &contract, &account)
+	tracker := NewTributeTracker(logger, cfg, proxy, &contract, &account)
 	err = tracker.Exec(context.Background())
 	testutil.Ok(t, err)
-	v, err := proxy.Get(db.TributeBalanceKey)
-	testutil.Ok(t, err)
-	b, err := hexutil.DecodeBig(string(v))
+	b, _, err := db.NewStore(proxy).GetTributeBalance()
 	testutil.Ok(t, err)
 	t.Logf("Tribute Balance stored: %v\n", b)
 	if b.Cmp(startBal) != 0 {
@@ -8,6 +8,7 @@ import (
 	"math"
 	"math/big"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -20,8 +21,7 @@ import (
 
 // Uniswap implements DataSource interface.
 type Uniswap struct {
-	symbol0 string
-	symbol1 string
+	tokens  *uniswapTokens
 	address string
 	client  contracts.ETHClient
 }
@@ -32,10 +32,8 @@ func (u *Uniswap) String() string {
 
 // NewUniswap creates new Uniswap for provided pair and pair address.
 func NewUniswap(pair string, address string, client contracts.ETHClient) *Uniswap {
-	symbols := strings.Split(pair, "/")
 	return &Uniswap{
-		symbol0: symbols[0],
-		symbol1: symbols[1],
+		tokens:  newUniswapTokens(pair, client),
 		address: address,
 		client:  client,
 	}
@@ -69,12 +67,67 @@ func (u *Uniswap) getSpotPrice() (*big.Float, error) {
 		return nil, errors.New("there's no liquidity in the pair")
 	}
 
+	pair, err := u.tokens.get(pairContract)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculating spot price based on reserve values.
+	if pair.side == 0 {
+		return calculateSpotPrice(reserve.Reserve0, reserve.Reserve1, pair.decimals0, pair.decimals1), nil
+	}
+	return calculateSpotPrice(reserve.Reserve1, reserve.Reserve0, pair.decimals1, pair.decimals0), nil
+}
+
+// uniswapPool is implemented by both the V2 pair and the V3 pool bindings.
+type uniswapPool interface {
+	Token0(opts *bind.CallOpts) (common.Address, error)
+	Token1(opts *bind.CallOpts) (common.Address, error)
+}
+
+// uniswapPair holds the token metadata of a Uniswap pair or pool.
+type uniswapPair struct {
+	token0    common.Address
+	token1    common.Address
+	decimals0 uint8
+	decimals1 uint8
+	// side is 0 when the first symbol of the index is token0 and 1 when it is token1.
+	side int
+}
+
+// uniswapTokens looks up the token metadata of a pair on the first call and caches it
+// so that the ERC20 calls are not repeated on every tracker cycle.
+type uniswapTokens struct {
+	symbol0 string
+	symbol1 string
+	client  contracts.ETHClient
+
+	mtx  sync.Mutex
+	pair *uniswapPair
+}
+
+func newUniswapTokens(pair string, client contracts.ETHClient) *uniswapTokens {
+	symbols := strings.Split(pair, "/")
+	return &uniswapTokens{
+		symbol0: symbols[0],
+		symbol1: symbols[1],
+		client:  client,
+	}
+}
+
+func (u *uniswapTokens) get(pool uniswapPool) (*uniswapPair, error) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if u.pair != nil {
+		return u.pair, nil
+	}
+
 	// Getting tokens addresses.
-	token0, err := pairContract.Token0(&bind.CallOpts{})
+	token0, err := pool.Token0(&bind.CallOpts{})
 	if err != nil {
 		return nil, errors.Wrap(err, "getting token0")
 	}
-	token1, err := pairContract.Token1(&bind.CallOpts{})
+	token1, err := pool.Token1(&bind.CallOpts{})
 	if err != nil {
 		return nil, errors.Wrap(err, "getting token1")
 	}
@@ -95,14 +148,17 @@ func (u *Uniswap) getSpotPrice() (*big.Float, error) {
 		return nil, err
 	}
 
-	// Calculating spot price based on reserve values.
-	if side == 0 {
-		return calculateSpotPrice(reserve.Reserve0, reserve.Reserve1, decimals0, decimals1), nil
+	u.pair = &uniswapPair{
+		token0:    token0,
+		token1:    token1,
+		decimals0: decimals0,
+		decimals1: decimals1,
+		side:      side,
 	}
-	return calculateSpotPrice(reserve.Reserve1, reserve.Reserve0, decimals1, decimals0), nil
+	return u.pair, nil
 }
 
-func (u *Uniswap) getTokenDecimals(token common.Address) (uint8, error) {
+func (u *uniswapTokens) getTokenDecimals(token common.Address) (uint8, error) {
 	// Get token decimals.
 	// Call on erc20 contracts.
 	var erc20TokenCaller *uniswap.IERC20Caller
@@ -117,7 +173,7 @@ func (u *Uniswap) getTokenDecimals(token common.Address) (uint8, error) {
 	return decimals, nil
 }
 
-func (u *Uniswap) getSide(token0, token1 common.Address) (int, error) {
+func (u *uniswapTokens) getSide(token0, token1 common.Address) (int, error) {
 	// Get price side.
 	symbol0, err := u.getTokenSymbol(token0)
 	if err != nil {
@@ -142,7 +198,7 @@ func (u *Uniswap) getSide(token0, token1 common.Address) (int, error) {
 	return -1, errors.New("wrong pair of input symbols were provided")
 }
 
-func (u *Uniswap) getTokenSymbol(token common.Address) (string, error) {
+func (u *uniswapTokens) getTokenSymbol(token common.Address) (string, error) {
 	// Get token symbol.
 	// Call on erc20 contracts.
 	var erc20TokenCaller *uniswap.IERC20Caller
@@ -166,3 +222,10 @@ func calculateSpotPrice(reserve0, reserve1 *big.Int, decimals0, decimals1 uint8)
 	return new(big.Float).Quo(price0, new(big.Float).Quo(new(big.Float).SetFloat64(math.Pow10(int(decimals1))),
 		new(big.Float).SetFloat64(math.Pow10(int(decimals0)))))
 }
+
+// scaleDecimals converts a raw on-chain price of base in quote units
+// to a price that accounts for the decimals of both tokens.
+func scaleDecimals(price *big.Float, decimalsBase, decimalsQuote uint8) *big.Float {
+	return new(big.Float).Quo(price, new(big.Float).Quo(new(big.Float).SetFloat64(math.Pow10(int(decimalsQuote))),
+		new(big.Float).SetFloat64(math.Pow10(int(decimalsBase)))))
+}
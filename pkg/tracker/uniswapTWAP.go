@@ -0,0 +1,163 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	uniswap "github.com/tellor-io/telliot/pkg/contracts/uniswap"
+)
+
+// DefaultTWAPPeriod is used when the index doesn't set a period in its param.
+const DefaultTWAPPeriod = 10 * time.Minute
+
+// cumulativeSnapshot is a reading of a pair price accumulator at a given block time.
+type cumulativeSnapshot struct {
+	cumulative *big.Int
+	timestamp  uint64
+}
+
+// UniswapTWAP implements DataSource interface.
+// It calculates a time weighted average price from the
+// price0CumulativeLast and price1CumulativeLast accumulators of a Uniswap V2 pair.
+// Such price can't be moved by trades within a single block.
+type UniswapTWAP struct {
+	tokens  *uniswapTokens
+	address string
+	client  contracts.ETHClient
+	period  time.Duration
+
+	mtx       sync.Mutex
+	snapshots []cumulativeSnapshot
+}
+
+func (u *UniswapTWAP) String() string {
+	return "UniswapTWAP"
+}
+
+// NewUniswapTWAP creates new UniswapTWAP for provided pair, pair address and averaging period.
+func NewUniswapTWAP(pair string, address string, period time.Duration, client contracts.ETHClient) *UniswapTWAP {
+	if period <= 0 {
+		period = DefaultTWAPPeriod
+	}
+	return &UniswapTWAP{
+		tokens:  newUniswapTokens(pair, client),
+		address: address,
+		client:  client,
+		period:  period,
+	}
+}
+
+// Get calculates the time weighted average price for the provided pair.
+// The average is taken between the current accumulator value and the newest
+// snapshot that is at least one period old.
+// Until such snapshot exists the oldest recorded one is used so
+// the averaging window grows with every call up to the configured period.
+func (u *UniswapTWAP) Get() ([]byte, error) {
+	pairContract, err := uniswap.NewIUniswapV2PairCaller(common.HexToAddress(u.address), u.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting pair contract")
+	}
+	pair, err := u.tokens.get(pairContract)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := u.currentCumulative(pairContract, pair.side)
+	if err != nil {
+		return nil, err
+	}
+	average, err := u.average(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var price *big.Float
+	if pair.side == 0 {
+		price = scaleDecimals(average, pair.decimals0, pair.decimals1)
+	} else {
+		price = scaleDecimals(average, pair.decimals1, pair.decimals0)
+	}
+	priceF64, _ := price.Float64()
+	return json.Marshal([]float64{priceF64})
+}
+
+// currentCumulative returns the price accumulator for the current block.
+// The pair updates its accumulators only on the first trade in a block so
+// the time elapsed since the last update is accumulated here the same way
+// as UniswapV2OracleLibrary.currentCumulativePrices does.
+func (u *UniswapTWAP) currentCumulative(pairContract *uniswap.IUniswapV2PairCaller, side int) (cumulativeSnapshot, error) {
+	reserve, err := pairContract.GetReserves(&bind.CallOpts{})
+	if err != nil {
+		return cumulativeSnapshot{}, errors.Wrap(err, "getting reserves")
+	}
+	if reserve.Reserve0.Uint64() == 0 || reserve.Reserve1.Uint64() == 0 {
+		return cumulativeSnapshot{}, errors.New("there's no liquidity in the pair")
+	}
+
+	var cumulative *big.Int
+	reserveBase, reserveQuote := reserve.Reserve0, reserve.Reserve1
+	if side == 0 {
+		cumulative, err = pairContract.Price0CumulativeLast(&bind.CallOpts{})
+	} else {
+		cumulative, err = pairContract.Price1CumulativeLast(&bind.CallOpts{})
+		reserveBase, reserveQuote = reserve.Reserve1, reserve.Reserve0
+	}
+	if err != nil {
+		return cumulativeSnapshot{}, errors.Wrap(err, "getting cumulative price")
+	}
+
+	header, err := u.client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return cumulativeSnapshot{}, errors.Wrap(err, "getting latest block header")
+	}
+
+	// The pair stores the timestamp as uint32 so the subtraction is expected to overflow.
+	elapsed := uint32(header.Time) - reserve.BlockTimestampLast
+	if elapsed > 0 {
+		price := new(big.Int).Lsh(reserveQuote, 112)
+		price.Div(price, reserveBase)
+		price.Mul(price, big.NewInt(int64(elapsed)))
+		cumulative = new(big.Int).Add(cumulative, price)
+	}
+	return cumulativeSnapshot{cumulative: cumulative, timestamp: header.Time}, nil
+}
+
+// average records the current snapshot and returns
+// the average raw price since the oldest snapshot still needed.
+func (u *UniswapTWAP) average(current cumulativeSnapshot) (*big.Float, error) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	period := uint64(u.period.Seconds())
+	if len(u.snapshots) == 0 || u.snapshots[len(u.snapshots)-1].timestamp < current.timestamp {
+		u.snapshots = append(u.snapshots, current)
+	}
+	// Drop all snapshots older than the newest one that covers a full period.
+	for len(u.snapshots) > 1 && current.timestamp-u.snapshots[1].timestamp >= period {
+		u.snapshots = u.snapshots[1:]
+	}
+
+	oldest := u.snapshots[0]
+	elapsed := current.timestamp - oldest.timestamp
+	if elapsed == 0 {
+		return nil, errors.New("not enough price snapshots yet to calculate the average")
+	}
+
+	// The accumulators are expected to overflow so the difference is taken modulo 2^256.
+	diff := new(big.Int).Sub(current.cumulative, oldest.cumulative)
+	diff.Mod(diff, new(big.Int).Lsh(big.NewInt(1), 256))
+
+	average := new(big.Float).Quo(new(big.Float).SetInt(diff), new(big.Float).SetUint64(elapsed))
+	// Convert from the UQ112x112 fixed point format of the accumulators.
+	return average.Quo(average, new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 112))), nil
+}
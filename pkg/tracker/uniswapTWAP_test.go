@@ -0,0 +1,72 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	eth_common "github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestUniswapTWAPPrice(t *testing.T) {
+	bPairContract := eth_common.HexToAddress("0xc5be99a02c6857f9eac67bbce58df5572498f40c")
+	token1Address := eth_common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")
+	token2Address := eth_common.HexToAddress("0xd46ba6d942050d489dbd938a2c909a5d5039a161")
+	reserve0, _ := big.NewInt(0).SetString("11073781494155978314322", 10)
+	reserve1, _ := big.NewInt(0).SetString("14899909395042275", 10)
+	opts := &rpc.MockOptions{
+		UniPairContractAddress: bPairContract,
+		UniToken0:              token1Address,
+		UniToken1:              token2Address,
+		UniReserves: &rpc.CurrentReserves{
+			Reserve0:           reserve0,
+			Reserve1:           reserve1,
+			BlockTimestampLast: 200,
+		},
+		UniPrice0CumulativeLast: big.NewInt(0),
+		UniPrice1CumulativeLast: big.NewInt(0),
+		TokenSymbols: map[string]string{
+			token1Address.Hex(): "ETH",
+			token2Address.Hex(): "AMPL",
+		},
+		Decimals: map[string]int{
+			bPairContract.Hex(): 18,
+			token1Address.Hex(): 18,
+			token2Address.Hex(): 9,
+		},
+	}
+	client := rpc.NewMockClientWithValues(opts)
+
+	tracker := NewUniswapTWAP("ETH/AMPL", bPairContract.Hex(), 10*time.Minute, client)
+
+	// A single snapshot is not enough to calculate an average.
+	_, err := tracker.Get()
+	testutil.NotOk(t, err)
+
+	// Seed a snapshot taken 10 minutes ago with the same reserves so
+	// that the average must match the spot price.
+	spot := new(big.Int).Lsh(reserve1, 112)
+	spot.Div(spot, reserve0)
+	then := uint64(time.Now().Add(-10 * time.Minute).Unix())
+	tracker.snapshots = []cumulativeSnapshot{{
+		cumulative: new(big.Int).Mul(spot, big.NewInt(int64(then-200))),
+		timestamp:  then,
+	}}
+
+	priceJSON, err := tracker.Get()
+	testutil.Ok(t, err)
+
+	var priceInfo []float64
+	err = json.Unmarshal(priceJSON, &priceInfo)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(priceInfo))
+	testutil.Assert(t, math.Abs(priceInfo[0]-1345.5123168996497) < 1e-6, "unexpected TWAP price:%v", priceInfo[0])
+	t.Logf("AMPL/ETH TWAP on Uniswap: %.5f\n", priceInfo[0])
+}
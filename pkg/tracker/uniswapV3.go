@@ -0,0 +1,105 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/tellor-io/telliot/pkg/contracts"
+	uniswap "github.com/tellor-io/telliot/pkg/contracts/uniswap"
+)
+
+// UniswapV3 implements DataSource interface.
+// With a zero period it returns the pool spot price from slot0,
+// otherwise the time weighted average price over the period using observe().
+type UniswapV3 struct {
+	tokens  *uniswapTokens
+	address string
+	client  contracts.ETHClient
+	period  time.Duration
+}
+
+func (u *UniswapV3) String() string {
+	return "UniswapV3"
+}
+
+// NewUniswapV3 creates new UniswapV3 for provided pair, pool address and averaging period.
+func NewUniswapV3(pair string, address string, period time.Duration, client contracts.ETHClient) *UniswapV3 {
+	return &UniswapV3{
+		tokens:  newUniswapTokens(pair, client),
+		address: address,
+		client:  client,
+		period:  period,
+	}
+}
+
+// Get calculates price for the provided pair.
+func (u *UniswapV3) Get() ([]byte, error) {
+	poolContract, err := uniswap.NewIUniswapV3PoolCaller(common.HexToAddress(u.address), u.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting pool contract")
+	}
+	pair, err := u.tokens.get(poolContract)
+	if err != nil {
+		return nil, err
+	}
+
+	var price *big.Float
+	if u.period > 0 {
+		price, err = u.getTWAP(poolContract)
+	} else {
+		price, err = u.getSpotPrice(poolContract)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The pool price is always token1 per token0.
+	if pair.side == 0 {
+		price = scaleDecimals(price, pair.decimals0, pair.decimals1)
+	} else {
+		price = scaleDecimals(new(big.Float).Quo(big.NewFloat(1), price), pair.decimals1, pair.decimals0)
+	}
+	priceF64, _ := price.Float64()
+	return json.Marshal([]float64{priceF64})
+}
+
+func (u *UniswapV3) getSpotPrice(poolContract *uniswap.IUniswapV3PoolCaller) (*big.Float, error) {
+	slot0, err := poolContract.Slot0(&bind.CallOpts{})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting slot0")
+	}
+	if slot0.SqrtPriceX96.Sign() == 0 {
+		return nil, errors.New("the pool isn't initialized")
+	}
+	// price = (sqrtPriceX96 / 2^96)^2
+	sqrtPrice := new(big.Float).Quo(new(big.Float).SetInt(slot0.SqrtPriceX96), new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96)))
+	return new(big.Float).Mul(sqrtPrice, sqrtPrice), nil
+}
+
+func (u *UniswapV3) getTWAP(poolContract *uniswap.IUniswapV3PoolCaller) (*big.Float, error) {
+	period := uint32(u.period.Seconds())
+	observations, err := poolContract.Observe(&bind.CallOpts{}, []uint32{period, 0})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting pool observations")
+	}
+	if len(observations.TickCumulatives) != 2 {
+		return nil, errors.Errorf("expected 2 tick cumulatives, got:%v", len(observations.TickCumulatives))
+	}
+
+	delta := new(big.Int).Sub(observations.TickCumulatives[1], observations.TickCumulatives[0])
+	// Round towards negative infinity the same way as the OracleLibrary.consult does.
+	tick, rem := new(big.Int).QuoRem(delta, big.NewInt(int64(period)), new(big.Int))
+	if delta.Sign() < 0 && rem.Sign() != 0 {
+		tick.Sub(tick, big.NewInt(1))
+	}
+	// price = 1.0001^tick
+	return big.NewFloat(math.Pow(1.0001, float64(tick.Int64()))), nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) The Tellor Authors.
+// Licensed under the MIT License.
+
+package tracker
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	eth_common "github.com/ethereum/go-ethereum/common"
+	"github.com/tellor-io/telliot/pkg/rpc"
+	"github.com/tellor-io/telliot/pkg/testutil"
+)
+
+func TestUniswapV3Price(t *testing.T) {
+	poolContract := eth_common.HexToAddress("0x8ad599c3a0ff1de082011efddc58f1908eb6e6d8")
+	token0Address := eth_common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	token1Address := eth_common.HexToAddress("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")
+	// sqrt(1e18 / 2000e6) * 2^96, ETH at 2000 USDC.
+	sqrtPriceX96, _ := big.NewInt(0).SetString("1771595571142957166518320255467520", 10)
+	opts := &rpc.MockOptions{
+		UniToken0:         token0Address,
+		UniToken1:         token1Address,
+		UniV3SqrtPriceX96: sqrtPriceX96,
+		// Average tick of 200311 over 10 minutes.
+		UniV3TickCumulatives: []*big.Int{big.NewInt(1000), big.NewInt(1000 + 200311*600)},
+		TokenSymbols: map[string]string{
+			token0Address.Hex(): "USDC",
+			token1Address.Hex(): "WETH",
+		},
+		Decimals: map[string]int{
+			token0Address.Hex(): 6,
+			token1Address.Hex(): 18,
+		},
+	}
+	client := rpc.NewMockClientWithValues(opts)
+
+	for _, tc := range []struct {
+		name     string
+		period   time.Duration
+		expected float64
+	}{
+		{"spot", 0, 2000},
+		{"twap", 10 * time.Minute, 2000.0402896525002},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := NewUniswapV3("ETH/USDC", poolContract.Hex(), tc.period, client)
+			priceJSON, err := tracker.Get()
+			testutil.Ok(t, err)
+
+			var priceInfo []float64
+			err = json.Unmarshal(priceJSON, &priceInfo)
+			testutil.Ok(t, err)
+			testutil.Equals(t, 1, len(priceInfo))
+			testutil.Assert(t, math.Abs(priceInfo[0]-tc.expected) < 1e-6, "unexpected price:%v", priceInfo[0])
+		})
+	}
+}
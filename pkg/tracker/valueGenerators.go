@@ -5,12 +5,10 @@ package tracker
 
 import (
 	"context"
-	"fmt"
 	"math"
 	"math/big"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/pkg/errors"
 	"github.com/tellor-io/telliot/pkg/apiOracle"
 	"github.com/tellor-io/telliot/pkg/config"
@@ -33,7 +31,11 @@ type ValueGenerator interface {
 }
 
 func InitPSRs() error {
-	//check that we have all the symbols asked for
+	return validatePSRs(GetIndexes())
+}
+
+// validatePSRs checks that the indexes have all the symbols required by the PSRs.
+func validatePSRs(indexes map[string][]*IndexTracker) error {
 	now := clck.Now()
 	for requestID, handler := range PSRs {
 		reqs := handler.Require(now)
@@ -47,14 +49,20 @@ func InitPSRs() error {
 	return nil
 }
 
+// PSRValueForTime returns the value of a PSR and its confidence at the given time
+// calculated from the recorded values of its indexes.
 func PSRValueForTime(requestID int, at time.Time) (float64, float64, error) {
+	psr, ok := PSRs[requestID]
+	if !ok {
+		return 0, 0, errors.Errorf("no PSR for request id:%v", requestID)
+	}
 	// Get the requirements.
-	reqs := PSRs[requestID].Require(at)
+	reqs := psr.Require(at)
 	values := make(map[string]apiOracle.PriceInfo)
 	minConfidence := math.MaxFloat64
 
 	for symbol, fn := range reqs {
-		val, confidence, err := fn(indexes[symbol], at)
+		val, confidence, err := fn(indexesForSymbol(symbol), at)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -67,7 +75,7 @@ func PSRValueForTime(requestID int, at time.Time) (float64, float64, error) {
 		values[symbol] = val
 	}
 
-	return PSRs[requestID].ValueAt(values, at), minConfidence, nil
+	return psr.ValueAt(values, at), minConfidence, nil
 }
 
 func UpdatePSRs(ctx context.Context, DB db.DataServerProxy, updatedSymbols []string) error {
@@ -103,9 +111,7 @@ func UpdatePSRs(ctx context.Context, DB db.DataServerProxy, updatedSymbols []str
 		bigVal.SetFloat64(amt)
 		bigInt := new(big.Int)
 		bigVal.Int(bigInt)
-		// Encode it and store to DB.
-		enc := hexutil.EncodeBig(bigInt)
-		err = DB.Put(fmt.Sprintf("%s%d", db.QueriedValuePrefix, requestID), []byte(enc))
+		err = db.NewStore(DB).PutPSRValue(uint64(requestID), bigInt, conf, now)
 		if err != nil {
 			return err
 		}